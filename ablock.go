@@ -5,11 +5,17 @@
 package factom
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 )
 
 var (
@@ -83,9 +89,13 @@ type ABlock struct {
 	DBHeight              int64     `json:"dbheight"`
 	BackReferenceHash     string    `json:"backreferencehash"`
 	LookupHash            string    `json:"lookuphash"`
+	HeaderExpansionArea   []byte    `json:"headerexpansionarea,omitempty"`
 	ABEntries             []ABEntry `json:"abentries"`
 }
 
+// AdminChainID is the Chain ID of the Admin Blocks.
+const AdminChainID = "000000000000000000000000000000000000000000000000000000000000000a"
+
 func (a *ABlock) String() string {
 	var s string
 
@@ -108,6 +118,7 @@ func (a *ABlock) UnmarshalJSON(js []byte) error {
 		Header struct {
 			PrevBackreferenceHash string `json:"prevbackrefhash"`
 			DBHeight              int64  `json:"dbheight"`
+			HeaderExpansionArea   string `json:"headerexpansionarea"`
 		}
 		BackReferenceHash string            `json:"backreferencehash"`
 		LookupHash        string            `json:"lookuphash"`
@@ -123,6 +134,12 @@ func (a *ABlock) UnmarshalJSON(js []byte) error {
 	a.DBHeight = tmp.Header.DBHeight
 	a.BackReferenceHash = tmp.BackReferenceHash
 	a.LookupHash = tmp.LookupHash
+	if tmp.Header.HeaderExpansionArea != "" {
+		a.HeaderExpansionArea, err = hex.DecodeString(tmp.Header.HeaderExpansionArea)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Use a regular expression to match the "adminidtype" field from the json
 	// and unmarshal the ABEntry into its correct type
@@ -241,6 +258,264 @@ func (a *ABlock) UnmarshalJSON(js []byte) error {
 	return nil
 }
 
+// MarshalBinary returns the binary Admin Block as stored by factomd.
+func (a *ABlock) MarshalBinary() ([]byte, error) {
+	body := new(bytes.Buffer)
+	for _, v := range a.ABEntries {
+		if err := marshalABEntry(body, v); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	writeHash(buf, AdminChainID)
+	if err := writeHash(buf, a.PrevBackreferenceHash); err != nil {
+		return nil, err
+	}
+	binary.Write(buf, binary.BigEndian, uint32(a.DBHeight))
+	writeVarInt(buf, uint64(len(a.HeaderExpansionArea)))
+	buf.Write(a.HeaderExpansionArea)
+	binary.Write(buf, binary.BigEndian, uint32(len(a.ABEntries)))
+	binary.Write(buf, binary.BigEndian, uint32(body.Len()))
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a binary Admin Block, such as the raw data returned
+// by GetABlock, and computes its LookupHash and BackReferenceHash.
+func (a *ABlock) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+
+	if id := r.hash(); r.err == nil && id != AdminChainID {
+		return fmt.Errorf("invalid admin block chainid %s", id)
+	}
+	a.PrevBackreferenceHash = r.hash()
+	a.DBHeight = int64(r.uint32())
+	a.HeaderExpansionArea = nil
+	if n := r.varInt(); n > 0 {
+		a.HeaderExpansionArea = append([]byte{}, r.next(int(n))...)
+	}
+	count := int(r.uint32())
+	size := int(r.uint32())
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) != size {
+		return fmt.Errorf("admin block body is %d bytes not %d", len(r.data), size)
+	}
+
+	a.ABEntries = a.ABEntries[:0]
+	for i := 0; i < count; i++ {
+		e, err := unmarshalABEntry(r)
+		if err != nil {
+			return err
+		}
+		a.ABEntries = append(a.ABEntries, e)
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	lookup := sha256.Sum256(data)
+	backref := sha512.Sum512(data)
+	a.LookupHash = hex.EncodeToString(lookup[:])
+	a.BackReferenceHash = hex.EncodeToString(backref[:32])
+
+	return nil
+}
+
+// marshalABEntry writes the AdminID and binary data of an ABEntry. The
+// entries added after the Milestone 2 release carry the size of their data.
+func marshalABEntry(buf *bytes.Buffer, e ABEntry) error {
+	data := new(bytes.Buffer)
+	var err error
+
+	switch e := e.(type) {
+	case *AdminMinuteNumber:
+		data.WriteByte(byte(e.MinuteNumber))
+	case *AdminDBSignature:
+		err = writeHashes(data, e.IdentityChainID, e.PreviousSignature.Pub)
+		if err == nil {
+			err = writeHex(data, e.PreviousSignature.Sig, 64)
+		}
+	case *AdminRevealHash:
+		err = writeHashes(data, e.IdentityChainID, e.MatryoshkaHash)
+	case *AdminAddHash:
+		err = writeHashes(data, e.IdentityChainID, e.MatryoshkaHash)
+	case *AdminIncreaseServerCount:
+		data.WriteByte(byte(e.Amount))
+	case *AdminAddFederatedServer:
+		err = writeHash(data, e.IdentityChainID)
+		binary.Write(data, binary.BigEndian, uint32(e.DBHeight))
+	case *AdminAddAuditServer:
+		err = writeHash(data, e.IdentityChainID)
+		binary.Write(data, binary.BigEndian, uint32(e.DBHeight))
+	case *AdminRemoveFederatedServer:
+		err = writeHash(data, e.IdentityChainID)
+		binary.Write(data, binary.BigEndian, uint32(e.DBHeight))
+	case *AdminAddFederatedServerKey:
+		err = writeHash(data, e.IdentityChainID)
+		data.WriteByte(byte(e.KeyPriority))
+		if err == nil {
+			err = writeHash(data, e.PublicKey)
+		}
+		binary.Write(data, binary.BigEndian, uint32(e.DBHeight))
+	case *AdminAddFederatedServerBTCKey:
+		err = writeHash(data, e.IdentityChainID)
+		data.WriteByte(byte(e.KeyPriority))
+		data.WriteByte(byte(e.KeyType))
+		if err == nil {
+			err = writeHex(data, e.ECDSAPublicKey, 20)
+		}
+	case *AdminCoinbaseDescriptor:
+		for _, v := range e.Outputs {
+			writeVarInt(data, uint64(v.Amount))
+			if err = writeHash(data, v.Address); err != nil {
+				break
+			}
+		}
+	case *AdminCoinbaseDescriptorCancel:
+		binary.Write(data, binary.BigEndian, uint32(e.DescriptorHeight))
+		binary.Write(data, binary.BigEndian, uint32(e.DescriptorIndex))
+	case *AdminAddAuthorityAddress:
+		err = writeHash(data, e.IdentityChainID)
+		if err == nil {
+			var rcdHash []byte
+			rcdHash, err = decodePubAddress(fcPubPrefix, e.FactoidAddress)
+			data.Write(rcdHash)
+		}
+	case *AdminAddAuthorityEfficiency:
+		err = writeHash(data, e.IdentityChainID)
+		binary.Write(data, binary.BigEndian, uint16(e.Efficiency))
+	case *AdminServerFault:
+		err = marshalServerFault(data, e)
+	default:
+		return fmt.Errorf("cannot encode admin block entry type %s", e.Type())
+	}
+	if err != nil {
+		return err
+	}
+
+	buf.WriteByte(byte(e.Type()))
+	if e.Type() >= AIDCoinbaseDescriptor {
+		writeVarInt(buf, uint64(data.Len()))
+	}
+	buf.Write(data.Bytes())
+
+	return nil
+}
+
+// unmarshalABEntry reads an ABEntry written by marshalABEntry.
+func unmarshalABEntry(r *binaryReader) (ABEntry, error) {
+	id := AdminID(r.byte())
+	if id >= AIDCoinbaseDescriptor {
+		// read the entry from its own data so its size is checked
+		size := r.varInt()
+		if size > uint64(len(r.data)) {
+			return nil, ErrBinaryTooShort
+		}
+		data := &binaryReader{data: r.next(int(size))}
+		e, err := unmarshalABEntryData(id, data)
+		if err != nil {
+			return nil, err
+		}
+		return e, data.done()
+	}
+	return unmarshalABEntryData(id, r)
+}
+
+func unmarshalABEntryData(id AdminID, r *binaryReader) (ABEntry, error) {
+	var e ABEntry
+
+	switch id {
+	case AIDMinuteNumber:
+		e = &AdminMinuteNumber{MinuteNumber: int(r.byte())}
+	case AIDDBSignature:
+		s := new(AdminDBSignature)
+		s.IdentityChainID = r.hash()
+		s.PreviousSignature.Pub = r.hash()
+		s.PreviousSignature.Sig = hex.EncodeToString(r.next(64))
+		e = s
+	case AIDRevealHash:
+		e = &AdminRevealHash{IdentityChainID: r.hash(), MatryoshkaHash: r.hash()}
+	case AIDAddHash:
+		e = &AdminAddHash{IdentityChainID: r.hash(), MatryoshkaHash: r.hash()}
+	case AIDIncreaseServerCount:
+		e = &AdminIncreaseServerCount{Amount: int(r.byte())}
+	case AIDAddFederatedServer:
+		e = &AdminAddFederatedServer{IdentityChainID: r.hash(), DBHeight: int64(r.uint32())}
+	case AIDAddAuditServer:
+		e = &AdminAddAuditServer{IdentityChainID: r.hash(), DBHeight: int64(r.uint32())}
+	case AIDRemoveFederatedServer:
+		e = &AdminRemoveFederatedServer{IdentityChainID: r.hash(), DBHeight: int64(r.uint32())}
+	case AIDAddFederatedServerKey:
+		k := new(AdminAddFederatedServerKey)
+		k.IdentityChainID = r.hash()
+		k.KeyPriority = int(r.byte())
+		k.PublicKey = r.hash()
+		k.DBHeight = int(r.uint32())
+		e = k
+	case AIDAddFederatedServerBTCKey:
+		k := new(AdminAddFederatedServerBTCKey)
+		k.IdentityChainID = r.hash()
+		k.KeyPriority = int(r.byte())
+		k.KeyType = int(r.byte())
+		k.ECDSAPublicKey = hex.EncodeToString(r.next(20))
+		e = k
+	case AIDCoinbaseDescriptor:
+		d := new(AdminCoinbaseDescriptor)
+		for len(r.data) > 0 && r.err == nil {
+			d.Outputs = append(d.Outputs, struct {
+				Amount  int    `json:"amount"`
+				Address string `json:"address"`
+			}{int(r.varInt()), r.hash()})
+		}
+		e = d
+	case AIDCoinbaseDescriptorCancel:
+		e = &AdminCoinbaseDescriptorCancel{
+			DescriptorHeight: int(r.uint32()),
+			DescriptorIndex:  int(r.uint32()),
+		}
+	case AIDAddAuthorityAddress:
+		e = &AdminAddAuthorityAddress{
+			IdentityChainID: r.hash(),
+			FactoidAddress:  encodePubAddress(fcPubPrefix, r.next(32)),
+		}
+	case AIDAddAuthorityEfficiency:
+		e = &AdminAddAuthorityEfficiency{IdentityChainID: r.hash(), Efficiency: int(r.uint16())}
+	case AIDServerFault:
+		f := new(AdminServerFault)
+		f.Timestamp = strconv.FormatInt(r.milliTime(), 10)
+		f.ServerID = r.hash()
+		f.AuditServerID = r.hash()
+		f.VMIndex = int(r.byte())
+		f.DBHeight = int(r.uint32())
+		f.Height = int(r.uint32())
+		sigs := new(serverFaultSignatures)
+		sigs.Length = int(r.uint32())
+		for i := 0; i < sigs.Length && r.err == nil; i++ {
+			sigs.List = append(sigs.List, serverFaultSignature{
+				Pub: r.hash(),
+				Sig: hex.EncodeToString(r.next(64)),
+			})
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		p, err := json.Marshal(sigs)
+		if err != nil {
+			return nil, err
+		}
+		f.SignatureList = p
+		e = f
+	default:
+		return nil, ErrAIDUnknown
+	}
+
+	return e, r.err
+}
+
 // ABEntry is any valid Admin Block Entry type
 type ABEntry interface {
 	Type() AdminID
@@ -485,6 +760,51 @@ type AdminServerFault struct {
 	SignatureList json.RawMessage `json:"signaturelist"`
 }
 
+// serverFaultSignatures is the SignatureList of an AdminServerFault.
+type serverFaultSignatures struct {
+	Length int                    `json:"Length"`
+	List   []serverFaultSignature `json:"List"`
+}
+
+type serverFaultSignature struct {
+	Pub string `json:"pub"`
+	Sig string `json:"sig"`
+}
+
+// marshalServerFault writes the binary data of an AdminServerFault: the
+// timestamp, the server and audit server ids, the VM index, the heights and
+// the signatures.
+func marshalServerFault(buf *bytes.Buffer, a *AdminServerFault) error {
+	ms, err := strconv.ParseInt(a.Timestamp, 10, 64)
+	if err != nil {
+		return err
+	}
+	sigs := new(serverFaultSignatures)
+	if len(a.SignatureList) > 0 {
+		if err := json.Unmarshal(a.SignatureList, sigs); err != nil {
+			return err
+		}
+	}
+
+	buf.Write(milliTimeBytes(ms))
+	if err := writeHashes(buf, a.ServerID, a.AuditServerID); err != nil {
+		return err
+	}
+	buf.WriteByte(byte(a.VMIndex))
+	binary.Write(buf, binary.BigEndian, uint32(a.DBHeight))
+	binary.Write(buf, binary.BigEndian, uint32(a.Height))
+	binary.Write(buf, binary.BigEndian, uint32(len(sigs.List)))
+	for _, v := range sigs.List {
+		if err := writeHash(buf, v.Pub); err != nil {
+			return err
+		}
+		if err := writeHex(buf, v.Sig, 64); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (a *AdminServerFault) Type() AdminID {
 	return AIDServerFault
 }
@@ -604,9 +924,14 @@ func (a *AdminAddAuthorityEfficiency) String() string {
 
 // GetABlock requests a specific ABlock from the factomd API.
 func GetABlock(keymr string) (ablock *ABlock, raw []byte, err error) {
+	return DefaultClient.GetABlock(context.Background(), keymr)
+}
+
+// GetABlock requests a specific ABlock from the factomd API.
+func (c *Client) GetABlock(ctx context.Context, keymr string) (ablock *ABlock, raw []byte, err error) {
 	params := keyMRRequest{KeyMR: keymr}
 	req := NewJSON2Request("admin-block", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
@@ -636,9 +961,15 @@ func GetABlock(keymr string) (ablock *ABlock, raw []byte, err error) {
 // GetABlockByHeight requests an ABlock of a specific height from the factomd
 // API.
 func GetABlockByHeight(height int64) (ablock *ABlock, raw []byte, err error) {
+	return DefaultClient.GetABlockByHeight(context.Background(), height)
+}
+
+// GetABlockByHeight requests an ABlock of a specific height from the factomd
+// API.
+func (c *Client) GetABlockByHeight(ctx context.Context, height int64) (ablock *ABlock, raw []byte, err error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request("ablock-by-height", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
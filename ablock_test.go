@@ -5,10 +5,13 @@
 package factom_test
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	. "github.com/FactomProject/factom"
 
@@ -77,6 +80,22 @@ func TestGetABlock(t *testing.T) {
 	}
 	t.Log("ABlock:", ab)
 	t.Log(fmt.Sprintf("Raw: %x\n", raw))
+
+	ab2 := new(ABlock)
+	if err := ab2.UnmarshalBinary(raw); err != nil {
+		t.Fatal(err)
+	}
+	if ab2.String() != ab.String() {
+		t.Errorf("expected:%s\nrecieved:%s", ab, ab2)
+	}
+	for _, a := range []*ABlock{ab, ab2} {
+		if p, err := a.MarshalBinary(); err != nil || !bytes.Equal(p, raw) {
+			t.Errorf("expected:%x\nrecieved:%x %v", raw, p, err)
+		}
+	}
+	if err := ab2.UnmarshalBinary(raw[:len(raw)-1]); err == nil {
+		t.Error("expected an error for truncated admin block")
+	}
 }
 
 func TestGetABlockByHeight(t *testing.T) {
@@ -127,3 +146,48 @@ func TestGetABlockByHeight(t *testing.T) {
 	t.Log("ABlock:", ab)
 	t.Log(fmt.Sprintf("Raw: %x\n", raw))
 }
+
+func TestABlockServerFault(t *testing.T) {
+	fault := "0a" + // ServerFault
+		"016b7a6b0c58" + // timestamp
+		strings.Repeat("88", 32) + // server id
+		strings.Repeat("99", 32) + // audit server id
+		"02" + // vm index
+		"00031c7e" + // dbheight
+		"00000005" + // height
+		"00000001" + // signature count
+		strings.Repeat("aa", 32) + strings.Repeat("bb", 64)
+	raw, err := hex.DecodeString(
+		strings.Repeat("00", 31) + "0a" + // admin chain id
+			strings.Repeat("11", 32) + // previous back reference hash
+			"00031c7f" + // dbheight
+			"00" + // header expansion size
+			"00000001" + // message count
+			fmt.Sprintf("%08x", len(fault)/2) + // body size
+			fault)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ab := new(ABlock)
+	if err := ab.UnmarshalBinary(raw); err != nil {
+		t.Fatal(err)
+	}
+	if len(ab.ABEntries) != 1 {
+		t.Fatalf("expected 1 entry, recieved %d", len(ab.ABEntries))
+	}
+	f, ok := ab.ABEntries[0].(*AdminServerFault)
+	if !ok {
+		t.Fatalf("expected *AdminServerFault, recieved %T", ab.ABEntries[0])
+	}
+	if f.Timestamp != "1561126964312" || f.ServerID != strings.Repeat("88", 32) ||
+		f.VMIndex != 2 || f.DBHeight != 203902 || f.Height != 5 {
+		t.Errorf("unexpected server fault %s", f)
+	}
+	if p, err := ab.MarshalBinary(); err != nil || !bytes.Equal(p, raw) {
+		t.Errorf("expected:%x\nrecieved:%x %v", raw, p, err)
+	}
+	if err := ab.UnmarshalBinary(raw[:len(raw)-1]); err == nil {
+		t.Error("expected an error for truncated server fault")
+	}
+}
@@ -5,8 +5,21 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+)
+
+// The statuses of Transactions and Entries reported by factomd, from least to
+// most confirmed.
+const (
+	StatusUnknown         = "Unknown"
+	StatusNotConfirmed    = "NotConfirmed"
+	StatusTransactionACK  = "TransactionACK"
+	StatusDBlockConfirmed = "DBlockConfirmed"
 )
 
 // TransactionData is metadata about a given Transaction, including data about
@@ -75,9 +88,14 @@ func (e *EntryStatus) String() string {
 
 // FactoidACK gets the status of a given Factoid Transaction.
 func FactoidACK(txID, fullTransaction string) (*FactoidTxStatus, error) {
+	return DefaultClient.FactoidACK(context.Background(), txID, fullTransaction)
+}
+
+// FactoidACK gets the status of a given Factoid Transaction.
+func (c *Client) FactoidACK(ctx context.Context, txID, fullTransaction string) (*FactoidTxStatus, error) {
 	params := ackRequest{Hash: txID, ChainID: "f", FullTransaction: fullTransaction}
 	req := NewJSON2Request("ack", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -95,9 +113,14 @@ func FactoidACK(txID, fullTransaction string) (*FactoidTxStatus, error) {
 
 // EntryCommitACK searches for an entry/chain commit with a given transaction ID.
 func EntryCommitACK(txID, fullTransaction string) (*EntryStatus, error) {
+	return DefaultClient.EntryCommitACK(context.Background(), txID, fullTransaction)
+}
+
+// EntryCommitACK searches for an entry/chain commit with a given transaction ID.
+func (c *Client) EntryCommitACK(ctx context.Context, txID, fullTransaction string) (*EntryStatus, error) {
 	params := ackRequest{Hash: txID, ChainID: "c", FullTransaction: fullTransaction}
 	req := NewJSON2Request("ack", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -115,9 +138,14 @@ func EntryCommitACK(txID, fullTransaction string) (*EntryStatus, error) {
 
 // EntryRevealACK will take the entryhash and search for the entry and the commit
 func EntryRevealACK(entryhash, fullTransaction, chainiID string) (*EntryStatus, error) {
+	return DefaultClient.EntryRevealACK(context.Background(), entryhash, fullTransaction, chainiID)
+}
+
+// EntryRevealACK will take the entryhash and search for the entry and the commit
+func (c *Client) EntryRevealACK(ctx context.Context, entryhash, fullTransaction, chainiID string) (*EntryStatus, error) {
 	params := ackRequest{Hash: entryhash, ChainID: chainiID, FullTransaction: fullTransaction}
 	req := NewJSON2Request("ack", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -132,3 +160,271 @@ func EntryRevealACK(entryhash, fullTransaction, chainiID string) (*EntryStatus,
 
 	return eb, nil
 }
+
+// ackLevels orders the acknowledgement statuses.
+var ackLevels = map[string]int{
+	StatusUnknown:         0,
+	StatusNotConfirmed:    1,
+	StatusTransactionACK:  2,
+	StatusDBlockConfirmed: 3,
+}
+
+// ErrACKLevel is returned when waiting for a status that is not one of the
+// acknowledgement statuses.
+var ErrACKLevel = errors.New("invalid acknowledgement level")
+
+// DefaultACKBackoff is the time between status requests made while waiting
+// for an acknowledgement when FactomdACKBackoff is not set.
+var DefaultACKBackoff = &Backoff{
+	InitialInterval: time.Second,
+	MaxInterval:     10 * time.Second,
+	Multiplier:      1.5,
+	Jitter:          0.1,
+}
+
+// MalleatedError is returned when waiting for a Transaction that factomd
+// reports has been malleated, meaning it was confirmed under a different
+// TxID.
+type MalleatedError struct {
+	TxID           string
+	MalleatedTxIDs []string
+}
+
+func (e *MalleatedError) Error() string {
+	return fmt.Sprintf("transaction %s was malleated to %s",
+		e.TxID, strings.Join(e.MalleatedTxIDs, ", "))
+}
+
+// ConflictingRevealError is returned when waiting for an Entry commit that
+// factomd reports has been used by the reveals of other Entries.
+type ConflictingRevealError struct {
+	CommitTxID        string
+	EntryHash         string
+	ConflictingHashes []string
+}
+
+func (e *ConflictingRevealError) Error() string {
+	return fmt.Sprintf("commit %s has conflicting reveals %s",
+		e.CommitTxID, strings.Join(e.ConflictingHashes, ", "))
+}
+
+// ACKUpdate is a change of status seen while waiting for an acknowledgement.
+type ACKUpdate struct {
+	Status string
+
+	// Factoid is set when watching a Factoid Transaction, and Entry when
+	// watching an Entry or commit.
+	Factoid *FactoidTxStatus
+	Entry   *EntryStatus
+
+	// Err is set on a last update sent if the wait failed.
+	Err error
+}
+
+// WaitForFactoidACK polls FactoidACK until the Transaction reaches the given
+// status, such as StatusTransactionACK or StatusDBlockConfirmed.
+func WaitForFactoidACK(txID, level string) (*FactoidTxStatus, error) {
+	return DefaultClient.WaitForFactoidACK(context.Background(), txID, level)
+}
+
+// WaitForFactoidACK polls FactoidACK until the Transaction reaches the given
+// status, such as StatusTransactionACK or StatusDBlockConfirmed, or the
+// context is done. A malleated Transaction returns a *MalleatedError.
+func (c *Client) WaitForFactoidACK(ctx context.Context, txID, level string) (*FactoidTxStatus, error) {
+	v, err := c.waitACK(ctx, level, c.factoidACKPoll(txID), nil)
+	if v == nil {
+		return nil, err
+	}
+	return v.(*FactoidTxStatus), err
+}
+
+// WaitForEntryCommitACK polls EntryCommitACK until the commit reaches the
+// given status.
+func WaitForEntryCommitACK(txID, level string) (*EntryStatus, error) {
+	return DefaultClient.WaitForEntryCommitACK(context.Background(), txID, level)
+}
+
+// WaitForEntryCommitACK polls EntryCommitACK until the commit reaches the
+// given status, such as StatusTransactionACK or StatusDBlockConfirmed, or the
+// context is done. A commit with conflicting reveals returns a
+// *ConflictingRevealError.
+func (c *Client) WaitForEntryCommitACK(ctx context.Context, txID, level string) (*EntryStatus, error) {
+	v, err := c.waitACK(ctx, level, c.entryCommitACKPoll(txID), nil)
+	if v == nil {
+		return nil, err
+	}
+	return v.(*EntryStatus), err
+}
+
+// WaitForEntryRevealACK polls EntryRevealACK until the Entry reaches the given
+// status.
+func WaitForEntryRevealACK(entryhash, chainid, level string) (*EntryStatus, error) {
+	return DefaultClient.WaitForEntryRevealACK(context.Background(), entryhash, chainid, level)
+}
+
+// WaitForEntryRevealACK polls EntryRevealACK until the Entry reaches the given
+// status, such as StatusTransactionACK or StatusDBlockConfirmed, or the
+// context is done. An Entry whose commit has conflicting reveals returns a
+// *ConflictingRevealError.
+func (c *Client) WaitForEntryRevealACK(ctx context.Context, entryhash, chainid, level string) (*EntryStatus, error) {
+	v, err := c.waitACK(ctx, level, c.entryRevealACKPoll(entryhash, chainid), nil)
+	if v == nil {
+		return nil, err
+	}
+	return v.(*EntryStatus), err
+}
+
+// WatchFactoidACK waits for a Transaction like WaitForFactoidACK in a new
+// goroutine, sending every change of status to the returned channel. The
+// channel is closed after the Transaction reaches the given status or the
+// wait fails.
+func (c *Client) WatchFactoidACK(ctx context.Context, txID, level string) <-chan *ACKUpdate {
+	return c.watchACK(ctx, level, c.factoidACKPoll(txID))
+}
+
+// WatchEntryCommitACK waits for a commit like WaitForEntryCommitACK in a new
+// goroutine, sending every change of status to the returned channel. The
+// channel is closed after the commit reaches the given status or the wait
+// fails.
+func (c *Client) WatchEntryCommitACK(ctx context.Context, txID, level string) <-chan *ACKUpdate {
+	return c.watchACK(ctx, level, c.entryCommitACKPoll(txID))
+}
+
+// WatchEntryRevealACK waits for an Entry like WaitForEntryRevealACK in a new
+// goroutine, sending every change of status to the returned channel. The
+// channel is closed after the Entry reaches the given status or the wait
+// fails.
+func (c *Client) WatchEntryRevealACK(ctx context.Context, entryhash, chainid, level string) <-chan *ACKUpdate {
+	return c.watchACK(ctx, level, c.entryRevealACKPoll(entryhash, chainid))
+}
+
+// ackPoll requests a status once, returning the status, the ack result, and
+// an error for results that can never reach the wanted status.
+type ackPoll func(ctx context.Context) (string, interface{}, error)
+
+func (c *Client) factoidACKPoll(txID string) ackPoll {
+	return func(ctx context.Context) (string, interface{}, error) {
+		st, err := c.FactoidACK(ctx, txID, "")
+		if err != nil {
+			return "", nil, err
+		}
+		if ids := st.Malleated.MalleatedTxIDs; len(ids) > 0 {
+			return st.Status, st, &MalleatedError{TxID: txID, MalleatedTxIDs: ids}
+		}
+		return st.Status, st, nil
+	}
+}
+
+func (c *Client) entryCommitACKPoll(txID string) ackPoll {
+	return func(ctx context.Context) (string, interface{}, error) {
+		st, err := c.EntryCommitACK(ctx, txID, "")
+		if err != nil {
+			return "", nil, err
+		}
+		return st.CommitData.Status, st, entryACKError(st)
+	}
+}
+
+func (c *Client) entryRevealACKPoll(entryhash, chainid string) ackPoll {
+	return func(ctx context.Context) (string, interface{}, error) {
+		st, err := c.EntryRevealACK(ctx, entryhash, "", chainid)
+		if err != nil {
+			return "", nil, err
+		}
+		return st.EntryData.Status, st, entryACKError(st)
+	}
+}
+
+// entryACKError returns the typed error for a malleated commit or a commit
+// with conflicting reveals.
+func entryACKError(st *EntryStatus) error {
+	if ids := st.CommitData.Malleated.MalleatedTxIDs; len(ids) > 0 {
+		return &MalleatedError{TxID: st.CommitTxID, MalleatedTxIDs: ids}
+	}
+	if hs := st.ConflictingRevealEntryHashes; len(hs) > 0 {
+		return &ConflictingRevealError{
+			CommitTxID:        st.CommitTxID,
+			EntryHash:         st.EntryHash,
+			ConflictingHashes: hs,
+		}
+	}
+	return nil
+}
+
+// waitACK polls until the status reaches level, calling changed, if not nil,
+// with every new status. Transient request errors are retried.
+func (c *Client) waitACK(ctx context.Context, level string, poll ackPoll, changed func(string, interface{})) (interface{}, error) {
+	want, ok := ackLevels[level]
+	if !ok {
+		return nil, ErrACKLevel
+	}
+	b := c.config().FactomdACKBackoff
+	if b == nil {
+		b = DefaultACKBackoff
+	}
+
+	var last string
+	var v interface{}
+	for attempt := 1; ; attempt++ {
+		status, r, err := poll(ctx)
+		if err != nil && r == nil {
+			if ctx.Err() != nil {
+				return v, ctx.Err()
+			}
+			if !IsRetryableError(err) {
+				return v, err
+			}
+		}
+		if r != nil {
+			v = r
+			if status != last && changed != nil {
+				changed(status, v)
+			}
+			last = status
+			if err != nil {
+				return v, err
+			}
+			if ackLevels[status] >= want {
+				return v, nil
+			}
+		}
+
+		t := time.NewTimer(b.interval(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return v, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (c *Client) watchACK(ctx context.Context, level string, poll ackPoll) <-chan *ACKUpdate {
+	updates := make(chan *ACKUpdate)
+	send := func(status string, v interface{}, err error) {
+		u := &ACKUpdate{Status: status, Err: err}
+		switch st := v.(type) {
+		case *FactoidTxStatus:
+			u.Factoid = st
+		case *EntryStatus:
+			u.Entry = st
+		}
+		select {
+		case updates <- u:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		defer close(updates)
+		var last string
+		v, err := c.waitACK(ctx, level, poll, func(status string, v interface{}) {
+			last = status
+			send(status, v, nil)
+		})
+		if err != nil {
+			send(last, v, err)
+		}
+	}()
+	return updates
+}
@@ -5,9 +5,12 @@
 package factom_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
+	"time"
 
 	. "github.com/FactomProject/factom"
 
@@ -149,3 +152,91 @@ Date: 2015-12-07 22:10:01
 	}
 	t.Log(entryStatus.String())
 }
+
+// ackSequenceServer answers the n-th ack request with the n-th result, and
+// every later request with the last result.
+func ackSequenceServer(results ...string) *httptest.Server {
+	var n int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(atomic.AddInt32(&n, 1)) - 1
+		if i >= len(results) {
+			i = len(results) - 1
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":0,"result":%s}`, results[i])
+	}))
+}
+
+func TestWaitForACK(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(ts *httptest.Server) *Client {
+		b := NewBackoff()
+		b.InitialInterval = time.Millisecond
+		return NewClient(&RPCConfig{FactomdServer: ts.URL[7:], FactomdACKBackoff: b})
+	}
+
+	ts := ackSequenceServer(
+		`{"txid":"aa","status":"Unknown"}`,
+		`{"txid":"aa","status":"TransactionACK"}`,
+		`{"txid":"aa","status":"TransactionACK"}`,
+		`{"txid":"aa","status":"DBlockConfirmed"}`,
+	)
+	c := newClient(ts)
+	st, err := c.WaitForFactoidACK(ctx, "aa", StatusTransactionACK)
+	if err != nil || st.Status != StatusTransactionACK {
+		t.Errorf("expected %s, recieved %v %v", StatusTransactionACK, st, err)
+	}
+	var statuses []string
+	for u := range c.WatchFactoidACK(ctx, "aa", StatusDBlockConfirmed) {
+		if u.Err != nil || u.Factoid == nil {
+			t.Errorf("unexpected update %v", u)
+		}
+		statuses = append(statuses, u.Status)
+	}
+	if fmt.Sprint(statuses) != "[TransactionACK DBlockConfirmed]" {
+		t.Errorf("unexpected statuses %v", statuses)
+	}
+	if _, err := c.WaitForFactoidACK(ctx, "aa", "Confirmed"); err != ErrACKLevel {
+		t.Errorf("expected %v, recieved %v", ErrACKLevel, err)
+	}
+	ts.Close()
+
+	// waiting ends with the context
+	ts = ackSequenceServer(`{"txid":"aa","status":"NotConfirmed"}`)
+	c = newClient(ts)
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	st, err = c.WaitForFactoidACK(tctx, "aa", StatusDBlockConfirmed)
+	cancel()
+	if err != context.DeadlineExceeded || st == nil || st.Status != StatusNotConfirmed {
+		t.Errorf("expected %v, recieved %v %v", context.DeadlineExceeded, st, err)
+	}
+	ts.Close()
+
+	ts = ackSequenceServer(`{"txid":"aa","status":"TransactionACK","malleated":{"malleatedtxids":["bb"]}}`)
+	c = newClient(ts)
+	_, err = c.WaitForFactoidACK(ctx, "aa", StatusDBlockConfirmed)
+	if merr, ok := err.(*MalleatedError); !ok || merr.TxID != "aa" || merr.MalleatedTxIDs[0] != "bb" {
+		t.Errorf("expected *MalleatedError, recieved %v", err)
+	}
+	ts.Close()
+
+	ts = ackSequenceServer(
+		`{"committxid":"cc","entryhash":"dd","commitdata":{"status":"TransactionACK"},"entrydata":{"status":"NotConfirmed"}}`,
+		`{"committxid":"cc","entryhash":"dd","commitdata":{"status":"TransactionACK"},"entrydata":{"status":"Unknown"},`+
+			`"conflictingrevealentryhashes":["ee"]}`,
+	)
+	c = newClient(ts)
+	es, err := c.WaitForEntryCommitACK(ctx, "cc", StatusTransactionACK)
+	if err != nil || es.CommitTxID != "cc" {
+		t.Errorf("expected commit cc, recieved %v %v", es, err)
+	}
+	var last *ACKUpdate
+	for u := range c.WatchEntryRevealACK(ctx, "dd", ZeroHash, StatusDBlockConfirmed) {
+		last = u
+	}
+	if cerr, ok := last.Err.(*ConflictingRevealError); !ok || cerr.CommitTxID != "cc" ||
+		cerr.ConflictingHashes[0] != "ee" || last.Entry == nil {
+		t.Errorf("expected *ConflictingRevealError, recieved %v", last)
+	}
+	ts.Close()
+}
@@ -94,6 +94,27 @@ func IsValidAddress(s string) bool {
 	return false
 }
 
+// encodePubAddress returns the public address string for an RCD Hash or an
+// Entry Credit public key with the given prefix.
+func encodePubAddress(prefix, key []byte) string {
+	buf := new(bytes.Buffer)
+	buf.Write(prefix)
+	buf.Write(key)
+	buf.Write(shad(buf.Bytes())[:ChecksumLength])
+	return base58.Encode(buf.Bytes())
+}
+
+// decodePubAddress returns the RCD Hash or Entry Credit public key of a
+// public address string with the given prefix.
+func decodePubAddress(prefix []byte, s string) ([]byte, error) {
+	p := base58.Decode(s)
+	if len(p) != AddressLength || !bytes.Equal(p[:PrefixLength], prefix) ||
+		!bytes.Equal(shad(p[:BodyLength])[:ChecksumLength], p[BodyLength:]) {
+		return nil, ErrInvalidAddress
+	}
+	return p[PrefixLength:BodyLength], nil
+}
+
 // ECAddress is an Entry Credit public/secret key pair.
 type ECAddress struct {
 	Pub *[ed.PublicKeySize]byte
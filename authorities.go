@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -19,6 +20,7 @@ type Authority struct {
 	SigningKey        string              `json:"signingkey"`
 	Status            string              `json:"status"`
 	AnchorKeys        []*AnchorSigningKey `json:"anchorkeys"`
+	Efficiency        int                 `json:"efficiency"`
 }
 
 func (a *Authority) String() string {
@@ -29,6 +31,7 @@ func (a *Authority) String() string {
 	s += fmt.Sprintln("MatryoshkaHash:", a.MatryoshkaHash)
 	s += fmt.Sprintln("SigningKey:", a.SigningKey)
 	s += fmt.Sprintln("Status:", a.Status)
+	s += fmt.Sprintln("Efficiency:", a.Efficiency)
 
 	s += fmt.Sprintln("AnchorKeys {")
 	for _, k := range a.AnchorKeys {
@@ -61,8 +64,13 @@ func (k *AnchorSigningKey) String() string {
 
 // GetAuthorities retrieves a list of the known athorities from factomd.
 func GetAuthorities() ([]*Authority, error) {
+	return DefaultClient.GetAuthorities(context.Background())
+}
+
+// GetAuthorities retrieves a list of the known athorities from factomd.
+func (c *Client) GetAuthorities(ctx context.Context) ([]*Authority, error) {
 	req := NewJSON2Request("authorities", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
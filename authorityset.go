@@ -0,0 +1,352 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Authority Status values reported by factomd.
+const (
+	AuthorityFederated = "federated"
+	AuthorityAudit     = "audit"
+)
+
+// AuthorityMismatchError lists the differences between an AuthoritySet and
+// the authorities reported by factomd.
+type AuthorityMismatchError struct {
+	Height      int64
+	Differences []string
+}
+
+func (e *AuthorityMismatchError) Error() string {
+	return fmt.Sprintf("authority set at height %d does not match factomd: %s",
+		e.Height, strings.Join(e.Differences, "; "))
+}
+
+// AuthoritySet is the set of Federated and Audit Servers, with their signing
+// keys, anchor keys and efficiency, built by replaying Admin Blocks.
+//
+// The set at a height is the result of every Admin Block up to and including
+// that height. Adding and removing servers and their signing keys take effect
+// at the DBHeight given in the Admin Block Entry, which is usually the next
+// height, so the set at a height holds the servers of that block: the
+// Federated Servers that sign the previous Directory Block in its Admin
+// Block.
+type AuthoritySet struct {
+	// Height is the height of the last Admin Block applied, or -1 before the
+	// genesis block.
+	Height int64
+
+	// authorities holds every identity seen, with an empty Status if it is
+	// not a server at Height.
+	authorities map[string]*Authority
+
+	// scheduled are the Entries of applied Admin Blocks that take effect
+	// after Height, in the order they were applied.
+	scheduled []ABEntry
+}
+
+// NewAuthoritySet returns an AuthoritySet at a height from a checkpoint of
+// the authorities known at that height, such as those returned by
+// GetAuthorities. A height of -1 with no authorities starts before the
+// genesis block. A checkpoint does not know of changes scheduled for later
+// heights by earlier Admin Blocks; use Copy to keep a set that does.
+func NewAuthoritySet(height int64, authorities []*Authority) *AuthoritySet {
+	s := &AuthoritySet{Height: height, authorities: make(map[string]*Authority)}
+	for _, a := range authorities {
+		s.authorities[a.AuthorityChainID] = copyAuthority(a)
+	}
+	return s
+}
+
+// copyAuthority returns a deep copy of an Authority.
+func copyAuthority(a *Authority) *Authority {
+	c := *a
+	c.AnchorKeys = make([]*AnchorSigningKey, len(a.AnchorKeys))
+	for i, k := range a.AnchorKeys {
+		kc := *k
+		c.AnchorKeys[i] = &kc
+	}
+	return &c
+}
+
+// Copy returns a copy of the AuthoritySet, with its scheduled changes, that
+// is not changed by applying later Admin Blocks to s.
+func (s *AuthoritySet) Copy() *AuthoritySet {
+	c := &AuthoritySet{Height: s.Height, authorities: make(map[string]*Authority)}
+	for id, a := range s.authorities {
+		c.authorities[id] = copyAuthority(a)
+	}
+	c.scheduled = append(c.scheduled, s.scheduled...)
+	return c
+}
+
+// Authorities returns the Federated and Audit Servers ordered by Chain ID.
+func (s *AuthoritySet) Authorities() []*Authority {
+	as := make([]*Authority, 0, len(s.authorities))
+	for _, a := range s.authorities {
+		if a.Status == "" {
+			continue
+		}
+		as = append(as, copyAuthority(a))
+	}
+	sort.Slice(as, func(i, j int) bool {
+		return as[i].AuthorityChainID < as[j].AuthorityChainID
+	})
+	return as
+}
+
+// Federated returns the Federated Servers ordered by Chain ID.
+func (s *AuthoritySet) Federated() []*Authority {
+	return s.withStatus(AuthorityFederated)
+}
+
+// Audit returns the Audit Servers ordered by Chain ID.
+func (s *AuthoritySet) Audit() []*Authority {
+	return s.withStatus(AuthorityAudit)
+}
+
+func (s *AuthoritySet) withStatus(status string) []*Authority {
+	as := make([]*Authority, 0)
+	for _, a := range s.Authorities() {
+		if a.Status == status {
+			as = append(as, a)
+		}
+	}
+	return as
+}
+
+// Authority returns the server with an identity Chain ID, or nil if it is not
+// in the set.
+func (s *AuthoritySet) Authority(chainid string) *Authority {
+	a, ok := s.authorities[chainid]
+	if !ok || a.Status == "" {
+		return nil
+	}
+	return copyAuthority(a)
+}
+
+// identity returns the identity with a Chain ID, adding it to the set, but
+// not as a server, if it has not been seen before.
+func (s *AuthoritySet) identity(chainid string) *Authority {
+	a, ok := s.authorities[chainid]
+	if !ok {
+		a = &Authority{AuthorityChainID: chainid, AnchorKeys: make([]*AnchorSigningKey, 0)}
+		s.authorities[chainid] = a
+	}
+	return a
+}
+
+// Apply updates the AuthoritySet with the Admin Block at the next height.
+// Entries scheduled for a later height are kept until the set reaches it.
+func (s *AuthoritySet) Apply(ab *ABlock) error {
+	if ab.DBHeight != s.Height+1 {
+		return fmt.Errorf("admin block height %d does not follow authority set height %d", ab.DBHeight, s.Height)
+	}
+
+	due := make([]ABEntry, 0)
+	scheduled := make([]ABEntry, 0)
+	for _, e := range append(s.scheduled, ab.ABEntries...) {
+		if activationHeight(e) > ab.DBHeight {
+			scheduled = append(scheduled, e)
+		} else {
+			due = append(due, e)
+		}
+	}
+	for _, e := range due {
+		s.apply(e)
+	}
+
+	s.scheduled = scheduled
+	s.Height = ab.DBHeight
+	return nil
+}
+
+// activationHeight returns the height at which an Admin Block Entry takes
+// effect, or 0 for Entries that take effect in their own block.
+func activationHeight(e ABEntry) int64 {
+	switch e := e.(type) {
+	case *AdminAddFederatedServer:
+		return e.DBHeight
+	case *AdminAddAuditServer:
+		return e.DBHeight
+	case *AdminRemoveFederatedServer:
+		return e.DBHeight
+	case *AdminAddFederatedServerKey:
+		return int64(e.DBHeight)
+	}
+	return 0
+}
+
+func (s *AuthoritySet) apply(v ABEntry) {
+	switch e := v.(type) {
+	case *AdminAddFederatedServer:
+		s.identity(e.IdentityChainID).Status = AuthorityFederated
+	case *AdminAddAuditServer:
+		s.identity(e.IdentityChainID).Status = AuthorityAudit
+	case *AdminRemoveFederatedServer:
+		s.identity(e.IdentityChainID).Status = ""
+	case *AdminServerFault:
+		// the faulted Federated Server swaps places with the Audit Server
+		if a, ok := s.authorities[e.ServerID]; ok && a.Status != "" {
+			a.Status = AuthorityAudit
+		}
+		if a, ok := s.authorities[e.AuditServerID]; ok && a.Status != "" {
+			a.Status = AuthorityFederated
+		}
+	case *AdminAddFederatedServerKey:
+		s.identity(e.IdentityChainID).SigningKey = e.PublicKey
+	case *AdminAddFederatedServerBTCKey:
+		s.identity(e.IdentityChainID).setAnchorKey(&AnchorSigningKey{
+			BlockChain: "BTC",
+			KeyLevel:   byte(e.KeyPriority),
+			KeyType:    byte(e.KeyType),
+			SigningKey: e.ECDSAPublicKey,
+		})
+	case *AdminAddHash:
+		s.identity(e.IdentityChainID).MatryoshkaHash = e.MatryoshkaHash
+	case *AdminAddAuthorityEfficiency:
+		s.identity(e.IdentityChainID).Efficiency = e.Efficiency
+	}
+}
+
+// setAnchorKey replaces the anchor key of the same blockchain and level, or
+// adds it.
+func (a *Authority) setAnchorKey(k *AnchorSigningKey) {
+	for i, v := range a.AnchorKeys {
+		if v.BlockChain == k.BlockChain && v.KeyLevel == k.KeyLevel {
+			a.AnchorKeys[i] = k
+			return
+		}
+	}
+	a.AnchorKeys = append(a.AnchorKeys, k)
+}
+
+// Compare checks the AuthoritySet against a list of authorities, such as
+// those returned by GetAuthorities at the same height. It returns an
+// *AuthorityMismatchError listing any differences in the servers, their
+// status, signing keys, anchor keys or efficiency.
+func (s *AuthoritySet) Compare(authorities []*Authority) error {
+	diffs := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, b := range authorities {
+		seen[b.AuthorityChainID] = true
+		a, ok := s.authorities[b.AuthorityChainID]
+		if !ok || a.Status == "" {
+			diffs = append(diffs, fmt.Sprintf("%s is missing", b.AuthorityChainID))
+			continue
+		}
+		if a.Status != b.Status {
+			diffs = append(diffs, fmt.Sprintf("%s is %s not %s", a.AuthorityChainID, a.Status, b.Status))
+		}
+		if a.SigningKey != b.SigningKey {
+			diffs = append(diffs, fmt.Sprintf("%s has signing key %s not %s", a.AuthorityChainID, a.SigningKey, b.SigningKey))
+		}
+		if a.Efficiency != b.Efficiency {
+			diffs = append(diffs, fmt.Sprintf("%s has efficiency %d not %d", a.AuthorityChainID, a.Efficiency, b.Efficiency))
+		}
+		if !sameAnchorKeys(a.AnchorKeys, b.AnchorKeys) {
+			diffs = append(diffs, fmt.Sprintf("%s has different anchor keys", a.AuthorityChainID))
+		}
+	}
+	for _, a := range s.Authorities() {
+		if !seen[a.AuthorityChainID] {
+			diffs = append(diffs, fmt.Sprintf("%s is not an authority", a.AuthorityChainID))
+		}
+	}
+
+	if len(diffs) > 0 {
+		return &AuthorityMismatchError{Height: s.Height, Differences: diffs}
+	}
+	return nil
+}
+
+// sameAnchorKeys reports whether two lists hold the same anchor keys in any
+// order.
+func sameAnchorKeys(a, b []*AnchorSigningKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	keys := make(map[AnchorSigningKey]int)
+	for _, k := range a {
+		keys[*k]++
+	}
+	for _, k := range b {
+		if keys[*k] == 0 {
+			return false
+		}
+		keys[*k]--
+	}
+	return true
+}
+
+// GetAuthoritySetAtHeight replays the Admin Blocks from the genesis block to
+// build the AuthoritySet at a height.
+func GetAuthoritySetAtHeight(height int64) (*AuthoritySet, error) {
+	return DefaultClient.GetAuthoritySetAtHeight(context.Background(), height)
+}
+
+// GetAuthoritySetAtHeight replays the Admin Blocks from the genesis block to
+// build the AuthoritySet at a height.
+func (c *Client) GetAuthoritySetAtHeight(ctx context.Context, height int64) (*AuthoritySet, error) {
+	s := NewAuthoritySet(-1, nil)
+	if err := c.UpdateAuthoritySet(ctx, s, height); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UpdateAuthoritySet replays the Admin Blocks after the height of an
+// AuthoritySet up to and including a later height. If an Admin Block cannot
+// be retrieved the AuthoritySet is left at the last height applied.
+func UpdateAuthoritySet(s *AuthoritySet, height int64) error {
+	return DefaultClient.UpdateAuthoritySet(context.Background(), s, height)
+}
+
+// UpdateAuthoritySet replays the Admin Blocks after the height of an
+// AuthoritySet up to and including a later height. If an Admin Block cannot
+// be retrieved the AuthoritySet is left at the last height applied.
+func (c *Client) UpdateAuthoritySet(ctx context.Context, s *AuthoritySet, height int64) error {
+	if height < s.Height {
+		return fmt.Errorf("cannot rewind authority set from height %d to %d", s.Height, height)
+	}
+	for h := s.Height + 1; h <= height; h++ {
+		ab, _, err := c.GetABlockByHeight(ctx, h)
+		if err != nil {
+			return err
+		}
+		if err := s.Apply(ab); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckAuthoritySet compares an AuthoritySet with the authorities reported by
+// factomd, which must be at the same Directory Block height.
+func CheckAuthoritySet(s *AuthoritySet) error {
+	return DefaultClient.CheckAuthoritySet(context.Background(), s)
+}
+
+// CheckAuthoritySet compares an AuthoritySet with the authorities reported by
+// factomd, which must be at the same Directory Block height.
+func (c *Client) CheckAuthoritySet(ctx context.Context, s *AuthoritySet) error {
+	heights, err := c.GetHeights(ctx)
+	if err != nil {
+		return err
+	}
+	if heights.DirectoryBlockHeight != s.Height {
+		return fmt.Errorf("factomd is at height %d not %d", heights.DirectoryBlockHeight, s.Height)
+	}
+	authorities, err := c.GetAuthorities(ctx)
+	if err != nil {
+		return err
+	}
+	return s.Compare(authorities)
+}
@@ -0,0 +1,128 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/factomtest"
+)
+
+func TestAuthoritySet(t *testing.T) {
+	ctx := context.Background()
+	s := factomtest.NewServer()
+	defer s.Close()
+	c := s.Client()
+
+	fed := "888888" + strings.Repeat("a", 58)
+	audit := "888888" + strings.Repeat("b", 58)
+	key1 := strings.Repeat("1", 64)
+	key2 := strings.Repeat("2", 64)
+	btc := strings.Repeat("3", 40)
+
+	// height 1
+	s.AddAdminEntry(&AdminAddFederatedServer{IdentityChainID: fed, DBHeight: 2})
+	s.AddAdminEntry(&AdminAddAuditServer{IdentityChainID: audit, DBHeight: 2})
+	s.AddAdminEntry(&AdminAddFederatedServerKey{IdentityChainID: fed, PublicKey: key1, DBHeight: 2})
+	s.AddAdminEntry(&AdminAddFederatedServerBTCKey{IdentityChainID: fed, ECDSAPublicKey: btc})
+	s.AddAdminEntry(&AdminAddAuthorityEfficiency{IdentityChainID: fed, Efficiency: 4000})
+	s.NextBlock()
+
+	// height 2: the servers swap places
+	s.AddAdminEntry(&AdminAddFederatedServer{IdentityChainID: audit, DBHeight: 3})
+	s.AddAdminEntry(&AdminAddAuditServer{IdentityChainID: fed, DBHeight: 3})
+	s.AddAdminEntry(&AdminAddFederatedServerKey{IdentityChainID: audit, PublicKey: key2, DBHeight: 3})
+	s.NextBlock()
+
+	// height 3: the removal takes effect a block later than usual
+	s.AddAdminEntry(&AdminRemoveFederatedServer{IdentityChainID: fed, DBHeight: 5})
+	s.NextBlock()
+	s.NextBlock()
+	s.NextBlock()
+
+	// the servers added at height 1 take effect at height 2
+	set, err := c.GetAuthoritySetAtHeight(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if as := set.Authorities(); len(as) != 0 {
+		t.Errorf("expected no authorities at height 1, recieved %v", as)
+	}
+	if err := c.UpdateAuthoritySet(ctx, set, 2); err != nil {
+		t.Fatal(err)
+	}
+	f := set.Federated()
+	if len(f) != 1 || f[0].AuthorityChainID != fed || f[0].SigningKey != key1 || f[0].Efficiency != 4000 ||
+		len(f[0].AnchorKeys) != 1 || f[0].AnchorKeys[0].SigningKey != btc {
+		t.Errorf("unexpected federated servers %v", f)
+	}
+	if a := set.Audit(); len(a) != 1 || a[0].AuthorityChainID != audit || a[0].SigningKey != "" {
+		t.Errorf("unexpected audit servers %v", a)
+	}
+
+	// continue from a checkpoint
+	at2 := set.Copy()
+	if err := c.UpdateAuthoritySet(ctx, set, 4); err != nil {
+		t.Fatal(err)
+	}
+	if a := set.Authority(fed); a == nil || a.Status != AuthorityAudit || a.SigningKey != key1 {
+		t.Errorf("expected %s audit, recieved %v", fed, a)
+	}
+	if a := set.Authority(audit); a == nil || a.Status != AuthorityFederated || a.SigningKey != key2 {
+		t.Errorf("expected %s federated, recieved %v", audit, a)
+	}
+	if a := at2.Authority(fed); a == nil || a.Status != AuthorityFederated {
+		t.Errorf("expected %s federated at height 2, recieved %v", fed, a)
+	}
+
+	if err := c.CheckAuthoritySet(ctx, set); err == nil {
+		t.Errorf("expected height error, recieved %v", err)
+	}
+	if err := c.UpdateAuthoritySet(ctx, set, 5); err != nil {
+		t.Fatal(err)
+	}
+	if as := set.Authorities(); len(as) != 1 || as[0].AuthorityChainID != audit {
+		t.Errorf("unexpected authorities %v", as)
+	}
+
+	// the copy keeps the removal scheduled before it
+	if err := c.UpdateAuthoritySet(ctx, at2, 5); err != nil {
+		t.Fatal(err)
+	}
+	if as := at2.Authorities(); len(as) != 1 || as[0].AuthorityChainID != audit {
+		t.Errorf("unexpected authorities %v", as)
+	}
+
+	// cross-check with factomd
+	s.SetAuthorities([]*Authority{{
+		AuthorityChainID: audit,
+		SigningKey:       key2,
+		Status:           AuthorityFederated,
+		AnchorKeys:       []*AnchorSigningKey{},
+	}})
+	if err := c.CheckAuthoritySet(ctx, set); err != nil {
+		t.Error(err)
+	}
+	at2, err = c.GetAuthoritySetAtHeight(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetAuthorities(at2.Authorities())
+	err = c.CheckAuthoritySet(ctx, set)
+	if merr, ok := err.(*AuthorityMismatchError); !ok || merr.Height != 5 || len(merr.Differences) != 3 {
+		t.Errorf("expected 3 differences, recieved %v", err)
+	}
+
+	ab, _, err := c.GetABlockByHeight(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Apply(ab); err == nil {
+		t.Errorf("expected height error, recieved %v", err)
+	}
+}
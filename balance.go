@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 )
 
@@ -21,13 +22,19 @@ type MultiBalanceResponse struct {
 // GetECBalance returns the balance in factoshi (factoid * 1e8) of a given Entry
 // Credit Public Address.
 func GetECBalance(addr string) (int64, error) {
+	return DefaultClient.GetECBalance(context.Background(), addr)
+}
+
+// GetECBalance returns the balance in factoshi (factoid * 1e8) of a given Entry
+// Credit Public Address.
+func (c *Client) GetECBalance(ctx context.Context, addr string) (int64, error) {
 	type balanceResponse struct {
 		Balance int64 `json:"balance"`
 	}
 
 	params := addressRequest{Address: addr}
 	req := NewJSON2Request("entry-credit-balance", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return -1, err
 	}
@@ -46,13 +53,19 @@ func GetECBalance(addr string) (int64, error) {
 // GetFactoidBalance returns the balance in factoshi (factoid * 1e8) of a given
 // Factoid Public Address.
 func GetFactoidBalance(addr string) (int64, error) {
+	return DefaultClient.GetFactoidBalance(context.Background(), addr)
+}
+
+// GetFactoidBalance returns the balance in factoshi (factoid * 1e8) of a given
+// Factoid Public Address.
+func (c *Client) GetFactoidBalance(ctx context.Context, addr string) (int64, error) {
 	type balanceResponse struct {
 		Balance int64 `json:"balance"`
 	}
 
 	params := addressRequest{Address: addr}
 	req := NewJSON2Request("factoid-balance", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return -1, err
 	}
@@ -72,6 +85,13 @@ func GetFactoidBalance(addr string) (int64, error) {
 // wallet according to the the server acknowledgement and the value saved in the
 // blockchain.
 func GetBalanceTotals() (fs, fa, es, ea int64, err error) {
+	return DefaultClient.GetBalanceTotals(context.Background())
+}
+
+// GetBalanceTotals return the total value of Factoids and Entry Credits in the
+// wallet according to the the server acknowledgement and the value saved in the
+// blockchain.
+func (c *Client) GetBalanceTotals(ctx context.Context) (fs, fa, es, ea int64, err error) {
 	type multiBalanceResponse struct {
 		FactoidAccountBalances struct {
 			Ack   int64 `json:"ack"`
@@ -84,7 +104,7 @@ func GetBalanceTotals() (fs, fa, es, ea int64, err error) {
 	}
 
 	req := NewJSON2Request("wallet-balances", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return
 	}
@@ -106,13 +126,19 @@ func GetBalanceTotals() (fs, fa, es, ea int64, err error) {
 // GetMultipleFCTBalances returns balances for multiple Factoid Addresses from
 // the factomd API.
 func GetMultipleFCTBalances(fas ...string) (*MultiBalanceResponse, error) {
+	return DefaultClient.GetMultipleFCTBalances(context.Background(), fas...)
+}
+
+// GetMultipleFCTBalances returns balances for multiple Factoid Addresses from
+// the factomd API.
+func (c *Client) GetMultipleFCTBalances(ctx context.Context, fas ...string) (*MultiBalanceResponse, error) {
 	type multiAddressRequest struct {
 		Addresses []string `json:"addresses"`
 	}
 
 	params := multiAddressRequest{fas}
 	req := NewJSON2Request("multiple-fct-balances", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -129,13 +155,19 @@ func GetMultipleFCTBalances(fas ...string) (*MultiBalanceResponse, error) {
 // GetMultipleECBalances returns balances for multiple Entry Credit Addresses
 // from the factomd API.
 func GetMultipleECBalances(ecs ...string) (*MultiBalanceResponse, error) {
+	return DefaultClient.GetMultipleECBalances(context.Background(), ecs...)
+}
+
+// GetMultipleECBalances returns balances for multiple Entry Credit Addresses
+// from the factomd API.
+func (c *Client) GetMultipleECBalances(ctx context.Context, ecs ...string) (*MultiBalanceResponse, error) {
 	type multiAddressRequest struct {
 		Addresses []string `json:"addresses"`
 	}
 
 	params := multiAddressRequest{ecs}
 	req := NewJSON2Request("multiple-ec-balances", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,225 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultFactomdBatchSize is the default maximum number of requests sent to
+// factomd in a single JSON RPC batch.
+const DefaultFactomdBatchSize = 100
+
+// DefaultFactomdConcurrency is the default maximum number of JSON RPC batches
+// sent to factomd at once.
+const DefaultFactomdConcurrency = 4
+
+// SendFactomdBatchRequest sends a list of JSON RPC requests to factomd as JSON
+// RPC 2.0 batches and returns the responses in the same order as the requests.
+// Every request must have a unique ID.
+func SendFactomdBatchRequest(reqs ...*JSON2Request) ([]*JSON2Response, error) {
+	return DefaultClient.SendFactomdBatchRequest(context.Background(), reqs...)
+}
+
+// SendFactomdBatchRequest sends a list of JSON RPC requests to factomd as JSON
+// RPC 2.0 batches and returns the responses in the same order as the requests.
+// Every request must have a unique ID.
+func (c *Client) SendFactomdBatchRequest(ctx context.Context, reqs ...*JSON2Request) ([]*JSON2Response, error) {
+	return c.factomdBatchRequest(ctx, reqs)
+}
+
+// factomdBatchRequest splits the requests into batches of at most
+// FactomdBatchSize requests and sends up to FactomdConcurrency batches to
+// factomd at once. The responses are matched to their requests by ID and
+// returned in the order of the requests. The first failed batch cancels the
+// others.
+func (c *Client) factomdBatchRequest(ctx context.Context, reqs []*JSON2Request) ([]*JSON2Response, error) {
+	cfg := c.config()
+	size := cfg.FactomdBatchSize
+	if size <= 0 {
+		size = DefaultFactomdBatchSize
+	}
+	workers := cfg.FactomdConcurrency
+	if workers <= 0 {
+		workers = DefaultFactomdConcurrency
+	}
+
+	total := len(reqs)
+	batches := make([][]*JSON2Request, 0, len(reqs)/size+1)
+	for len(reqs) > 0 {
+		n := size
+		if n > len(reqs) {
+			n = len(reqs)
+		}
+		batches = append(batches, reqs[:n])
+		reqs = reqs[n:]
+	}
+	if workers > len(batches) {
+		workers = len(batches)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]*JSON2Response, len(batches))
+	jobs := make(chan int)
+	errs := make(chan error, 1)
+	wg := new(sync.WaitGroup)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r, err := c.factomdBatch(ctx, batches[i])
+				if err != nil {
+					select {
+					case errs <- err:
+						cancel()
+					default:
+					}
+					continue
+				}
+				results[i] = r
+			}
+		}()
+	}
+
+feed:
+	for i := range batches {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+	}
+
+	resps := make([]*JSON2Response, 0, total)
+	for _, r := range results {
+		resps = append(resps, r...)
+	}
+
+	return resps, nil
+}
+
+// batchState records the factomd configurations that do not support batches.
+type batchState struct {
+	sync.Mutex
+	unsupported map[string]bool
+}
+
+// batchKey identifies the factomd servers of a configuration.
+func batchKey(cfg *RPCConfig) string {
+	if len(cfg.FactomdServers) == 0 {
+		return cfg.FactomdServer
+	}
+	return strings.Join(cfg.FactomdServers, ",")
+}
+
+func (b *batchState) supported(key string) bool {
+	b.Lock()
+	defer b.Unlock()
+	return !b.unsupported[key]
+}
+
+func (b *batchState) setUnsupported(key string) {
+	b.Lock()
+	defer b.Unlock()
+	if b.unsupported == nil {
+		b.unsupported = make(map[string]bool)
+	}
+	b.unsupported[key] = true
+}
+
+// factomdBatch sends a single JSON RPC batch to factomd. If the server does not
+// support batches and answers with a single response object the requests are
+// sent one at a time instead, and so are the later batches to the same
+// servers.
+func (c *Client) factomdBatch(ctx context.Context, reqs []*JSON2Request) ([]*JSON2Response, error) {
+	key := batchKey(c.config())
+	if !c.batches.supported(key) {
+		return c.factomdEach(ctx, reqs)
+	}
+
+	index := make(map[string]int)
+	for i, req := range reqs {
+		id, err := json.Marshal(req.ID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := index[string(id)]; ok {
+			return nil, fmt.Errorf("Duplicate request ID %s in batch", id)
+		}
+		index[string(id)] = i
+	}
+
+	var body []byte
+	err := c.retry(ctx, reqs, func() (err error) {
+		body, err = c.factomdPost(ctx, reqs, reqs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if b := bytes.TrimSpace(body); len(b) == 0 || b[0] != '[' {
+		c.batches.setUnsupported(key)
+		return c.factomdEach(ctx, reqs)
+	}
+
+	list := make([]*JSON2Response, 0, len(reqs))
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+
+	resps := make([]*JSON2Response, len(reqs))
+	for _, r := range list {
+		id, err := json.Marshal(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[string(id)]
+		if !ok {
+			return nil, fmt.Errorf("Unexpected response ID %s in batch", id)
+		}
+		resps[i] = r
+	}
+	for i, r := range resps {
+		if r == nil {
+			return nil, fmt.Errorf("Missing response for request ID %v in batch", reqs[i].ID)
+		}
+	}
+
+	return resps, nil
+}
+
+// factomdEach sends the requests to factomd one at a time.
+func (c *Client) factomdEach(ctx context.Context, reqs []*JSON2Request) ([]*JSON2Response, error) {
+	resps := make([]*JSON2Response, 0, len(reqs))
+	for _, req := range reqs {
+		resp, err := c.factomdRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/FactomProject/factom"
+
+	"testing"
+)
+
+// newBatchTestServer returns a factomd stand-in serving an Entry Block with n
+// Entries. Each Entry's content is its own Entry Hash. If batches is false the
+// server rejects JSON RPC batches like a server without batch support.
+func newBatchTestServer(n int, batches bool, posts *int32) *httptest.Server {
+	handle := func(req *JSON2Request) *JSON2Response {
+		resp := NewJSON2Response()
+		resp.ID = req.ID
+		switch req.Method {
+		case "entry-block":
+			eb := new(EBlock)
+			for i := 0; i < n; i++ {
+				eb.EntryList = append(eb.EntryList, EBEntry{EntryHash: fmt.Sprintf("%064x", i)})
+			}
+			resp.Result, _ = json.Marshal(eb)
+		case "entry":
+			params := new(struct {
+				Hash string `json:"hash"`
+			})
+			json.Unmarshal(req.Params, params)
+			e := NewEntryFromStrings(ZeroHash, params.Hash)
+			resp.Result, _ = json.Marshal(e)
+		default:
+			resp.Error = NewJSONError(-32601, "Method not found", nil)
+		}
+		return resp
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(posts, 1)
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := ioutil.ReadAll(r.Body)
+
+		if body[0] == '[' {
+			if !batches {
+				resp := NewJSON2Response()
+				resp.Error = NewJSONError(-32600, "Invalid Request", nil)
+				json.NewEncoder(w).Encode(resp)
+				return
+			}
+			var reqs []*JSON2Request
+			json.Unmarshal(body, &reqs)
+			// answer in reverse order to check the responses are matched by id
+			resps := make([]*JSON2Response, 0)
+			for i := len(reqs) - 1; i >= 0; i-- {
+				resps = append(resps, handle(reqs[i]))
+			}
+			json.NewEncoder(w).Encode(resps)
+			return
+		}
+
+		req := new(JSON2Request)
+		json.Unmarshal(body, req)
+		json.NewEncoder(w).Encode(handle(req))
+	}))
+}
+
+func TestGetAllEBlockEntriesBatched(t *testing.T) {
+	var posts int32
+	ts := newBatchTestServer(250, true, &posts)
+	defer ts.Close()
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+	es, err := c.GetAllEBlockEntries(context.Background(), ZeroHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(es) != 250 {
+		t.Fatalf("expected 250 entries, recieved %d", len(es))
+	}
+	for i, e := range es {
+		if string(e.Content) != fmt.Sprintf("%064x", i) {
+			t.Errorf("entry %d out of order: %s", i, e.Content)
+		}
+	}
+	// one entry-block request and three batches of at most 100 entries
+	if n := atomic.LoadInt32(&posts); n != 4 {
+		t.Errorf("expected 4 requests, recieved %d", n)
+	}
+}
+
+func TestBatchFallback(t *testing.T) {
+	var posts int32
+	ts := newBatchTestServer(5, false, &posts)
+	defer ts.Close()
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:], FactomdBatchSize: 2, FactomdConcurrency: 1})
+	es, err := c.GetAllEBlockEntries(context.Background(), ZeroHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 5 {
+		t.Fatalf("expected 5 entries, recieved %d", len(es))
+	}
+	for i, e := range es {
+		if string(e.Content) != fmt.Sprintf("%064x", i) {
+			t.Errorf("entry %d out of order: %s", i, e.Content)
+		}
+	}
+	// the entry block, one rejected batch, and each entry
+	if n := atomic.LoadInt32(&posts); n != 7 {
+		t.Errorf("expected 7 requests, recieved %d", n)
+	}
+
+	// later batches are not attempted
+	if _, err := c.GetAllEBlockEntries(context.Background(), ZeroHash); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&posts); n != 13 {
+		t.Errorf("expected 13 requests, recieved %d", n)
+	}
+}
+
+func TestSendFactomdBatchRequest(t *testing.T) {
+	var posts int32
+	ts := newBatchTestServer(0, true, &posts)
+	defer ts.Close()
+
+	SetFactomdServer(ts.URL[7:])
+
+	hash := hex.EncodeToString(make([]byte, 32))
+	params := map[string]string{"hash": hash}
+	resps, err := SendFactomdBatchRequest(
+		NewJSON2Request("entry", 1, params),
+		NewJSON2Request("bogus", 2, nil),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resps[0].Error != nil {
+		t.Error(resps[0].Error)
+	}
+	if resps[1].Error == nil || resps[1].Error.Code != -32601 {
+		t.Errorf("expected a method not found error, recieved %v", resps[1].Error)
+	}
+
+	_, err = SendFactomdBatchRequest(
+		NewJSON2Request("entry", 1, params),
+		NewJSON2Request("entry", 1, params),
+	)
+	if err == nil {
+		t.Error("expected an error for duplicate request ids")
+	}
+}
+
+func TestGetEntriesConcurrent(t *testing.T) {
+	var inflight, maxInflight, posts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for m := atomic.LoadInt32(&maxInflight); n > m; m = atomic.LoadInt32(&maxInflight) {
+			if atomic.CompareAndSwapInt32(&maxInflight, m, n) {
+				break
+			}
+		}
+		atomic.AddInt32(&posts, 1)
+		time.Sleep(20 * time.Millisecond)
+
+		var reqs []*JSON2Request
+		json.NewDecoder(r.Body).Decode(&reqs)
+		resps := make([]*JSON2Response, 0)
+		for _, req := range reqs {
+			params := new(struct {
+				Hash string `json:"hash"`
+			})
+			json.Unmarshal(req.Params, params)
+			resp := NewJSON2Response()
+			resp.ID = req.ID
+			if params.Hash == "bad" {
+				resp.Error = NewJSONError(-32008, "Entry not found", nil)
+			} else {
+				resp.Result, _ = json.Marshal(NewEntryFromStrings(ZeroHash, params.Hash))
+			}
+			resps = append(resps, resp)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resps)
+	}))
+	defer ts.Close()
+
+	hashes := make([]string, 0)
+	for i := 0; i < 100; i++ {
+		hashes = append(hashes, fmt.Sprint(i))
+	}
+
+	c := NewClient(&RPCConfig{
+		FactomdServer:      ts.URL[7:],
+		FactomdBatchSize:   10,
+		FactomdConcurrency: 3,
+	})
+	es, err := c.GetEntries(context.Background(), hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range es {
+		if string(e.Content) != hashes[i] {
+			t.Errorf("entry %d out of order: %s", i, e.Content)
+		}
+	}
+	if n := atomic.LoadInt32(&posts); n != 10 {
+		t.Errorf("expected 10 batches, recieved %d", n)
+	}
+	if n := atomic.LoadInt32(&maxInflight); n != 3 {
+		t.Errorf("expected 3 concurrent batches, recieved %d", n)
+	}
+
+	hashes[42] = "bad"
+	if _, err := c.GetEntries(context.Background(), hashes); err == nil {
+		t.Error("expected an entry not found error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetEntries(ctx, hashes); err != context.Canceled {
+		t.Errorf("expected %v, recieved %v", context.Canceled, err)
+	}
+}
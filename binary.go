@@ -0,0 +1,137 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrBinaryTooShort = errors.New("binary data too short")
+	ErrBinaryTooLong  = errors.New("binary data has extra bytes")
+	ErrVarIntTooLong  = errors.New("varint overflows 64 bits")
+)
+
+// writeVarInt writes x in the factomd variable length integer encoding: big
+// endian groups of 7 bits with the high bit set on every byte but the last.
+func writeVarInt(buf *bytes.Buffer, x uint64) {
+	p := make([]byte, 0, 10)
+	p = append(p, byte(x&0x7f))
+	for x >>= 7; x > 0; x >>= 7 {
+		p = append(p, byte(x&0x7f)|0x80)
+	}
+	for i := len(p) - 1; i >= 0; i-- {
+		buf.WriteByte(p[i])
+	}
+}
+
+// writeHex writes the n bytes encoded in the hex string s.
+func writeHex(buf *bytes.Buffer, s string, n int) error {
+	p, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(p) != n {
+		return fmt.Errorf("invalid %d byte value %s", n, s)
+	}
+	buf.Write(p)
+	return nil
+}
+
+// writeHash writes the 32 byte hash encoded in the hex string h.
+func writeHash(buf *bytes.Buffer, h string) error {
+	return writeHex(buf, h, 32)
+}
+
+// writeHashes writes each of the 32 byte hashes hs.
+func writeHashes(buf *bytes.Buffer, hs ...string) error {
+	for _, h := range hs {
+		if err := writeHash(buf, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// binaryReader reads the fields of a binary block. The first error is kept
+// and every later read returns zero values, so a series of reads may be
+// checked once at the end.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+// next returns the next n bytes.
+func (r *binaryReader) next(n int) []byte {
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	if n < 0 || len(r.data) < n {
+		r.err = ErrBinaryTooShort
+		return make([]byte, n)
+	}
+	p := r.data[:n]
+	r.data = r.data[n:]
+	return p
+}
+
+func (r *binaryReader) byte() byte {
+	return r.next(1)[0]
+}
+
+func (r *binaryReader) uint16() uint16 {
+	return binary.BigEndian.Uint16(r.next(2))
+}
+
+func (r *binaryReader) uint32() uint32 {
+	return binary.BigEndian.Uint32(r.next(4))
+}
+
+func (r *binaryReader) uint64() uint64 {
+	return binary.BigEndian.Uint64(r.next(8))
+}
+
+// milliTime reads a 6 byte millisecond timestamp.
+func (r *binaryReader) milliTime() int64 {
+	m := make([]byte, 8)
+	copy(m[2:], r.next(6))
+	return int64(binary.BigEndian.Uint64(m))
+}
+
+// hash reads a 32 byte hash as a hex string.
+func (r *binaryReader) hash() string {
+	return hex.EncodeToString(r.next(32))
+}
+
+// varInt reads an integer in the factomd variable length encoding.
+func (r *binaryReader) varInt() uint64 {
+	var x uint64
+	for {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		if x>>57 != 0 {
+			r.err = ErrVarIntTooLong
+			return 0
+		}
+		x = x<<7 | uint64(b&0x7f)
+		if b&0x80 == 0 {
+			return x
+		}
+	}
+}
+
+// done returns the first error, or ErrBinaryTooLong if any data is left.
+func (r *binaryReader) done() error {
+	if r.err == nil && len(r.data) > 0 {
+		return ErrBinaryTooLong
+	}
+	return r.err
+}
@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -57,9 +58,15 @@ func (f *BlockByHeightRawResponse) String() string {
 // GetBlockByHeightRaw fetches the specified block type by height
 // Deprecated: use ablock, dblock, eblock, ecblock and fblock instead.
 func GetBlockByHeightRaw(blockType string, height int64) (*BlockByHeightRawResponse, error) {
+	return DefaultClient.GetBlockByHeightRaw(context.Background(), blockType, height)
+}
+
+// GetBlockByHeightRaw fetches the specified block type by height
+// Deprecated: use ablock, dblock, eblock, ecblock and fblock instead.
+func (c *Client) GetBlockByHeightRaw(ctx context.Context, blockType string, height int64) (*BlockByHeightRawResponse, error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request(fmt.Sprintf("%vblock-by-height", blockType), APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
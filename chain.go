@@ -6,6 +6,7 @@ package factom
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -76,7 +77,13 @@ func ChainIDFromStrings(fields []string) string {
 // ChainExists returns true if a Chain with the given chainid exists within the
 // Factom Blockchain.
 func ChainExists(chainid string) bool {
-	if _, _, err := GetChainHead(chainid); err == nil {
+	return DefaultClient.ChainExists(context.Background(), chainid)
+}
+
+// ChainExists returns true if a Chain with the given chainid exists within the
+// Factom Blockchain.
+func (c *Client) ChainExists(ctx context.Context, chainid string) bool {
+	if _, _, err := c.GetChainHead(ctx, chainid); err == nil {
 		// no error means we found the Chain
 		return true
 	}
@@ -120,7 +127,7 @@ func ComposeChainCommit(c *Chain, ec *ECAddress) (*JSON2Request, error) {
 	if d, err := EntryCost(e); err != nil {
 		return nil, err
 	} else {
-		buf.WriteByte(byte(d + 10))
+		buf.WriteByte(byte(d + ChainCommitCost))
 	}
 
 	// 32 byte Entry Credit Address Public Key + 64 byte Signature
@@ -152,17 +159,25 @@ func ComposeChainReveal(c *Chain) (*JSON2Request, error) {
 // network is commited to publishing the Chain it may be published by revealing
 // the First Entry in the Chain.
 func CommitChain(c *Chain, ec *ECAddress) (string, error) {
+	return DefaultClient.CommitChain(context.Background(), c, ec)
+}
+
+// CommitChain sends the signed ChainID, the Entry Hash, and the Entry Credit
+// public key to the factom network. Once the payment is verified and the
+// network is commited to publishing the Chain it may be published by revealing
+// the First Entry in the Chain.
+func (c *Client) CommitChain(ctx context.Context, ch *Chain, ec *ECAddress) (string, error) {
 	type commitResponse struct {
 		Message string `json:"message"`
 		TxID    string `json:"txid"`
 	}
 
-	req, err := ComposeChainCommit(c, ec)
+	req, err := ComposeChainCommit(ch, ec)
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -180,17 +195,23 @@ func CommitChain(c *Chain, ec *ECAddress) (string, error) {
 // RevealChain sends the Chain data to the factom network to create a chain that
 // has previously been commited.
 func RevealChain(c *Chain) (string, error) {
+	return DefaultClient.RevealChain(context.Background(), c)
+}
+
+// RevealChain sends the Chain data to the factom network to create a chain that
+// has previously been commited.
+func (c *Client) RevealChain(ctx context.Context, ch *Chain) (string, error) {
 	type revealResponse struct {
 		Message string `json:"message"`
 		Entry   string `json:"entryhash"`
 	}
 
-	req, err := ComposeChainReveal(c)
+	req, err := ComposeChainReveal(ch)
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -208,9 +229,15 @@ func RevealChain(c *Chain) (string, error) {
 // GetChainHead returns the hash of the most recent Entry made into a given
 // Factom Chain.
 func GetChainHead(chainid string) (string, bool, error) {
+	return DefaultClient.GetChainHead(context.Background(), chainid)
+}
+
+// GetChainHead returns the hash of the most recent Entry made into a given
+// Factom Chain.
+func (c *Client) GetChainHead(ctx context.Context, chainid string) (string, bool, error) {
 	params := chainIDRequest{ChainID: chainid}
 	req := NewJSON2Request("chain-head", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return "", false, err
 	}
@@ -230,10 +257,17 @@ func GetChainHead(chainid string) (string, bool, error) {
 }
 
 // GetAllChainEntries returns a list of all Factom Entries for a given Chain.
+// Large Chains may be read one Entry Block at a time with a ChainIterator.
 func GetAllChainEntries(chainid string) ([]*Entry, error) {
+	return DefaultClient.GetAllChainEntries(context.Background(), chainid)
+}
+
+// GetAllChainEntries returns a list of all Factom Entries for a given Chain.
+// Large Chains may be read one Entry Block at a time with a ChainIterator.
+func (c *Client) GetAllChainEntries(ctx context.Context, chainid string) ([]*Entry, error) {
 	es := make([]*Entry, 0)
 
-	head, inPL, err := GetChainHead(chainid)
+	head, inPL, err := c.GetChainHead(ctx, chainid)
 	if err != nil {
 		return es, err
 	}
@@ -242,63 +276,124 @@ func GetAllChainEntries(chainid string) ([]*Entry, error) {
 		return nil, ErrChainPending
 	}
 
+	// the Entry Blocks from the Chain Head back
+	ebs := make([]*EBlock, 0)
+
 	for ebhash := head; ebhash != ZeroHash; {
-		eb, err := GetEBlock(ebhash)
-		if err != nil {
+		if err := ctx.Err(); err != nil {
 			return es, err
 		}
-		s, err := GetAllEBlockEntries(ebhash)
+		eb, err := c.GetEBlock(ctx, ebhash)
 		if err != nil {
 			return es, err
 		}
-		es = append(s, es...)
+		ebs = append(ebs, eb)
 
 		ebhash = eb.Header.PrevKeyMR
 	}
 
-	return es, nil
+	return c.GetEntries(ctx, chainEntryHashes(ebs))
 }
 
 // GetAllChainEntriesAtHeight returns a list of all Factom Entries for a given
 // Chain at a given point in the Chain's history.
 func GetAllChainEntriesAtHeight(chainid string, height int64) ([]*Entry, error) {
-	es := make([]*Entry, 0)
+	return DefaultClient.GetAllChainEntriesAtHeight(context.Background(), chainid, height)
+}
 
-	head, inPL, err := GetChainHead(chainid)
+// GetAllChainEntriesAtHeight returns a list of all Factom Entries for a given
+// Chain at a given point in the Chain's history.
+func (c *Client) GetAllChainEntriesAtHeight(ctx context.Context, chainid string, height int64) ([]*Entry, error) {
+	ebs, err := c.chainEBlocksAtHeight(ctx, chainid, height)
+	if err == ErrChainPending {
+		return nil, err
+	}
 	if err != nil {
-		return es, err
+		return make([]*Entry, 0), err
+	}
+
+	return c.GetEntries(ctx, chainEntryHashes(ebs))
+}
+
+// chainEntriesAtHeight returns the Entries of a Chain up to the given
+// Directory Block height in Chain order, and the height of the Entry Block of
+// each Entry.
+func (c *Client) chainEntriesAtHeight(ctx context.Context, chainid string, height int64) ([]*Entry, []int64, error) {
+	ebs, err := c.chainEBlocksAtHeight(ctx, chainid, height)
+	if err != nil {
+		return nil, nil, err
+	}
+	es, err := c.GetEntries(ctx, chainEntryHashes(ebs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	heights := make([]int64, 0, len(es))
+	for i := len(ebs) - 1; i >= 0; i-- {
+		for range ebs[i].EntryList {
+			heights = append(heights, ebs[i].Header.DBHeight)
+		}
+	}
+	return es, heights, nil
+}
+
+// chainEBlocksAtHeight returns the Entry Blocks of a Chain up to the given
+// Directory Block height, from the newest to the oldest.
+func (c *Client) chainEBlocksAtHeight(ctx context.Context, chainid string, height int64) ([]*EBlock, error) {
+	head, inPL, err := c.GetChainHead(ctx, chainid)
+	if err != nil {
+		return nil, err
 	}
 
 	if head == "" && inPL {
 		return nil, ErrChainPending
 	}
 
+	// the Entry Blocks from the Chain Head back
+	ebs := make([]*EBlock, 0)
+
 	for ebhash := head; ebhash != ZeroHash; {
-		eb, err := GetEBlock(ebhash)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		eb, err := c.GetEBlock(ctx, ebhash)
 		if err != nil {
-			return es, err
+			return nil, err
 		}
 		if eb.Header.DBHeight > height {
 			ebhash = eb.Header.PrevKeyMR
 			continue
 		}
-		s, err := GetAllEBlockEntries(ebhash)
-		if err != nil {
-			return es, err
-		}
-		es = append(s, es...)
+		ebs = append(ebs, eb)
 
 		ebhash = eb.Header.PrevKeyMR
 	}
 
-	return es, nil
+	return ebs, nil
+}
+
+// chainEntryHashes returns the Entry Hashes of Entry Blocks listed from the
+// newest to the oldest in Chain order.
+func chainEntryHashes(ebs []*EBlock) []string {
+	hashes := make([]string, 0)
+	for i := len(ebs) - 1; i >= 0; i-- {
+		for _, v := range ebs[i].EntryList {
+			hashes = append(hashes, v.EntryHash)
+		}
+	}
+	return hashes
 }
 
 // GetFirstEntry returns the first Entry used to create the given Factom Chain.
 func GetFirstEntry(chainid string) (*Entry, error) {
+	return DefaultClient.GetFirstEntry(context.Background(), chainid)
+}
+
+// GetFirstEntry returns the first Entry used to create the given Factom Chain.
+func (c *Client) GetFirstEntry(ctx context.Context, chainid string) (*Entry, error) {
 	e := new(Entry)
 
-	head, inPL, err := GetChainHead(chainid)
+	head, inPL, err := c.GetChainHead(ctx, chainid)
 	if err != nil {
 		return e, err
 	}
@@ -307,18 +402,21 @@ func GetFirstEntry(chainid string) (*Entry, error) {
 		return nil, ErrChainPending
 	}
 
-	eb, err := GetEBlock(head)
+	eb, err := c.GetEBlock(ctx, head)
 	if err != nil {
 		return e, err
 	}
 
 	for eb.Header.PrevKeyMR != ZeroHash {
+		if err := ctx.Err(); err != nil {
+			return e, err
+		}
 		ebhash := eb.Header.PrevKeyMR
-		eb, err = GetEBlock(ebhash)
+		eb, err = c.GetEBlock(ctx, ebhash)
 		if err != nil {
 			return e, err
 		}
 	}
 
-	return GetEntry(eb.EntryList[0].EntryHash)
+	return c.GetEntry(ctx, eb.EntryList[0].EntryHash)
 }
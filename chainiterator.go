@@ -0,0 +1,239 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChainCursor is the position of an Entry in a Chain: the KeyMR of its Entry
+// Block and its index in the Entry Block.
+type ChainCursor struct {
+	KeyMR string `json:"keymr"`
+	Index int    `json:"index"`
+}
+
+// ChainIterator iterates over the Entries of a Chain, requesting the Entries of
+// one Entry Block at a time from factomd so that only the Entries of the
+// current Entry Block are held in memory. Forward iteration also holds the
+// KeyMRs of the Entry Blocks listed walking back from the Chain Head.
+//
+// The options must be set before the first call to Next. By default the
+// iterator starts at the first Entry of the Chain and ends at the Chain Head
+// found by the first call to Next.
+//
+//	it := c.NewChainIterator(chainid)
+//	for it.Next(ctx) {
+//		e := it.Entry()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// Next returns false when it fails to request a block from factomd. Calling
+// Next again retries the failed request, so the iteration may be continued
+// after transient errors. A new ChainIterator may also continue where another
+// left off by setting Resume to its Cursor.
+type ChainIterator struct {
+	// Reverse iterates from the Chain Head back to the first Entry.
+	Reverse bool
+
+	// StartKeyMR is the KeyMR of the first Entry Block to iterate.
+	StartKeyMR string
+
+	// StartHeight is the Directory Block height to start at if StartKeyMR is
+	// not set. Forward iteration starts at the first Entry Block at or above
+	// StartHeight. Reverse iteration starts at the last Entry Block at or
+	// below StartHeight, or the Chain Head if StartHeight is 0.
+	StartHeight int64
+
+	// Resume continues the iteration after the Entry at the given cursor.
+	Resume *ChainCursor
+
+	c       *Client
+	chainid string
+
+	started bool
+	head    string
+	walk    string   // next Entry Block to list when starting forward
+	keymrs  []string // Entry Blocks still to iterate forward, oldest first
+	next    string   // next Entry Block to iterate in reverse
+	loaded  bool     // the first Entry Block has been loaded
+
+	keymr   string
+	entries []*Entry
+	index   int
+
+	err error
+}
+
+// NewChainIterator returns a ChainIterator over the Entries of a Chain.
+func NewChainIterator(chainid string) *ChainIterator {
+	return DefaultClient.NewChainIterator(chainid)
+}
+
+// NewChainIterator returns a ChainIterator over the Entries of a Chain.
+func (c *Client) NewChainIterator(chainid string) *ChainIterator {
+	it := new(ChainIterator)
+	it.c = c
+	it.chainid = chainid
+	return it
+}
+
+// Next advances the iterator to the next Entry. It returns false at the end of
+// the Chain or on an error, which is returned by Err.
+func (it *ChainIterator) Next(ctx context.Context) bool {
+	it.err = nil
+
+	if !it.started {
+		if err := it.start(ctx); err != nil {
+			it.err = err
+			return false
+		}
+		it.started = true
+	}
+
+	for {
+		if it.Reverse && it.index > 0 {
+			it.index--
+			return true
+		}
+		if !it.Reverse && it.index+1 < len(it.entries) {
+			it.index++
+			return true
+		}
+
+		var keymr string
+		if it.Reverse {
+			keymr = it.next
+		} else if len(it.keymrs) > 0 {
+			keymr = it.keymrs[0]
+		}
+		if keymr == "" || keymr == ZeroHash {
+			return false
+		}
+
+		if err := ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		eb, err := it.c.GetEBlock(ctx, keymr)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		// a reverse StartKeyMR or Resume cursor may name any block
+		if !it.loaded && eb.Header.ChainID != it.chainid {
+			it.err = fmt.Errorf("Entry Block %s not found in Chain %s", keymr, it.chainid)
+			return false
+		}
+
+		// skip the Entry Blocks above the starting height
+		if it.Reverse && !it.loaded && it.StartKeyMR == "" && it.Resume == nil &&
+			it.StartHeight > 0 && eb.Header.DBHeight > it.StartHeight {
+			it.next = eb.Header.PrevKeyMR
+			continue
+		}
+
+		es, err := it.c.getEBlockEntries(ctx, eb)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.keymr = keymr
+		it.entries = es
+		if it.Reverse {
+			it.next = eb.Header.PrevKeyMR
+			it.index = len(es)
+		} else {
+			it.keymrs = it.keymrs[1:]
+			it.index = -1
+		}
+		if !it.loaded && it.Resume != nil {
+			it.index = it.Resume.Index
+		}
+		it.loaded = true
+	}
+}
+
+// start finds the Chain Head and the Entry Blocks to iterate.
+func (it *ChainIterator) start(ctx context.Context) error {
+	if it.head == "" {
+		head, inPL, err := it.c.GetChainHead(ctx, it.chainid)
+		if err != nil {
+			return err
+		}
+		if head == "" && inPL {
+			return ErrChainPending
+		}
+		it.head = head
+		it.walk = head
+	}
+
+	stop := it.StartKeyMR
+	if it.Resume != nil {
+		stop = it.Resume.KeyMR
+	}
+
+	if it.Reverse {
+		it.next = it.head
+		if stop != "" {
+			it.next = stop
+		}
+		return nil
+	}
+
+	// Entry Blocks only link back to the previous block so the KeyMRs are
+	// listed walking back from the Chain Head.
+	for it.walk != "" && it.walk != ZeroHash {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		keymr := it.walk
+		eb, err := it.c.GetEBlock(ctx, keymr)
+		if err != nil {
+			return err
+		}
+		if stop == "" && eb.Header.DBHeight < it.StartHeight {
+			break
+		}
+		it.keymrs = append(it.keymrs, keymr)
+		it.walk = eb.Header.PrevKeyMR
+		if keymr == stop {
+			break
+		}
+	}
+
+	if stop != "" && (len(it.keymrs) == 0 || it.keymrs[len(it.keymrs)-1] != stop) {
+		return fmt.Errorf("Entry Block %s not found in Chain %s", stop, it.chainid)
+	}
+
+	for i, j := 0, len(it.keymrs)-1; i < j; i, j = i+1, j-1 {
+		it.keymrs[i], it.keymrs[j] = it.keymrs[j], it.keymrs[i]
+	}
+
+	return nil
+}
+
+// Entry returns the current Entry.
+func (it *ChainIterator) Entry() *Entry {
+	if it.index < 0 || it.index >= len(it.entries) {
+		return nil
+	}
+	return it.entries[it.index]
+}
+
+// Cursor returns the position of the current Entry.
+func (it *ChainIterator) Cursor() *ChainCursor {
+	return &ChainCursor{KeyMR: it.keymr, Index: it.index}
+}
+
+// Err returns the error that stopped the last call to Next, if any.
+func (it *ChainIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,180 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	. "github.com/FactomProject/factom"
+
+	"testing"
+)
+
+// testChainKeyMR is the KeyMR of the nth Entry Block of the test Chain.
+func testChainKeyMR(n int) string {
+	return fmt.Sprintf("%064x", n+1)
+}
+
+// newChainTestServer returns a factomd stand-in serving a Chain of n Entry
+// Blocks with two Entries each. The Entry Block n is at Directory Block height
+// 10*n and its Entries have the content "n-0" and "n-1". The blocks after n
+// belong to another Chain. The next *fails Entry Block requests fail with an
+// internal error, and *ebs counts the Entry Block requests.
+func newChainTestServer(n int, fails, ebs *int32) *httptest.Server {
+	handle := func(req *JSON2Request) *JSON2Response {
+		resp := NewJSON2Response()
+		resp.ID = req.ID
+		params := new(struct {
+			Hash  string `json:"hash"`
+			KeyMR string `json:"keymr"`
+		})
+		json.Unmarshal(req.Params, params)
+		switch req.Method {
+		case "chain-head":
+			resp.Result, _ = json.Marshal(map[string]string{"chainhead": testChainKeyMR(n - 1)})
+		case "entry-block":
+			atomic.AddInt32(ebs, 1)
+			if atomic.AddInt32(fails, -1) >= 0 {
+				resp.Error = NewJSONError(-32603, "Internal error", nil)
+				break
+			}
+			var b int
+			fmt.Sscanf(params.KeyMR, "%x", &b)
+			b--
+			eb := new(EBlock)
+			eb.Header.ChainID = ZeroHash
+			if b >= n {
+				eb.Header.ChainID = testChainKeyMR(b)
+			}
+			eb.Header.DBHeight = int64(10 * b)
+			eb.Header.PrevKeyMR = ZeroHash
+			if b > 0 {
+				eb.Header.PrevKeyMR = testChainKeyMR(b - 1)
+			}
+			for i := 0; i < 2; i++ {
+				eb.EntryList = append(eb.EntryList, EBEntry{EntryHash: fmt.Sprintf("%d-%d", b, i)})
+			}
+			resp.Result, _ = json.Marshal(eb)
+		case "entry":
+			resp.Result, _ = json.Marshal(NewEntryFromStrings(ZeroHash, params.Hash))
+		}
+		return resp
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := ioutil.ReadAll(r.Body)
+		if body[0] == '[' {
+			var reqs []*JSON2Request
+			json.Unmarshal(body, &reqs)
+			resps := make([]*JSON2Response, 0)
+			for _, req := range reqs {
+				resps = append(resps, handle(req))
+			}
+			json.NewEncoder(w).Encode(resps)
+			return
+		}
+		req := new(JSON2Request)
+		json.Unmarshal(body, req)
+		json.NewEncoder(w).Encode(handle(req))
+	}))
+}
+
+// iterate returns the content of every Entry from the iterator.
+func iterate(t *testing.T, it *ChainIterator) []string {
+	var s []string
+	for it.Next(context.Background()) {
+		s = append(s, string(it.Entry().Content))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestChainIterator(t *testing.T) {
+	var fails, ebs int32
+	ts := newChainTestServer(3, &fails, &ebs)
+	defer ts.Close()
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+
+	tests := []struct {
+		Name     string
+		Iterator *ChainIterator
+		Expected string
+	}{
+		{"forward", &ChainIterator{}, "[0-0 0-1 1-0 1-1 2-0 2-1]"},
+		{"reverse", &ChainIterator{Reverse: true}, "[2-1 2-0 1-1 1-0 0-1 0-0]"},
+		{"keymr", &ChainIterator{StartKeyMR: testChainKeyMR(1)}, "[1-0 1-1 2-0 2-1]"},
+		{"reverse keymr", &ChainIterator{Reverse: true, StartKeyMR: testChainKeyMR(1)}, "[1-1 1-0 0-1 0-0]"},
+		{"height", &ChainIterator{StartHeight: 15}, "[2-0 2-1]"},
+		{"reverse height", &ChainIterator{Reverse: true, StartHeight: 15}, "[1-1 1-0 0-1 0-0]"},
+		{"resume", &ChainIterator{Resume: &ChainCursor{KeyMR: testChainKeyMR(1), Index: 0}}, "[1-1 2-0 2-1]"},
+		{"reverse resume", &ChainIterator{Reverse: true, Resume: &ChainCursor{KeyMR: testChainKeyMR(1), Index: 0}}, "[0-1 0-0]"},
+	}
+	for _, test := range tests {
+		it := c.NewChainIterator(ZeroHash)
+		it.Reverse = test.Iterator.Reverse
+		it.StartKeyMR = test.Iterator.StartKeyMR
+		it.StartHeight = test.Iterator.StartHeight
+		it.Resume = test.Iterator.Resume
+		if s := fmt.Sprint(iterate(t, it)); s != test.Expected {
+			t.Errorf("%s: expected %s, recieved %s", test.Name, test.Expected, s)
+		}
+	}
+
+	// each Entry Block is requested once to list it walking back from the
+	// Chain Head and once more when its Entries are iterated
+	atomic.StoreInt32(&ebs, 0)
+	iterate(t, c.NewChainIterator(ZeroHash))
+	if n := atomic.LoadInt32(&ebs); n != 6 {
+		t.Errorf("expected 6 entry block requests, recieved %d", n)
+	}
+
+	for _, reverse := range []bool{false, true} {
+		it := c.NewChainIterator(ZeroHash)
+		it.Reverse = reverse
+		it.StartKeyMR = testChainKeyMR(5)
+		if it.Next(context.Background()) || it.Err() == nil {
+			t.Errorf("expected an error for an Entry Block of another Chain, recieved %v", it.Err())
+		}
+	}
+}
+
+func TestChainIteratorRetry(t *testing.T) {
+	var fails, ebs int32
+	ts := newChainTestServer(3, &fails, &ebs)
+	defer ts.Close()
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+
+	it := c.NewChainIterator(ZeroHash)
+	it.Reverse = true
+	var s []string
+	for it.Next(context.Background()) {
+		s = append(s, string(it.Entry().Content))
+		if len(s) == 3 {
+			atomic.StoreInt32(&fails, 1)
+		}
+	}
+	if it.Err() == nil {
+		t.Fatal("expected an error from the failed Entry Block request")
+	}
+	cursor := it.Cursor()
+	if cursor.KeyMR != testChainKeyMR(1) || cursor.Index != 0 {
+		t.Errorf("unexpected cursor %+v", cursor)
+	}
+
+	// continue after the error
+	s = append(s, iterate(t, it)...)
+	if e := "[2-1 2-0 1-1 1-0 0-1 0-0]"; fmt.Sprint(s) != e {
+		t.Errorf("expected %s, recieved %s", e, s)
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultChainWatcherInterval is the default time between polls of factomd by
+// a ChainWatcher.
+const DefaultChainWatcherInterval = 10 * time.Second
+
+// pendingExpiry is the number of Directory Blocks after which a delivered
+// pending Entry not seen in an Entry Block is forgotten.
+const pendingExpiry = 10
+
+// ChainEvent is a new Entry seen by a ChainWatcher.
+type ChainEvent struct {
+	ChainID string
+	Entry   *Entry
+
+	// KeyMR and EBlock are the Entry Block containing the Entry. They are
+	// empty for pending Entries.
+	KeyMR  string
+	EBlock *EBlock
+
+	// Pending is true for Entries that have been acknowledged but not yet
+	// included in an Entry Block. A pending Entry is delivered again once it
+	// is included in an Entry Block.
+	Pending bool
+}
+
+// ChainWatcher polls factomd for new Entries in a set of Chains and delivers
+// them in Chain order.
+//
+// The ChainWatcher tracks the KeyMR and height of the last Entry Block
+// delivered for each Chain, and only walks back from the Chain Head to that
+// height. When factomd is unreachable, or has restarted and is behind the last
+// seen Directory Block height, polling continues at the next interval from the
+// same position, so no Entries are skipped however long the gap.
+type ChainWatcher struct {
+	// Interval is the time between polls, or DefaultChainWatcherInterval if
+	// zero.
+	Interval time.Duration
+
+	// Pending delivers pending Entries as soon as factomd acknowledges them.
+	Pending bool
+
+	// OnError, if set, is called with errors polling factomd. The errors do
+	// not stop the ChainWatcher.
+	OnError func(error)
+
+	c *Client
+
+	mu      sync.Mutex
+	chains  map[string]string           // last delivered Entry Block KeyMR
+	heights map[string]int64            // height of the last delivered Entry Block, if known
+	pending map[string]map[string]int64 // height when pending Entry Hashes were delivered
+	height  int64                       // last seen Directory Block height
+}
+
+// NewChainWatcher returns a ChainWatcher for the given Chains, delivering only
+// Entries added after the first poll.
+func NewChainWatcher(chainids ...string) *ChainWatcher {
+	return DefaultClient.NewChainWatcher(chainids...)
+}
+
+// NewChainWatcher returns a ChainWatcher for the given Chains, delivering only
+// Entries added after the first poll.
+func (c *Client) NewChainWatcher(chainids ...string) *ChainWatcher {
+	w := new(ChainWatcher)
+	w.c = c
+	w.chains = make(map[string]string)
+	w.heights = make(map[string]int64)
+	w.pending = make(map[string]map[string]int64)
+	for _, chainid := range chainids {
+		w.AddChain(chainid, "")
+	}
+	return w
+}
+
+// AddChain adds a Chain to the ChainWatcher. Entries are delivered from the
+// Entry Block following the Entry Block with the given KeyMR. An empty KeyMR
+// delivers only Entries added after the next poll, and the ZeroHash delivers
+// every Entry from the start of the Chain.
+func (w *ChainWatcher) AddChain(chainid, keymr string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.chains[chainid] = keymr
+	delete(w.heights, chainid)
+	w.pending[chainid] = make(map[string]int64)
+}
+
+// RemoveChain stops watching a Chain.
+func (w *ChainWatcher) RemoveChain(chainid string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.chains, chainid)
+	delete(w.heights, chainid)
+	delete(w.pending, chainid)
+}
+
+// Position returns the KeyMR of the last Entry Block delivered for a Chain.
+// It may be passed to AddChain to resume watching the Chain later.
+func (w *ChainWatcher) Position(chainid string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.chains[chainid]
+}
+
+// Run polls factomd until the context is done or f returns an error, calling
+// f with each new Entry in Chain order. The position of a Chain only advances
+// past an Entry Block once f has returned nil for all of its Entries.
+func (w *ChainWatcher) Run(ctx context.Context, f func(*ChainEvent) error) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultChainWatcherInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := w.poll(ctx, f); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if herr, ok := err.(*handlerError); ok {
+				return herr.err
+			}
+			w.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Events runs the ChainWatcher in a new goroutine and returns a channel of
+// new Entries, which is closed when the context is done.
+func (w *ChainWatcher) Events(ctx context.Context) <-chan *ChainEvent {
+	events := make(chan *ChainEvent)
+	go func() {
+		defer close(events)
+		w.Run(ctx, func(ev *ChainEvent) error {
+			select {
+			case events <- ev:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return events
+}
+
+// onError calls OnError, if set, with an error polling factomd.
+func (w *ChainWatcher) onError(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+	}
+}
+
+// handlerError wraps an error returned by the ChainWatcher's callback.
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string {
+	return e.err.Error()
+}
+
+// poll delivers the new Entries of every Chain.
+func (w *ChainWatcher) poll(ctx context.Context, f func(*ChainEvent) error) error {
+	heights, err := w.c.GetHeights(ctx)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	behind := heights.DirectoryBlockHeight < w.height
+	if !behind {
+		w.height = heights.DirectoryBlockHeight
+		for _, seen := range w.pending {
+			for hash, h := range seen {
+				if w.height-h > pendingExpiry {
+					delete(seen, hash)
+				}
+			}
+		}
+	}
+	chains := make(map[string]string, len(w.chains))
+	for chainid, keymr := range w.chains {
+		chains[chainid] = keymr
+	}
+	w.mu.Unlock()
+
+	// factomd has restarted and is still syncing blocks already delivered
+	if behind {
+		return nil
+	}
+
+	// a Chain that fails is reported and does not hold up the others
+	for chainid, last := range chains {
+		if err := w.pollChain(ctx, chainid, last, f); err != nil {
+			if _, ok := err.(*handlerError); ok || ctx.Err() != nil {
+				return err
+			}
+			w.onError(err)
+		}
+	}
+
+	if w.Pending {
+		return w.pollPending(ctx, f)
+	}
+	return nil
+}
+
+// pollChain delivers the Entries of the Entry Blocks added to a Chain since
+// the Entry Block last.
+func (w *ChainWatcher) pollChain(ctx context.Context, chainid, last string, f func(*ChainEvent) error) error {
+	head, _, err := w.c.GetChainHead(ctx, chainid)
+	if jerr, ok := err.(*JSONError); ok && jerr.Is(ErrMissingChainHead) {
+		// the Chain does not exist yet so all of its Entries will be new
+		head, err = ZeroHash, nil
+	}
+	if err != nil {
+		return err
+	}
+	if head == "" {
+		head = ZeroHash
+	}
+
+	if last == "" {
+		w.setPosition(chainid, "", head, -1)
+		return nil
+	}
+	if head == last {
+		return nil
+	}
+
+	// the height of the last delivered Entry Block bounds the walk back
+	w.mu.Lock()
+	height, known := w.heights[chainid]
+	w.mu.Unlock()
+	if !known && last != ZeroHash {
+		eb, err := w.c.GetEBlock(ctx, last)
+		if err != nil {
+			return err
+		}
+		height, known = eb.Header.DBHeight, true
+		if !w.setPosition(chainid, last, last, height) {
+			return nil
+		}
+	}
+
+	// the Entry Blocks from the Chain Head back to the last delivered
+	ebs := make([]*EBlock, 0)
+	keymrs := make([]string, 0)
+	for keymr := head; keymr != last; {
+		if keymr == ZeroHash {
+			// factomd has not yet synced the last delivered Entry Block
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		eb, err := w.c.GetEBlock(ctx, keymr)
+		if err != nil {
+			return err
+		}
+		if known && last != ZeroHash && eb.Header.DBHeight <= height {
+			// the walk has passed the last delivered Entry Block, which
+			// factomd has not yet synced
+			return nil
+		}
+		ebs = append(ebs, eb)
+		keymrs = append(keymrs, keymr)
+		keymr = eb.Header.PrevKeyMR
+	}
+
+	for i := len(ebs) - 1; i >= 0; i-- {
+		es, err := w.c.getEBlockEntries(ctx, ebs[i])
+		if err != nil {
+			return err
+		}
+		for _, e := range es {
+			ev := &ChainEvent{ChainID: chainid, Entry: e, KeyMR: keymrs[i], EBlock: ebs[i]}
+			if err := f(ev); err != nil {
+				return &handlerError{err}
+			}
+		}
+		if !w.setPosition(chainid, last, keymrs[i], ebs[i].Header.DBHeight) {
+			return nil
+		}
+		w.confirmPending(chainid, ebs[i])
+		last = keymrs[i]
+	}
+
+	return nil
+}
+
+// setPosition advances the position of a Chain from the KeyMR last to keymr at
+// the given Directory Block height, which is negative if unknown. It returns
+// false if the Chain has been removed or moved by AddChain.
+func (w *ChainWatcher) setPosition(chainid, last, keymr string, height int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if pos, ok := w.chains[chainid]; !ok || pos != last {
+		return false
+	}
+	w.chains[chainid] = keymr
+	if height < 0 {
+		delete(w.heights, chainid)
+	} else {
+		w.heights[chainid] = height
+	}
+	return true
+}
+
+// confirmPending forgets the delivered pending Entries included in an Entry
+// Block.
+func (w *ChainWatcher) confirmPending(chainid string, eb *EBlock) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	seen := w.pending[chainid]
+	for _, v := range eb.EntryList {
+		delete(seen, v.EntryHash)
+	}
+}
+
+// pollPending delivers the pending Entries of the watched Chains that have
+// not been delivered before.
+func (w *ChainWatcher) pollPending(ctx context.Context, f func(*ChainEvent) error) error {
+	pending, err := w.c.ListPendingEntries(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	for _, pe := range pending {
+		w.mu.Lock()
+		seen, ok := w.pending[pe.ChainID]
+		_, delivered := seen[pe.EntryHash]
+		w.mu.Unlock()
+		if !ok || delivered {
+			continue
+		}
+
+		e, err := w.c.GetEntry(ctx, pe.EntryHash)
+		if err != nil {
+			return err
+		}
+		if err := f(&ChainEvent{ChainID: pe.ChainID, Entry: e, Pending: true}); err != nil {
+			return &handlerError{err}
+		}
+
+		w.mu.Lock()
+		seen[pe.EntryHash] = w.height
+		w.mu.Unlock()
+	}
+
+	return nil
+}
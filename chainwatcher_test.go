@@ -0,0 +1,339 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/FactomProject/factom"
+
+	"testing"
+)
+
+// watcherTestServer is a factomd stand-in for a single Chain whose Entry
+// Blocks and pending Entries may be changed while it is running. Entries are
+// served with their Entry Hash as their content.
+type watcherTestServer struct {
+	sync.Mutex
+	*httptest.Server
+	blocks  [][]string
+	pending []string
+	height  int64
+	down    bool
+	broken  map[string]bool // Chains whose Chain Head cannot be requested
+	ebs     int32           // Entry Block requests
+}
+
+func newWatcherTestServer() *watcherTestServer {
+	s := new(watcherTestServer)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+		if s.down {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := ioutil.ReadAll(r.Body)
+		if body[0] == '[' {
+			var reqs []*JSON2Request
+			json.Unmarshal(body, &reqs)
+			resps := make([]*JSON2Response, 0)
+			for _, req := range reqs {
+				resps = append(resps, s.handle(req))
+			}
+			json.NewEncoder(w).Encode(resps)
+			return
+		}
+		req := new(JSON2Request)
+		json.Unmarshal(body, req)
+		json.NewEncoder(w).Encode(s.handle(req))
+	}))
+	return s
+}
+
+func (s *watcherTestServer) handle(req *JSON2Request) *JSON2Response {
+	resp := NewJSON2Response()
+	resp.ID = req.ID
+	params := new(struct {
+		Hash    string `json:"hash"`
+		KeyMR   string `json:"keymr"`
+		ChainID string `json:"chainid"`
+	})
+	json.Unmarshal(req.Params, params)
+
+	var result interface{}
+	switch req.Method {
+	case "heights":
+		result = map[string]int64{"directoryblockheight": s.height}
+	case "chain-head":
+		if s.broken[params.ChainID] {
+			resp.Error = NewJSONError(-32603, "Internal error", nil)
+			return resp
+		}
+		if len(s.blocks) == 0 {
+			resp.Error = NewJSONError(-32009, "Missing Chain Head", nil)
+			return resp
+		}
+		result = map[string]string{"chainhead": testChainKeyMR(len(s.blocks) - 1)}
+	case "entry-block":
+		s.ebs++
+		var b int
+		fmt.Sscanf(params.KeyMR, "%x", &b)
+		b--
+		if b < 0 || b >= len(s.blocks) {
+			resp.Error = NewJSONError(-32008, "Block not found", nil)
+			return resp
+		}
+		eb := new(EBlock)
+		eb.Header.DBHeight = int64(b + 1)
+		eb.Header.PrevKeyMR = ZeroHash
+		if b > 0 {
+			eb.Header.PrevKeyMR = testChainKeyMR(b - 1)
+		}
+		for _, h := range s.blocks[b] {
+			eb.EntryList = append(eb.EntryList, EBEntry{EntryHash: h})
+		}
+		result = eb
+	case "entry":
+		result = NewEntryFromStrings(ZeroHash, params.Hash)
+	case "pending-entries":
+		list := make([]map[string]string, 0)
+		for _, h := range s.pending {
+			list = append(list, map[string]string{"entryhash": h, "chainid": ZeroHash})
+		}
+		result = list
+	}
+	resp.Result, _ = json.Marshal(result)
+	return resp
+}
+
+// addBlock adds an Entry Block with the given Entries to the Chain.
+func (s *watcherTestServer) addBlock(hashes ...string) {
+	s.Lock()
+	defer s.Unlock()
+	s.blocks = append(s.blocks, hashes)
+	s.pending = nil
+	s.height++
+}
+
+func TestChainWatcher(t *testing.T) {
+	ts := newWatcherTestServer()
+	defer ts.Close()
+	ts.addBlock("a", "b")
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+	w := c.NewChainWatcher(ZeroHash)
+	w.Interval = 5 * time.Millisecond
+	w.Pending = true
+	var errs int32
+	w.OnError = func(err error) { atomic.AddInt32(&errs, 1) }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := w.Events(ctx)
+
+	next := func() string {
+		select {
+		case ev := <-events:
+			s := string(ev.Entry.Content)
+			if ev.Pending {
+				s += "?"
+			}
+			return s
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for an event")
+		}
+		return ""
+	}
+
+	// wait for the first poll to set the starting position
+	for w.Position(ZeroHash) == "" {
+		time.Sleep(time.Millisecond)
+	}
+
+	ts.Lock()
+	ts.pending = []string{"c"}
+	ts.Unlock()
+	if s := next(); s != "c?" {
+		t.Errorf("expected pending entry c, recieved %s", s)
+	}
+
+	ts.addBlock("c")
+	ts.addBlock("d", "e")
+	var got []string
+	for i := 0; i < 3; i++ {
+		got = append(got, next())
+	}
+	if s := fmt.Sprint(got); s != "[c d e]" {
+		t.Errorf("expected [c d e], recieved %s", s)
+	}
+
+	// factomd goes down and comes back behind the last seen height
+	ts.Lock()
+	ts.down = true
+	ts.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	ts.Lock()
+	ts.down = false
+	ts.height = 1
+	ts.Unlock()
+	ts.addBlock("f")
+	ts.Lock()
+	ts.height = 10
+	ts.Unlock()
+	if s := next(); s != "f" {
+		t.Errorf("expected f, recieved %s", s)
+	}
+	if w.Position(ZeroHash) != testChainKeyMR(3) {
+		t.Errorf("unexpected position %s", w.Position(ZeroHash))
+	}
+
+	cancel()
+	if atomic.LoadInt32(&errs) == 0 {
+		t.Error("expected errors while factomd was down")
+	}
+}
+
+func TestChainWatcherFromStart(t *testing.T) {
+	ts := newWatcherTestServer()
+	defer ts.Close()
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+	w := c.NewChainWatcher()
+	w.AddChain(ZeroHash, ZeroHash)
+	w.Interval = 5 * time.Millisecond
+
+	ts.addBlock("a")
+	ts.addBlock("b", "c")
+
+	var got []string
+	stop := fmt.Errorf("stop")
+	err := w.Run(context.Background(), func(ev *ChainEvent) error {
+		got = append(got, string(ev.Entry.Content))
+		if len(got) == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("expected the handler error, recieved %v", err)
+	}
+	if s := fmt.Sprint(got); s != "[a b c]" {
+		t.Errorf("expected [a b c], recieved %s", s)
+	}
+	// the last Entry Block was not completely handled
+	if w.Position(ZeroHash) != testChainKeyMR(0) {
+		t.Errorf("unexpected position %s", w.Position(ZeroHash))
+	}
+}
+
+func TestChainWatcherNotSynced(t *testing.T) {
+	ts := newWatcherTestServer()
+	defer ts.Close()
+	for i := 0; i < 20; i++ {
+		ts.addBlock(fmt.Sprint(i))
+	}
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+	w := c.NewChainWatcher()
+	w.AddChain(ZeroHash, testChainKeyMR(17))
+	w.Interval = 5 * time.Millisecond
+	w.Pending = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := w.Events(ctx)
+	next := func() *ChainEvent {
+		select {
+		case ev := <-events:
+			return ev
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for an event")
+		}
+		return nil
+	}
+	if a, b := next(), next(); string(a.Entry.Content) != "18" || string(b.Entry.Content) != "19" {
+		t.Errorf("expected entries 18 and 19, recieved %v %v", a, b)
+	}
+
+	// factomd restarts from an older database without the last delivered
+	// Entry Block
+	ts.Lock()
+	ts.blocks = ts.blocks[:18]
+	ts.pending = []string{"x"}
+	ts.Unlock()
+	if ev := next(); !ev.Pending || string(ev.Entry.Content) != "x" {
+		t.Errorf("expected pending entry x, recieved %v", ev)
+	}
+
+	// the walk stops at the height of the last delivered Entry Block
+	ts.Lock()
+	ts.ebs = 0
+	ts.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	ts.Lock()
+	ebs := ts.ebs
+	ts.height += 11 // past the pending expiry
+	ts.Unlock()
+	if ebs > 20 {
+		t.Errorf("expected one entry block request per poll, recieved %d", ebs)
+	}
+	if w.Position(ZeroHash) != testChainKeyMR(19) {
+		t.Errorf("unexpected position %s", w.Position(ZeroHash))
+	}
+
+	// the pending entry has expired and is delivered again
+	if ev := next(); !ev.Pending || string(ev.Entry.Content) != "x" {
+		t.Errorf("expected pending entry x, recieved %v", ev)
+	}
+}
+
+func TestChainWatcherChainError(t *testing.T) {
+	ts := newWatcherTestServer()
+	defer ts.Close()
+	ts.broken = map[string]bool{"fe": true, "ff": true}
+	for i := 0; i < 20; i++ {
+		ts.addBlock(fmt.Sprint(i))
+	}
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+	w := c.NewChainWatcher()
+	w.AddChain("fe", ZeroHash)
+	w.AddChain("ff", ZeroHash)
+	w.AddChain(ZeroHash, ZeroHash)
+	w.Interval = time.Hour // poll once
+	var errs int32
+	w.OnError = func(err error) { atomic.AddInt32(&errs, 1) }
+
+	// the failing Chains do not stop the first poll of the other
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	events := w.Events(ctx)
+	for i := 0; i < 20; i++ {
+		select {
+		case ev := <-events:
+			if string(ev.Entry.Content) != fmt.Sprint(i) {
+				t.Errorf("expected entry %d, recieved %v", i, ev)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for entry %d", i)
+		}
+	}
+	for atomic.LoadInt32(&errs) < 2 && ctx.Err() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&errs); n != 2 {
+		t.Errorf("expected 2 errors, recieved %d", n)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+// Client is a handle for the factomd and factom-walletd APIs. Each Client
+// carries its own RPCConfig with the server endpoints, TLS settings,
+// credentials, and timeouts so that a single process may talk to several
+// factomd nodes or wallets at once.
+//
+// Every Client API method takes a context.Context which is carried through to
+// the HTTP requests made to factomd and factom-walletd so that calls may be
+// canceled or given deadlines. Methods that walk the blockchain with many
+// requests stop at the first request after the context is done.
+//
+// The package level API functions are thin wrappers around the DefaultClient
+// using context.Background().
+type Client struct {
+	// Config is the API configuration used by the Client. A nil Config uses
+	// the package level RpcConfig.
+	Config *RPCConfig
+
+	// nodes is the health of the factomd servers in Config.FactomdServers.
+	nodes nodeState
+
+	// batches remembers the factomd servers that do not support batches.
+	batches batchState
+}
+
+// DefaultClient is the Client used by the package level API functions. It
+// follows the package level RpcConfig, so the SetFactomdServer,
+// SetWalletServer, and related functions continue to configure it.
+var DefaultClient = new(Client)
+
+// NewClient creates a new Client using the given RPCConfig.
+func NewClient(config *RPCConfig) *Client {
+	c := new(Client)
+	c.Config = config
+	return c
+}
+
+// config returns the API configuration used by the Client.
+func (c *Client) config() *RPCConfig {
+	if c.Config == nil {
+		return RpcConfig
+	}
+	return c.Config
+}
@@ -0,0 +1,110 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/FactomProject/factom"
+
+	"testing"
+)
+
+func TestClientsUseSeparateConfigs(t *testing.T) {
+	newRateServer := func(rate int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, _ := r.BasicAuth()
+			if user != fmt.Sprint("user", rate) || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":0,"result":{"rate":%d}}`, rate)
+		}))
+	}
+	ts1 := newRateServer(1000)
+	defer ts1.Close()
+	ts2 := newRateServer(2000)
+	defer ts2.Close()
+
+	c1 := NewClient(&RPCConfig{
+		FactomdServer:      ts1.URL[7:],
+		FactomdRPCUser:     "user1000",
+		FactomdRPCPassword: "pass",
+	})
+	c2 := NewClient(&RPCConfig{
+		FactomdServer:      ts2.URL[7:],
+		FactomdRPCUser:     "user2000",
+		FactomdRPCPassword: "pass",
+	})
+
+	if rate, err := c1.GetECRate(context.Background()); err != nil {
+		t.Error(err)
+	} else if rate != 1000 {
+		t.Errorf("expected:%d\nrecieved:%d", 1000, rate)
+	}
+	if rate, err := c2.GetECRate(context.Background()); err != nil {
+		t.Error(err)
+	} else if rate != 2000 {
+		t.Errorf("expected:%d\nrecieved:%d", 2000, rate)
+	}
+
+	// the default client should not pick up either of the client configs
+	SetFactomdServer(ts1.URL[7:])
+	SetFactomdRpcConfig("", "")
+	if _, err := GetECRate(); err == nil {
+		t.Error("expected an authorization error from the default client")
+	}
+}
+
+func TestDefaultClientFollowsRpcConfig(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"jsonrpc":"2.0","id":0,"result":{"rate":95369}}`)
+	}))
+	defer ts.Close()
+
+	SetFactomdServer(ts.URL[7:])
+
+	rate, err := DefaultClient.GetECRate(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+	if rate != 95369 {
+		t.Errorf("expected:%d\nrecieved:%d", 95369, rate)
+	}
+}
+
+func TestClientContextCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetHeights(ctx); err == nil {
+		t.Error("expected the request to be canceled")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Error("expected the context deadline to be exceeded")
+	}
+
+	// a walker given a canceled context should stop without walking the chain
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetAllChainEntries(ctx, ZeroHash); err == nil {
+		t.Error("expected GetAllChainEntries to return an error")
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+
+	ed "github.com/FactomProject/ed25519"
+)
+
+// Commit message errors
+var (
+	ErrCommitVersion   = errors.New("unsupported commit version")
+	ErrCommitSignature = errors.New("invalid commit signature")
+	ErrCommitMismatch  = errors.New("commit does not match the entry")
+	ErrCommitCredits   = errors.New("commit does not pay enough entry credits")
+)
+
+// ChainCommitCost is the number of Entry Credits paid to create a new Chain,
+// in addition to the cost of its First Entry.
+const ChainCommitCost = 10
+
+// commitSigSize is the size of the EC public key and signature at the end of
+// a commit message.
+const commitSigSize = ed.PublicKeySize + ed.SignatureSize
+
+// DecodeEntryCommit decodes the hex encoded commit-entry message made by
+// ComposeEntryCommit. The signature is not checked; see VerifySignature.
+func DecodeEntryCommit(msg string) (*ECEntryCommit, error) {
+	p, err := hex.DecodeString(msg)
+	if err != nil {
+		return nil, err
+	}
+	c := new(ECEntryCommit)
+	if err := c.UnmarshalBinary(p); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DecodeChainCommit decodes the hex encoded commit-chain message made by
+// ComposeChainCommit. The signature is not checked; see VerifySignature.
+func DecodeChainCommit(msg string) (*ECChainCommit, error) {
+	p, err := hex.DecodeString(msg)
+	if err != nil {
+		return nil, err
+	}
+	c := new(ECChainCommit)
+	if err := c.UnmarshalBinary(p); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// MarshalBinary returns the binary commit-entry message.
+func (e *ECEntryCommit) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := e.writeBinary(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a binary commit-entry message.
+func (e *ECEntryCommit) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+	e.readBinary(r)
+	if err := r.done(); err != nil {
+		return err
+	}
+	if e.Version != 0 {
+		return ErrCommitVersion
+	}
+	return nil
+}
+
+func (e *ECEntryCommit) writeBinary(buf *bytes.Buffer) error {
+	buf.WriteByte(byte(e.Version))
+	buf.Write(milliTimeBytes(e.MilliTime))
+	if err := writeHash(buf, e.EntryHash); err != nil {
+		return err
+	}
+	buf.WriteByte(byte(e.Credits))
+	if err := writeHash(buf, e.ECPubKey); err != nil {
+		return err
+	}
+	return writeHex(buf, e.Sig, ed.SignatureSize)
+}
+
+func (e *ECEntryCommit) readBinary(r *binaryReader) {
+	e.Version = int(r.byte())
+	e.MilliTime = r.milliTime()
+	e.EntryHash = r.hash()
+	e.Credits = int(r.byte())
+	e.ECPubKey = r.hash()
+	e.Sig = hex.EncodeToString(r.next(ed.SignatureSize))
+}
+
+// VerifySignature checks that the commit is signed by its EC public key.
+func (e *ECEntryCommit) VerifySignature() error {
+	p, err := e.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return verifyCommitSig(p)
+}
+
+// ECAddress returns the public Entry Credit Address paying for the commit.
+func (e *ECEntryCommit) ECAddress() (string, error) {
+	return commitECAddress(e.ECPubKey)
+}
+
+// VerifyEntry checks that the commit is for the Entry and pays at least its
+// cost in Entry Credits.
+func (e *ECEntryCommit) VerifyEntry(entry *Entry) error {
+	if e.EntryHash != hex.EncodeToString(entry.Hash()) {
+		return ErrCommitMismatch
+	}
+	cost, err := EntryCost(entry)
+	if err != nil {
+		return err
+	}
+	if e.Credits < int(cost) {
+		return ErrCommitCredits
+	}
+	return nil
+}
+
+// MarshalBinary returns the binary commit-chain message.
+func (c *ECChainCommit) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := c.writeBinary(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a binary commit-chain message.
+func (c *ECChainCommit) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+	c.readBinary(r)
+	if err := r.done(); err != nil {
+		return err
+	}
+	if c.Version != 0 {
+		return ErrCommitVersion
+	}
+	return nil
+}
+
+func (c *ECChainCommit) writeBinary(buf *bytes.Buffer) error {
+	buf.WriteByte(byte(c.Version))
+	buf.Write(milliTimeBytes(c.MilliTime))
+	if err := writeHashes(buf, c.ChainIDHash, c.Weld, c.EntryHash); err != nil {
+		return err
+	}
+	buf.WriteByte(byte(c.Credits))
+	if err := writeHash(buf, c.ECPubKey); err != nil {
+		return err
+	}
+	return writeHex(buf, c.Sig, ed.SignatureSize)
+}
+
+func (c *ECChainCommit) readBinary(r *binaryReader) {
+	c.Version = int(r.byte())
+	c.MilliTime = r.milliTime()
+	c.ChainIDHash = r.hash()
+	c.Weld = r.hash()
+	c.EntryHash = r.hash()
+	c.Credits = int(r.byte())
+	c.ECPubKey = r.hash()
+	c.Sig = hex.EncodeToString(r.next(ed.SignatureSize))
+}
+
+// VerifySignature checks that the commit is signed by its EC public key.
+func (c *ECChainCommit) VerifySignature() error {
+	p, err := c.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return verifyCommitSig(p)
+}
+
+// ECAddress returns the public Entry Credit Address paying for the commit.
+func (c *ECChainCommit) ECAddress() (string, error) {
+	return commitECAddress(c.ECPubKey)
+}
+
+// VerifyChain checks that the commit is for the Chain and its First Entry,
+// with a matching ChainID Hash and Weld, and pays at least the cost of the
+// First Entry and ChainCommitCost in Entry Credits.
+func (c *ECChainCommit) VerifyChain(ch *Chain) error {
+	cid, err := hex.DecodeString(ch.ChainID)
+	if err != nil {
+		return err
+	}
+	ehash := ch.FirstEntry.Hash()
+	if c.ChainIDHash != hex.EncodeToString(shad(cid)) ||
+		c.Weld != hex.EncodeToString(shad(append(ehash, cid...))) ||
+		c.EntryHash != hex.EncodeToString(ehash) {
+		return ErrCommitMismatch
+	}
+	cost, err := EntryCost(ch.FirstEntry)
+	if err != nil {
+		return err
+	}
+	if c.Credits < int(cost)+ChainCommitCost {
+		return ErrCommitCredits
+	}
+	return nil
+}
+
+// verifyCommitSig checks the signature at the end of a binary commit message
+// of the data before the EC public key.
+func verifyCommitSig(p []byte) error {
+	n := len(p) - commitSigSize
+	pub := new([ed.PublicKeySize]byte)
+	copy(pub[:], p[n:])
+	sig := new([ed.SignatureSize]byte)
+	copy(sig[:], p[n+ed.PublicKeySize:])
+	if !ed.VerifyCanonical(pub, p[:n], sig) {
+		return ErrCommitSignature
+	}
+	return nil
+}
+
+func commitECAddress(pub string) (string, error) {
+	p, err := hex.DecodeString(pub)
+	if err != nil {
+		return "", err
+	}
+	if len(p) != ed.PublicKeySize {
+		return "", ErrInvalidAddress
+	}
+	return encodePubAddress(ecPubPrefix, p), nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestDecodeCommits(t *testing.T) {
+	ecAddr, err := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitMessage := func(req *JSON2Request) string {
+		r := new(struct {
+			Message string `json:"message"`
+		})
+		if err := json.Unmarshal(req.Params, r); err != nil {
+			t.Fatal(err)
+		}
+		return r.Message
+	}
+
+	ent := new(Entry)
+	ent.ChainID = "954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4"
+	ent.Content = []byte("test!")
+	ent.ExtIDs = append(ent.ExtIDs, []byte("test"))
+
+	// Entry commit
+	req, err := ComposeEntryCommit(ent, ecAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := commitMessage(req)
+	ec, err := DecodeEntryCommit(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ec.EntryHash != hex.EncodeToString(ent.Hash()) || ec.Credits != 1 ||
+		ec.ECPubKey != hex.EncodeToString(ecAddr.PubBytes()) {
+		t.Errorf("unexpected commit %s", ec)
+	}
+	if a, err := ec.ECAddress(); err != nil || a != ecAddr.PubString() {
+		t.Errorf("expected address %s, recieved %s %v", ecAddr.PubString(), a, err)
+	}
+	if err := ec.VerifySignature(); err != nil {
+		t.Error(err)
+	}
+	if err := ec.VerifyEntry(ent); err != nil {
+		t.Error(err)
+	}
+	if p, err := ec.MarshalBinary(); err != nil || hex.EncodeToString(p) != msg {
+		t.Errorf("expected:%s\nrecieved:%x %v", msg, p, err)
+	}
+
+	ec.Credits = 0
+	if err := ec.VerifyEntry(ent); err != ErrCommitCredits {
+		t.Errorf("expected %v, recieved %v", ErrCommitCredits, err)
+	}
+	if err := ec.VerifySignature(); err != ErrCommitSignature {
+		t.Errorf("expected %v, recieved %v", ErrCommitSignature, err)
+	}
+	if _, err := DecodeEntryCommit(msg[2:]); err != ErrBinaryTooShort {
+		t.Errorf("expected %v, recieved %v", ErrBinaryTooShort, err)
+	}
+	if _, err := DecodeEntryCommit(msg + "00"); err != ErrBinaryTooLong {
+		t.Errorf("expected %v, recieved %v", ErrBinaryTooLong, err)
+	}
+
+	// Chain commit
+	ch := NewChain(ent)
+	req, err = ComposeChainCommit(ch, ecAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg = commitMessage(req)
+	cc, err := DecodeChainCommit(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc.Credits != 11 {
+		t.Errorf("expected 11 credits, recieved %d", cc.Credits)
+	}
+	if err := cc.VerifySignature(); err != nil {
+		t.Error(err)
+	}
+	if err := cc.VerifyChain(ch); err != nil {
+		t.Error(err)
+	}
+	if err := cc.VerifyChain(NewChainFromStrings("other", "chain")); err != ErrCommitMismatch {
+		t.Errorf("expected %v, recieved %v", ErrCommitMismatch, err)
+	}
+	if _, err := DecodeChainCommit(msg[:len(msg)-2]); err != ErrBinaryTooShort {
+		t.Errorf("expected %v, recieved %v", ErrBinaryTooShort, err)
+	}
+}
@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -43,8 +44,13 @@ func (c *CurrentMinuteInfo) String() string {
 
 // GetCurrentMinute gets the current network information from the factom daemon.
 func GetCurrentMinute() (*CurrentMinuteInfo, error) {
+	return DefaultClient.GetCurrentMinute(context.Background())
+}
+
+// GetCurrentMinute gets the current network information from the factom daemon.
+func (c *Client) GetCurrentMinute(ctx context.Context) (*CurrentMinuteInfo, error) {
 	req := NewJSON2Request("current-minute", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -52,10 +58,10 @@ func GetCurrentMinute() (*CurrentMinuteInfo, error) {
 		return nil, resp.Error
 	}
 
-	c := new(CurrentMinuteInfo)
-	if err := json.Unmarshal(resp.JSONResult(), c); err != nil {
+	info := new(CurrentMinuteInfo)
+	if err := json.Unmarshal(resp.JSONResult(), info); err != nil {
 		return nil, err
 	}
 
-	return c, nil
+	return info, nil
 }
@@ -5,6 +5,10 @@
 package factom
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -61,6 +65,176 @@ func (db *DBlock) String() string {
 	return s
 }
 
+// headerBinary returns the binary Directory Block header.
+func (db *DBlock) headerBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	buf.WriteByte(byte(db.Header.Version))
+	binary.Write(buf, binary.BigEndian, uint32(db.Header.NetworkID))
+	for _, h := range []string{db.Header.BodyMR, db.Header.PrevKeyMR, db.Header.PrevFullHash} {
+		p, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, err
+		}
+		if len(p) != 32 {
+			return nil, fmt.Errorf("invalid directory block header hash %s", h)
+		}
+		buf.Write(p)
+	}
+	binary.Write(buf, binary.BigEndian, uint32(db.Header.Timestamp))
+	binary.Write(buf, binary.BigEndian, uint32(db.Header.DBHeight))
+	binary.Write(buf, binary.BigEndian, uint32(db.Header.BlockCount))
+
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary returns the binary Directory Block as stored by factomd.
+func (db *DBlock) MarshalBinary() ([]byte, error) {
+	header, err := db.headerBinary()
+	if err != nil {
+		return nil, err
+	}
+	if len(db.DBEntries) != db.Header.BlockCount {
+		return nil, fmt.Errorf("directory block has %d entries not %d", len(db.DBEntries), db.Header.BlockCount)
+	}
+
+	buf := bytes.NewBuffer(header)
+	for _, v := range db.DBEntries {
+		if err := writeHashes(buf, v.ChainID, v.KeyMR); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a binary Directory Block, such as the raw data
+// returned by GetDBlock, and computes its KeyMR, HeaderHash and DBHash.
+func (db *DBlock) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+
+	db.Header.Version = int(r.byte())
+	db.Header.NetworkID = int(r.uint32())
+	db.Header.BodyMR = r.hash()
+	db.Header.PrevKeyMR = r.hash()
+	db.Header.PrevFullHash = r.hash()
+	db.Header.Timestamp = int(r.uint32())
+	db.Header.DBHeight = int(r.uint32())
+	db.Header.BlockCount = int(r.uint32())
+	if r.err != nil {
+		return r.err
+	}
+
+	if len(r.data) != 64*db.Header.BlockCount {
+		return fmt.Errorf("directory block has %d bytes for %d entries", len(r.data), db.Header.BlockCount)
+	}
+	db.DBEntries = db.DBEntries[:0]
+	for i := 0; i < db.Header.BlockCount; i++ {
+		db.DBEntries = append(db.DBEntries, struct {
+			ChainID string `json:"chainid"`
+			KeyMR   string `json:"keymr"`
+		}{r.hash(), r.hash()})
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	header := data[:len(data)-len(db.DBEntries)*64]
+	bodyMR, _ := hex.DecodeString(db.Header.BodyMR)
+	h := sha256.Sum256(header)
+	db.HeaderHash = hex.EncodeToString(h[:])
+	db.KeyMR = hex.EncodeToString(computeKeyMR(header, bodyMR))
+	h = sha256.Sum256(data)
+	db.DBHash = hex.EncodeToString(h[:])
+
+	return nil
+}
+
+// ComputeBodyMR returns the Merkle root of the Directory Block body. Each
+// leaf is the hash of a DBEntry's ChainID and KeyMR.
+func (db *DBlock) ComputeBodyMR() (string, error) {
+	hashes := make([][]byte, 0, len(db.DBEntries))
+	for _, v := range db.DBEntries {
+		c, err := hex.DecodeString(v.ChainID)
+		if err != nil {
+			return "", err
+		}
+		k, err := hex.DecodeString(v.KeyMR)
+		if err != nil {
+			return "", err
+		}
+		if len(c) != 32 || len(k) != 32 {
+			return "", fmt.Errorf("invalid directory block entry %s %s", v.ChainID, v.KeyMR)
+		}
+		h := sha256.Sum256(append(c, k...))
+		hashes = append(hashes, h[:])
+	}
+	return hex.EncodeToString(computeMerkleRoot(hashes)), nil
+}
+
+// ComputeHeaderHash returns the hash of the Directory Block header.
+func (db *DBlock) ComputeHeaderHash() (string, error) {
+	header, err := db.headerBinary()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(header)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// ComputeKeyMR returns the Key Merkle Root of the Directory Block computed
+// from its header and DBEntries.
+func (db *DBlock) ComputeKeyMR() (string, error) {
+	header, err := db.headerBinary()
+	if err != nil {
+		return "", err
+	}
+	bodyMR, err := db.ComputeBodyMR()
+	if err != nil {
+		return "", err
+	}
+	if bodyMR != db.Header.BodyMR {
+		return "", fmt.Errorf("directory block body hashes to %s not %s", bodyMR, db.Header.BodyMR)
+	}
+	b, _ := hex.DecodeString(bodyMR)
+	return hex.EncodeToString(computeKeyMR(header, b)), nil
+}
+
+// Verify checks that the Directory Block hashes to the Key Merkle Root keymr.
+// If raw is not nil it must be the binary Directory Block, hashing to the
+// DBHash, with the same header and DBEntries.
+func (db *DBlock) Verify(keymr string, raw []byte) error {
+	if len(db.DBEntries) != db.Header.BlockCount {
+		return fmt.Errorf("directory block has %d entries not %d", len(db.DBEntries), db.Header.BlockCount)
+	}
+	k, err := db.ComputeKeyMR()
+	if err != nil {
+		return err
+	}
+	if k != keymr {
+		return fmt.Errorf("directory block keymr is %s not %s", k, keymr)
+	}
+	if db.KeyMR != "" && db.KeyMR != keymr {
+		return fmt.Errorf("directory block keymr field %s does not match %s", db.KeyMR, keymr)
+	}
+
+	if raw == nil {
+		return nil
+	}
+	p, err := db.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(raw, p) {
+		return fmt.Errorf("directory block does not match its raw data")
+	}
+	if h := sha256.Sum256(raw); db.DBHash != "" && hex.EncodeToString(h[:]) != db.DBHash {
+		return fmt.Errorf("directory block raw data hashes to %x not %s", h, db.DBHash)
+	}
+
+	return nil
+}
+
 // TODO: GetDBlock should use the dblock api call directy instead of
 // re-directing to dblock-by-height.
 // we either need to change the "directoy-block" API call or add a new call to
@@ -69,9 +243,15 @@ func (db *DBlock) String() string {
 // GetDBlock requests a Directory Block by its Key Merkle Root from the factomd
 // API.
 func GetDBlock(keymr string) (dblock *DBlock, raw []byte, err error) {
+	return DefaultClient.GetDBlock(context.Background(), keymr)
+}
+
+// GetDBlock requests a Directory Block by its Key Merkle Root from the factomd
+// API.
+func (c *Client) GetDBlock(ctx context.Context, keymr string) (dblock *DBlock, raw []byte, err error) {
 	params := keyMRRequest{KeyMR: keymr}
 	req := NewJSON2Request("directory-block", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
@@ -99,15 +279,30 @@ func GetDBlock(keymr string) (dblock *DBlock, raw []byte, err error) {
 
 	// TODO: we need a better api call for dblock by keymr so that API will
 	// retrun the same as dblock-byheight
-	return GetDBlockByHeight(db.Header.SequenceNumber)
+	dblock, raw, err = c.GetDBlockByHeight(ctx, db.Header.SequenceNumber)
+	if err != nil {
+		return
+	}
+
+	if c.config().FactomdVerifyBlocks && dblock.KeyMR != keymr {
+		return nil, nil, fmt.Errorf("directory block keymr is %s not %s", dblock.KeyMR, keymr)
+	}
+
+	return dblock, raw, nil
 }
 
 // GetDBlockByHeight requests a Directory Block by its block height from the factomd
 // API.
 func GetDBlockByHeight(height int64) (dblock *DBlock, raw []byte, err error) {
+	return DefaultClient.GetDBlockByHeight(context.Background(), height)
+}
+
+// GetDBlockByHeight requests a Directory Block by its block height from the factomd
+// API.
+func (c *Client) GetDBlockByHeight(ctx context.Context, height int64) (dblock *DBlock, raw []byte, err error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request("dblock-by-height", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
@@ -130,6 +325,15 @@ func GetDBlockByHeight(height int64) (dblock *DBlock, raw []byte, err error) {
 		return
 	}
 
+	if c.config().FactomdVerifyBlocks {
+		if err := wrap.DBlock.Verify(wrap.DBlock.KeyMR, raw); err != nil {
+			return nil, nil, err
+		}
+		if int64(wrap.DBlock.Header.DBHeight) != height {
+			return nil, nil, fmt.Errorf("directory block height is %d not %d", wrap.DBlock.Header.DBHeight, height)
+		}
+	}
+
 	wrap.DBlock.SequenceNumber = height
 	return wrap.DBlock, raw, nil
 }
@@ -137,8 +341,14 @@ func GetDBlockByHeight(height int64) (dblock *DBlock, raw []byte, err error) {
 // GetDBlockHead requests the most recent Directory Block Key Merkel Root
 // created by the Factom Network.
 func GetDBlockHead() (string, error) {
+	return DefaultClient.GetDBlockHead(context.Background())
+}
+
+// GetDBlockHead requests the most recent Directory Block Key Merkel Root
+// created by the Factom Network.
+func (c *Client) GetDBlockHead(ctx context.Context) (string, error) {
 	req := NewJSON2Request("directory-block-head", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -158,9 +368,14 @@ func GetDBlockHead() (string, error) {
 
 // ReplayDBlockFromHeight requests DBlock states to be emitted over the LiveFeed API
 func ReplayDBlockFromHeight(startheight int64, endheight int64) (*replayResponse, error) {
+	return DefaultClient.ReplayDBlockFromHeight(context.Background(), startheight, endheight)
+}
+
+// ReplayDBlockFromHeight requests DBlock states to be emitted over the LiveFeed API
+func (c *Client) ReplayDBlockFromHeight(ctx context.Context, startheight int64, endheight int64) (*replayResponse, error) {
 	params := replayRequest{StartHeight: startheight, EndHeight: endheight}
 	req := NewJSON2Request("replay-from-height", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 
 	if err != nil {
 		return nil, err
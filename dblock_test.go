@@ -5,6 +5,8 @@
 package factom_test
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -67,6 +69,37 @@ func TestGetDBlockByHeight(t *testing.T) {
 	}
 	t.Log("dblock:", d)
 	t.Log(fmt.Sprintf("raw: %x\n", raw))
+	if bodyMR, err := d.ComputeBodyMR(); err != nil || bodyMR != d.Header.BodyMR {
+		t.Errorf("expected body mr %s, recieved %s %v", d.Header.BodyMR, bodyMR, err)
+	}
+	if err := d.Verify("cde346e7ed87957edfd68c432c984f35596f29c7d23de6f279351cddecd5dc66", raw); err != nil {
+		t.Error(err)
+	}
+
+	d2 := new(DBlock)
+	if err := d2.UnmarshalBinary(raw); err != nil {
+		t.Fatal(err)
+	}
+	if d2.KeyMR != d.KeyMR || d2.DBHash != d.DBHash || d2.Header != d.Header ||
+		fmt.Sprint(d2.DBEntries) != fmt.Sprint(d.DBEntries) {
+		t.Errorf("expected:%s\nrecieved:%s", d, d2)
+	}
+	if p, err := d2.MarshalBinary(); err != nil || !bytes.Equal(p, raw) {
+		t.Errorf("expected:%x\nrecieved:%x %v", raw, p, err)
+	}
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:], FactomdVerifyBlocks: true})
+	if _, _, err := c.GetDBlockByHeight(context.Background(), 100); err != nil {
+		t.Error(err)
+	}
+	if _, _, err := c.GetDBlockByHeight(context.Background(), 101); err == nil {
+		t.Error("expected an error for a directory block at the wrong height")
+	}
+
+	d.DBEntries[3].KeyMR = ZeroHash
+	if err := d.Verify("cde346e7ed87957edfd68c432c984f35596f29c7d23de6f279351cddecd5dc66", nil); err == nil {
+		t.Error("expected tampered directory block to fail verification")
+	}
 }
 
 func TestGetDBlockHead(t *testing.T) {
@@ -0,0 +1,188 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	ed "github.com/FactomProject/ed25519"
+)
+
+// DBSignatures reports which Federated Servers signed a Directory Block.
+type DBSignatures struct {
+	// Height is the height of the signed Directory Block.
+	Height int64
+
+	// Signed and Unsigned are the identity Chain IDs of the Federated
+	// Servers with and without a valid signature of the Directory Block.
+	Signed   []string
+	Unsigned []string
+
+	// Invalid are the identity Chain IDs of the AdminDBSignatures that are
+	// not a valid signature by a Federated Server.
+	Invalid []string
+
+	// Majority is true if more than half of the Federated Servers signed.
+	Majority bool
+}
+
+func (d *DBSignatures) String() string {
+	var s string
+
+	s += fmt.Sprintln("Height:", d.Height)
+	s += fmt.Sprintln("Signed:", d.Signed)
+	s += fmt.Sprintln("Unsigned:", d.Unsigned)
+	s += fmt.Sprintln("Invalid:", d.Invalid)
+	s += fmt.Sprintln("Majority:", d.Majority)
+
+	return s
+}
+
+// VerifyDBSignatures checks the AdminDBSignatures over the header of the
+// Directory Block db, which are carried by the Admin Block ab of the
+// following height. The AuthoritySet must be at the height of ab, giving the
+// Federated Servers of that block and their signing keys.
+//
+// The DBEntries of db must hash to the BodyMR of the signed header, and ab
+// must hash to its LookupHash if it is set. VerifyDBSignatures does not check
+// that ab is the Admin Block of the next Directory Block; GetDBSignatures
+// does.
+func VerifyDBSignatures(db *DBlock, ab *ABlock, set *AuthoritySet) (*DBSignatures, error) {
+	height := int64(db.Header.DBHeight)
+	if ab.DBHeight != height+1 {
+		return nil, fmt.Errorf("admin block height %d does not follow directory block height %d", ab.DBHeight, height)
+	}
+	if set.Height != ab.DBHeight {
+		return nil, fmt.Errorf("authority set height %d is not admin block height %d", set.Height, ab.DBHeight)
+	}
+	keymr, err := db.ComputeKeyMR()
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Verify(keymr, nil); err != nil {
+		return nil, err
+	}
+	if ab.LookupHash != "" {
+		p, err := ab.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if h := sha256.Sum256(p); hex.EncodeToString(h[:]) != ab.LookupHash {
+			return nil, fmt.Errorf("admin block hashes to %x not %s", h, ab.LookupHash)
+		}
+	}
+	header, err := db.headerBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	federated := set.Federated()
+	signed := make(map[string]bool)
+	d := &DBSignatures{
+		Height:   height,
+		Signed:   make([]string, 0),
+		Unsigned: make([]string, 0),
+		Invalid:  make([]string, 0),
+	}
+	for _, v := range ab.ABEntries {
+		s, ok := v.(*AdminDBSignature)
+		if !ok {
+			continue
+		}
+		a := set.Authority(s.IdentityChainID)
+		if a == nil || a.Status != AuthorityFederated ||
+			a.SigningKey != s.PreviousSignature.Pub ||
+			!verifyDBSignature(header, s.PreviousSignature.Pub, s.PreviousSignature.Sig) {
+			d.Invalid = append(d.Invalid, s.IdentityChainID)
+			continue
+		}
+		signed[s.IdentityChainID] = true
+	}
+
+	for _, a := range federated {
+		if signed[a.AuthorityChainID] {
+			d.Signed = append(d.Signed, a.AuthorityChainID)
+		} else {
+			d.Unsigned = append(d.Unsigned, a.AuthorityChainID)
+		}
+	}
+	d.Majority = len(d.Signed) > len(federated)/2
+
+	return d, nil
+}
+
+// verifyDBSignature checks a hex encoded ed25519 signature of msg.
+func verifyDBSignature(msg []byte, pub, sig string) bool {
+	p, err := hex.DecodeString(pub)
+	if err != nil || len(p) != ed.PublicKeySize {
+		return false
+	}
+	s, err := hex.DecodeString(sig)
+	if err != nil || len(s) != ed.SignatureSize {
+		return false
+	}
+	var key [ed.PublicKeySize]byte
+	var signature [ed.SignatureSize]byte
+	copy(key[:], p)
+	copy(signature[:], s)
+	return ed.VerifyCanonical(&key, msg, &signature)
+}
+
+// GetDBSignatures verifies the signatures of the Directory Block at a height
+// by the Federated Servers of an AuthoritySet at the next height. The blocks
+// are decoded from their raw data, and the Admin Block carrying the
+// signatures must be the one in the next Directory Block, which must follow
+// the signed Directory Block.
+func GetDBSignatures(height int64, set *AuthoritySet) (*DBSignatures, error) {
+	return DefaultClient.GetDBSignatures(context.Background(), height, set)
+}
+
+// GetDBSignatures verifies the signatures of the Directory Block at a height
+// by the Federated Servers of an AuthoritySet at the next height. The blocks
+// are decoded from their raw data, and the Admin Block carrying the
+// signatures must be the one in the next Directory Block, which must follow
+// the signed Directory Block.
+func (c *Client) GetDBSignatures(ctx context.Context, height int64, set *AuthoritySet) (*DBSignatures, error) {
+	_, raw, err := c.GetDBlockByHeight(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	db := new(DBlock)
+	if err := db.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+
+	_, raw, err = c.GetDBlockByHeight(ctx, height+1)
+	if err != nil {
+		return nil, err
+	}
+	next := new(DBlock)
+	if err := next.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	if next.Header.PrevKeyMR != db.KeyMR {
+		return nil, fmt.Errorf("directory block %d does not follow %s", height+1, db.KeyMR)
+	}
+	if len(next.DBEntries) == 0 || next.DBEntries[0].ChainID != AdminChainID {
+		return nil, fmt.Errorf("directory block %d has no admin block", height+1)
+	}
+
+	_, raw, err = c.GetABlockByHeight(ctx, height+1)
+	if err != nil {
+		return nil, err
+	}
+	ab := new(ABlock)
+	if err := ab.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	if ab.LookupHash != next.DBEntries[0].KeyMR {
+		return nil, fmt.Errorf("admin block %s is not in directory block %d", ab.LookupHash, height+1)
+	}
+
+	return VerifyDBSignatures(db, ab, set)
+}
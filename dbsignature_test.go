@@ -0,0 +1,115 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	ed "github.com/FactomProject/ed25519"
+	. "github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/factomtest"
+)
+
+func TestDBSignatures(t *testing.T) {
+	ctx := context.Background()
+	s := factomtest.NewServer()
+	defer s.Close()
+	c := s.Client()
+
+	// three Federated Servers and an Audit Server from height 3
+	ids := make([]string, 4)
+	keys := make([]*ECAddress, 4)
+	for i := range ids {
+		ids[i] = "888888" + strings.Repeat(string(rune('a'+i)), 58)
+		k, err := MakeECAddress(bytes.Repeat([]byte{byte(i + 1)}, 32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = k
+		if i < 3 {
+			s.AddAdminEntry(&AdminAddFederatedServer{IdentityChainID: ids[i], DBHeight: 3})
+		} else {
+			s.AddAdminEntry(&AdminAddAuditServer{IdentityChainID: ids[i], DBHeight: 3})
+		}
+		s.AddAdminEntry(&AdminAddFederatedServerKey{
+			IdentityChainID: ids[i],
+			PublicKey:       hex.EncodeToString(k.PubBytes()),
+			DBHeight:        3,
+		})
+	}
+	s.NextBlock()
+	s.NextBlock()
+
+	// sign the header of the Directory Block at height 2 in the next block
+	db, raw, err := c.GetDBlockByHeight(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := raw[:len(raw)-64*len(db.DBEntries)]
+	sign := func(i int, msg []byte) *AdminDBSignature {
+		e := &AdminDBSignature{IdentityChainID: ids[i]}
+		e.PreviousSignature.Pub = hex.EncodeToString(keys[i].PubBytes())
+		e.PreviousSignature.Sig = hex.EncodeToString(ed.Sign(keys[i].SecFixed(), msg)[:])
+		return e
+	}
+	s.AddAdminEntry(sign(0, header))
+	s.AddAdminEntry(sign(1, header))
+	s.AddAdminEntry(sign(2, []byte("not the header")))
+	s.AddAdminEntry(sign(3, header))
+	s.NextBlock()
+
+	// the servers of the block carrying the signatures
+	set, err := c.GetAuthoritySetAtHeight(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetDBSignatures(ctx, 2, set); err == nil {
+		t.Errorf("expected height error, recieved %v", err)
+	}
+	if err := c.UpdateAuthoritySet(ctx, set, 3); err != nil {
+		t.Fatal(err)
+	}
+	d, err := c.GetDBSignatures(ctx, 2, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Signed) != 2 || d.Signed[0] != ids[0] || d.Signed[1] != ids[1] ||
+		len(d.Unsigned) != 1 || d.Unsigned[0] != ids[2] ||
+		len(d.Invalid) != 2 || d.Invalid[0] != ids[2] || d.Invalid[1] != ids[3] || !d.Majority {
+		t.Errorf("unexpected signatures %s", d)
+	}
+
+	// one of three is not a majority
+	ab, _, err := c.GetABlockByHeight(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab.ABEntries = ab.ABEntries[1:]
+	if _, err := VerifyDBSignatures(db, ab, set); err == nil {
+		t.Errorf("expected admin block hash error, recieved %v", err)
+	}
+	ab.LookupHash = ""
+	d, err = VerifyDBSignatures(db, ab, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Signed) != 1 || d.Majority {
+		t.Errorf("expected no majority, recieved %s", d)
+	}
+
+	// the signed header does not cover forged DBEntries
+	db.DBEntries[len(db.DBEntries)-1].KeyMR = ZeroHash
+	if _, err := VerifyDBSignatures(db, ab, set); err == nil {
+		t.Errorf("expected body error, recieved %v", err)
+	}
+
+	if _, err := c.GetDBSignatures(ctx, 1, set); err == nil {
+		t.Errorf("expected height error, recieved %v", err)
+	}
+}
@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -106,8 +107,13 @@ func (d *Diagnostics) String() string {
 
 // GetDiagnostics requests diagnostic information from factomd.
 func GetDiagnostics() (*Diagnostics, error) {
+	return DefaultClient.GetDiagnostics(context.Background())
+}
+
+// GetDiagnostics requests diagnostic information from factomd.
+func (c *Client) GetDiagnostics(ctx context.Context) (*Diagnostics, error) {
 	req := NewJSON2Request("diagnostics", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
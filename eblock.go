@@ -5,6 +5,10 @@
 package factom
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 )
@@ -17,7 +21,9 @@ type EBlock struct {
 	Header struct {
 		BlockSequenceNumber int64  `json:"blocksequencenumber"`
 		ChainID             string `json:"chainid"`
+		BodyMR              string `json:"bodymr,omitempty"`
 		PrevKeyMR           string `json:"prevkeymr"`
+		PrevFullHash        string `json:"prevfullhash,omitempty"`
 		Timestamp           int64  `json:"timestamp"`
 		DBHeight            int64  `json:"dbheight"`
 	} `json:"header"`
@@ -52,11 +58,199 @@ func (e *EBlock) String() string {
 	return s
 }
 
+// BodyHashes returns the hashes making up the body of the Entry Block: the
+// Entry Hashes of the EntryList with a minute marker after the Entries of
+// each minute. The minute of each Entry is found from its Timestamp, which
+// factomd reports as the Entry Block Timestamp plus 60 seconds for each
+// minute.
+func (e *EBlock) BodyHashes() ([][]byte, error) {
+	hashes := make([][]byte, 0, len(e.EntryList)+10)
+	var minute int64
+	for i, v := range e.EntryList {
+		h, err := hex.DecodeString(v.EntryHash)
+		if err != nil {
+			return nil, err
+		}
+		if len(h) != 32 {
+			return nil, fmt.Errorf("invalid entry hash %s", v.EntryHash)
+		}
+		m := (v.Timestamp - e.Header.Timestamp) / 60
+		if m < 1 || m > 10 || m < minute || (v.Timestamp-e.Header.Timestamp)%60 != 0 {
+			return nil, fmt.Errorf("invalid timestamp %d for entry %d", v.Timestamp, i)
+		}
+		if minute != 0 && m != minute {
+			hashes = append(hashes, minuteMarker(minute))
+		}
+		minute = m
+		hashes = append(hashes, h)
+	}
+	if minute != 0 {
+		hashes = append(hashes, minuteMarker(minute))
+	}
+	return hashes, nil
+}
+
+// minuteMarker returns the Entry Block body hash marking the end of a minute.
+func minuteMarker(minute int64) []byte {
+	m := make([]byte, 32)
+	m[31] = byte(minute)
+	return m
+}
+
+// ComputeBodyMR returns the Merkle root of the Entry Block body.
+func (e *EBlock) ComputeBodyMR() (string, error) {
+	hashes, err := e.BodyHashes()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(computeMerkleRoot(hashes)), nil
+}
+
+// Verify checks that raw is the binary Entry Block with the Key Merkle Root
+// keymr, and that the Entry Block matches it. The Entry Block returned by the
+// factomd API lacks the PrevFullHash needed to compute the KeyMR so it is
+// checked against the raw data from GetRaw.
+func (e *EBlock) Verify(keymr string, raw []byte) error {
+	// ChainID, BodyMR, PrevKeyMR, PrevFullHash, EBSequence, DBHeight,
+	// EntryCount
+	const headerLen = 32*4 + 4*3
+
+	if len(raw) < headerLen {
+		return fmt.Errorf("entry block data too short")
+	}
+	header := raw[:headerLen]
+	count := int(binary.BigEndian.Uint32(header[136:140]))
+	if len(raw) != headerLen+32*count {
+		return fmt.Errorf("entry block data has %d bytes for %d body hashes", len(raw), count)
+	}
+
+	body := make([][]byte, count)
+	for i := range body {
+		body[i] = raw[headerLen+32*i : headerLen+32*(i+1)]
+	}
+	bodyMR := computeMerkleRoot(body)
+	if !bytes.Equal(bodyMR, header[32:64]) {
+		return fmt.Errorf("entry block body hashes to %x not %x", bodyMR, header[32:64])
+	}
+	if k := hex.EncodeToString(computeKeyMR(header, bodyMR)); k != keymr {
+		return fmt.Errorf("entry block keymr is %s not %s", k, keymr)
+	}
+
+	if c := hex.EncodeToString(header[:32]); c != e.Header.ChainID {
+		return fmt.Errorf("entry block chainid %s does not match %s", e.Header.ChainID, c)
+	}
+	if p := hex.EncodeToString(header[64:96]); p != e.Header.PrevKeyMR {
+		return fmt.Errorf("entry block prevkeymr %s does not match %s", e.Header.PrevKeyMR, p)
+	}
+	if n := int64(binary.BigEndian.Uint32(header[128:132])); n != e.Header.BlockSequenceNumber {
+		return fmt.Errorf("entry block sequence number %d does not match %d", e.Header.BlockSequenceNumber, n)
+	}
+	if h := int64(binary.BigEndian.Uint32(header[132:136])); h != e.Header.DBHeight {
+		return fmt.Errorf("entry block dbheight %d does not match %d", e.Header.DBHeight, h)
+	}
+
+	hashes, err := e.BodyHashes()
+	if err != nil {
+		return err
+	}
+	if len(hashes) != count {
+		return fmt.Errorf("entry block has %d body hashes not %d", len(hashes), count)
+	}
+	for i := range hashes {
+		if !bytes.Equal(hashes[i], body[i]) {
+			return fmt.Errorf("entry block body hash %d is %x not %x", i, hashes[i], body[i])
+		}
+	}
+
+	return nil
+}
+
+// MarshalBinary returns the binary Entry Block as stored by factomd. The
+// PrevFullHash is not returned by the factomd API so an Entry Block must be
+// decoded with UnmarshalBinary, or have its PrevFullHash set, to be encoded.
+func (e *EBlock) MarshalBinary() ([]byte, error) {
+	if e.Header.PrevFullHash == "" {
+		return nil, fmt.Errorf("entry block has no prevfullhash")
+	}
+	hashes, err := e.BodyHashes()
+	if err != nil {
+		return nil, err
+	}
+	bodyMR := e.Header.BodyMR
+	if bodyMR == "" {
+		bodyMR = hex.EncodeToString(computeMerkleRoot(hashes))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := writeHashes(buf, e.Header.ChainID, bodyMR, e.Header.PrevKeyMR, e.Header.PrevFullHash); err != nil {
+		return nil, err
+	}
+	binary.Write(buf, binary.BigEndian, uint32(e.Header.BlockSequenceNumber))
+	binary.Write(buf, binary.BigEndian, uint32(e.Header.DBHeight))
+	binary.Write(buf, binary.BigEndian, uint32(len(hashes)))
+	for _, h := range hashes {
+		buf.Write(h)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a binary Entry Block, such as the raw data returned
+// by GetRaw. The binary Entry Block does not hold the Timestamp, which is
+// the Timestamp of its Directory Block, so the Header Timestamp is left
+// unchanged and the Timestamp of each EBEntry is set from it and the minute
+// the Entry was added.
+func (e *EBlock) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+
+	e.Header.ChainID = r.hash()
+	e.Header.BodyMR = r.hash()
+	e.Header.PrevKeyMR = r.hash()
+	e.Header.PrevFullHash = r.hash()
+	e.Header.BlockSequenceNumber = int64(r.uint32())
+	e.Header.DBHeight = int64(r.uint32())
+	count := int(r.uint32())
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) != 32*count {
+		return fmt.Errorf("entry block has %d bytes for %d body hashes", len(r.data), count)
+	}
+
+	// the Entries of each minute are followed by a minute marker
+	e.EntryList = e.EntryList[:0]
+	var minute []string
+	for i := 0; i < count; i++ {
+		h := r.next(32)
+		if m := h[31]; m >= 1 && m <= 10 && bytes.Equal(h, minuteMarker(int64(m))) {
+			for _, v := range minute {
+				e.EntryList = append(e.EntryList, EBEntry{
+					EntryHash: v,
+					Timestamp: e.Header.Timestamp + 60*int64(m),
+				})
+			}
+			minute = minute[:0]
+			continue
+		}
+		minute = append(minute, hex.EncodeToString(h))
+	}
+	if len(minute) > 0 {
+		return fmt.Errorf("entry block ends without a minute marker")
+	}
+
+	return r.done()
+}
+
 // GetEBlock requests an Entry Block from factomd by its Key Merkle Root
 func GetEBlock(keymr string) (*EBlock, error) {
+	return DefaultClient.GetEBlock(context.Background(), keymr)
+}
+
+// GetEBlock requests an Entry Block from factomd by its Key Merkle Root
+func (c *Client) GetEBlock(ctx context.Context, keymr string) (*EBlock, error) {
 	params := keyMRRequest{KeyMR: keymr}
 	req := NewJSON2Request("entry-block", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -69,25 +263,40 @@ func GetEBlock(keymr string) (*EBlock, error) {
 		return nil, err
 	}
 
+	if c.config().FactomdVerifyBlocks {
+		raw, err := c.GetRaw(ctx, keymr)
+		if err != nil {
+			return nil, err
+		}
+		if err := eb.Verify(keymr, raw); err != nil {
+			return nil, err
+		}
+	}
+
 	return eb, nil
 }
 
 // GetAllEBlockEntries requests every Entry from a given Entry Block
 func GetAllEBlockEntries(keymr string) ([]*Entry, error) {
-	es := make([]*Entry, 0)
+	return DefaultClient.GetAllEBlockEntries(context.Background(), keymr)
+}
 
-	eb, err := GetEBlock(keymr)
+// GetAllEBlockEntries requests every Entry from a given Entry Block
+func (c *Client) GetAllEBlockEntries(ctx context.Context, keymr string) ([]*Entry, error) {
+	eb, err := c.GetEBlock(ctx, keymr)
 	if err != nil {
-		return es, err
+		return make([]*Entry, 0), err
 	}
 
+	return c.getEBlockEntries(ctx, eb)
+}
+
+// getEBlockEntries requests every Entry listed in an Entry Block.
+func (c *Client) getEBlockEntries(ctx context.Context, eb *EBlock) ([]*Entry, error) {
+	hashes := make([]string, 0, len(eb.EntryList))
 	for _, v := range eb.EntryList {
-		e, err := GetEntry(v.EntryHash)
-		if err != nil {
-			return es, err
-		}
-		es = append(es, e)
+		hashes = append(hashes, v.EntryHash)
 	}
 
-	return es, nil
+	return c.GetEntries(ctx, hashes)
 }
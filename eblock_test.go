@@ -5,6 +5,10 @@
 package factom_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -66,3 +70,71 @@ EBEntry {
 	}
 	t.Log(response)
 }
+
+func TestEBlockVerify(t *testing.T) {
+	keymr := "7bd1725aa29c988f8f3486512a01976807a0884d4c71ac08d18d1982d905a27a"
+	raw := "df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604181735e2bc1caa844d66bd8ffd4b67e879d22f5b92c1a823008a8266b6bf4954eacdbae3b324a32cd77849bf5ab95782e5d9d8dfcba7c2b627da0d927ae19f3bee16802b7455d628a68c12b3513b75ccf0e67c6e722345fcfa2466f320e5762800008c950001130600000003e47fe17ea16474444d3895d6048b2ade4c71114f9742d31a6e1d7d035019e2ee51d3a04c2e8e4d86b84a22ac3f3a6e90046c28373b34678831fa7c460b7c69570000000000000000000000000000000000000000000000000000000000000002"
+	// both entries are in the second minute of the block
+	eblock := `{
+		"header":{
+			"blocksequencenumber":35989,
+			"chainid":"df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604",
+			"prevkeymr":"eacdbae3b324a32cd77849bf5ab95782e5d9d8dfcba7c2b627da0d927ae19f3b",
+			"timestamp":1486000000,
+			"dbheight":70406
+		},
+		"entrylist":[
+			{"entryhash":"e47fe17ea16474444d3895d6048b2ade4c71114f9742d31a6e1d7d035019e2ee","timestamp":%d},
+			{"entryhash":"51d3a04c2e8e4d86b84a22ac3f3a6e90046c28373b34678831fa7c460b7c6957","timestamp":1486000120}
+		]
+	}`
+
+	timestamp := 1486000120
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		req := new(JSON2Request)
+		json.NewDecoder(r.Body).Decode(req)
+		switch req.Method {
+		case "entry-block":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":0,"result":`+eblock+`}`, timestamp)
+		case "raw-data":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":0,"result":{"data":"%s"}}`, raw)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:], FactomdVerifyBlocks: true})
+	eb, err := c.GetEBlock(context.Background(), keymr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bodyMR, _ := eb.ComputeBodyMR(); bodyMR != "181735e2bc1caa844d66bd8ffd4b67e879d22f5b92c1a823008a8266b6bf4954" {
+		t.Errorf("unexpected body mr %s", bodyMR)
+	}
+
+	p, _ := hex.DecodeString(raw)
+	eb2 := new(EBlock)
+	eb2.Header.Timestamp = eb.Header.Timestamp
+	if err := eb2.UnmarshalBinary(p); err != nil {
+		t.Fatal(err)
+	}
+	if eb2.String() != eb.String() {
+		t.Errorf("expected:%s\nrecieved:%s", eb, eb2)
+	}
+	if q, err := eb2.MarshalBinary(); err != nil || !bytes.Equal(q, p) {
+		t.Errorf("expected:%x\nrecieved:%x %v", p, q, err)
+	}
+	if _, err := eb.MarshalBinary(); err == nil {
+		t.Error("expected an error encoding an entry block without a prevfullhash")
+	}
+
+	if _, err := c.GetEBlock(context.Background(), ZeroHash); err == nil {
+		t.Error("expected an error for the wrong keymr")
+	}
+
+	// the first entry reported in the wrong minute
+	timestamp = 1486000060
+	if _, err := c.GetEBlock(context.Background(), keymr); err == nil {
+		t.Error("expected an error for the wrong entry minute")
+	}
+}
@@ -5,6 +5,9 @@
 package factom
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
@@ -47,6 +50,9 @@ func (id ECID) String() string {
 	}
 }
 
+// ECChainID is the Chain ID of the Entry Credit Blocks.
+const ECChainID = "000000000000000000000000000000000000000000000000000000000000000c"
+
 // ECBlock (Entry Credit Block) holds transactions that create Chains and
 // Entries, and fund Entry Credit Addresses.
 type ECBlock struct {
@@ -159,6 +165,146 @@ func (e *ECBlock) UnmarshalJSON(js []byte) error {
 	return nil
 }
 
+// MarshalBinary returns the binary Entry Credit Block as stored by factomd.
+func (e *ECBlock) MarshalBinary() ([]byte, error) {
+	body := new(bytes.Buffer)
+	for _, v := range e.Entries {
+		if err := marshalECBEntry(body, v); err != nil {
+			return nil, err
+		}
+	}
+	bodyHash := e.Header.BodyHash
+	if bodyHash == "" {
+		h := sha256.Sum256(body.Bytes())
+		bodyHash = hex.EncodeToString(h[:])
+	}
+
+	buf := new(bytes.Buffer)
+	err := writeHashes(buf, ECChainID, bodyHash, e.Header.PrevHeaderHash, e.Header.PrevFullHash)
+	if err != nil {
+		return nil, err
+	}
+	binary.Write(buf, binary.BigEndian, uint32(e.Header.DBHeight))
+	writeVarInt(buf, uint64(len(e.Header.HeaderExpansionArea)))
+	buf.Write(e.Header.HeaderExpansionArea)
+	binary.Write(buf, binary.BigEndian, uint64(len(e.Entries)))
+	binary.Write(buf, binary.BigEndian, uint64(body.Len()))
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a binary Entry Credit Block, such as the raw data
+// returned by GetECBlock, and computes its HeaderHash and FullHash.
+func (e *ECBlock) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+
+	if id := r.hash(); r.err == nil && id != ECChainID {
+		return fmt.Errorf("invalid entry credit block chainid %s", id)
+	}
+	e.Header.BodyHash = r.hash()
+	e.Header.PrevHeaderHash = r.hash()
+	e.Header.PrevFullHash = r.hash()
+	e.Header.DBHeight = int64(r.uint32())
+	e.Header.HeaderExpansionArea = nil
+	if n := r.varInt(); n > 0 {
+		e.Header.HeaderExpansionArea = append([]byte{}, r.next(int(n))...)
+	}
+	count := r.uint64()
+	size := r.uint64()
+	if r.err != nil {
+		return r.err
+	}
+	if uint64(len(r.data)) != size {
+		return fmt.Errorf("entry credit block body is %d bytes not %d", len(r.data), size)
+	}
+	header := data[:len(data)-len(r.data)]
+
+	e.Entries = e.Entries[:0]
+	for i := uint64(0); i < count; i++ {
+		v, err := unmarshalECBEntry(r)
+		if err != nil {
+			return err
+		}
+		e.Entries = append(e.Entries, v)
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	h := sha256.Sum256(header)
+	e.HeaderHash = hex.EncodeToString(h[:])
+	h = sha256.Sum256(data)
+	e.FullHash = hex.EncodeToString(h[:])
+
+	return nil
+}
+
+// marshalECBEntry writes the ECID and binary data of an ECBEntry.
+func marshalECBEntry(buf *bytes.Buffer, v ECBEntry) error {
+	data := new(bytes.Buffer)
+	var err error
+
+	switch v := v.(type) {
+	case *ECServerIndexNumber:
+		data.WriteByte(byte(v.ServerIndexNumber))
+	case *ECMinuteNumber:
+		data.WriteByte(byte(v.Number))
+	case *ECChainCommit:
+		err = v.writeBinary(data)
+	case *ECEntryCommit:
+		err = v.writeBinary(data)
+	case *ECBalanceIncrease:
+		err = writeHashes(data, v.ECPubKey, v.TXID)
+		writeVarInt(data, v.Index)
+		writeVarInt(data, v.NumEC)
+	default:
+		return ErrUnknownECBEntry
+	}
+	if err != nil {
+		return err
+	}
+
+	buf.WriteByte(byte(v.Type()))
+	buf.Write(data.Bytes())
+
+	return nil
+}
+
+// unmarshalECBEntry reads an ECBEntry written by marshalECBEntry.
+func unmarshalECBEntry(r *binaryReader) (ECBEntry, error) {
+	var v ECBEntry
+
+	switch id := ECID(r.byte()); id {
+	case ECIDServerIndexNumber:
+		v = &ECServerIndexNumber{ServerIndexNumber: int(r.byte())}
+	case ECIDMinuteNumber:
+		v = &ECMinuteNumber{Number: int(r.byte())}
+	case ECIDChainCommit:
+		c := new(ECChainCommit)
+		c.readBinary(r)
+		v = c
+	case ECIDEntryCommit:
+		c := new(ECEntryCommit)
+		c.readBinary(r)
+		v = c
+	case ECIDBalanceIncrease:
+		b := new(ECBalanceIncrease)
+		b.ECPubKey = r.hash()
+		b.TXID = r.hash()
+		b.Index = r.varInt()
+		b.NumEC = r.varInt()
+		v = b
+	default:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, ErrUnknownECBEntry
+	}
+
+	return v, r.err
+}
+
 // an ECBEntry is an individual member of the Entry Credit Block.
 type ECBEntry interface {
 	Type() ECID
@@ -352,9 +498,14 @@ func (e *ECBalanceIncrease) String() string {
 
 // GetECBlock requests a specified Entry Credit Block from the factomd API.
 func GetECBlock(keymr string) (ecblock *ECBlock, raw []byte, err error) {
+	return DefaultClient.GetECBlock(context.Background(), keymr)
+}
+
+// GetECBlock requests a specified Entry Credit Block from the factomd API.
+func (c *Client) GetECBlock(ctx context.Context, keymr string) (ecblock *ECBlock, raw []byte, err error) {
 	params := keyMRRequest{KeyMR: keymr}
 	req := NewJSON2Request("entrycredit-block", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
@@ -383,9 +534,15 @@ func GetECBlock(keymr string) (ecblock *ECBlock, raw []byte, err error) {
 // GetECBlockByHeight request an Entry Credit Block of a given height from the
 // factomd API.
 func GetECBlockByHeight(height int64) (ecblock *ECBlock, raw []byte, err error) {
+	return DefaultClient.GetECBlockByHeight(context.Background(), height)
+}
+
+// GetECBlockByHeight request an Entry Credit Block of a given height from the
+// factomd API.
+func (c *Client) GetECBlockByHeight(ctx context.Context, height int64) (ecblock *ECBlock, raw []byte, err error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request("ecblock-by-height", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
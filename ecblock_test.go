@@ -5,6 +5,7 @@
 package factom_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -127,6 +128,22 @@ func TestGetECBlock(t *testing.T) {
 	}
 	t.Log("ECBlock: ", ecb)
 	t.Log(fmt.Sprintf("raw: %x\n", raw))
+
+	ecb2 := new(ECBlock)
+	if err := ecb2.UnmarshalBinary(raw); err != nil {
+		t.Fatal(err)
+	}
+	if ecb2.String() != ecb.String() {
+		t.Errorf("expected:%s\nrecieved:%s", ecb, ecb2)
+	}
+	for _, e := range []*ECBlock{ecb, ecb2} {
+		if p, err := e.MarshalBinary(); err != nil || !bytes.Equal(p, raw) {
+			t.Errorf("expected:%x\nrecieved:%x %v", raw, p, err)
+		}
+	}
+	if err := ecb2.UnmarshalBinary(append(raw, 0)); err == nil {
+		t.Error("expected an error for extra entry credit block data")
+	}
 }
 
 func TestGetECBlockByHeight(t *testing.T) {
@@ -5,18 +5,25 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 )
 
 // GetECRate returns the current conversion rate cost in factoshis
 // (Factoid^(-1e8)) of purchasing Entry Credits.
 func GetECRate() (uint64, error) {
+	return DefaultClient.GetECRate(context.Background())
+}
+
+// GetECRate returns the current conversion rate cost in factoshis
+// (Factoid^(-1e8)) of purchasing Entry Credits.
+func (c *Client) GetECRate(ctx context.Context) (uint64, error) {
 	type rateResponse struct {
 		Rate uint64 `json:"rate"`
 	}
 
 	req := NewJSON2Request("entry-credit-rate", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return 0, err
 	}
@@ -6,12 +6,32 @@ package factom
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
+// Binary Entry sizes
+const (
+	// EntryHeaderSize is the size of the version, ChainID and ExtIDs size at
+	// the start of a binary Entry.
+	EntryHeaderSize = 35
+	// EntryMaxDataSize is the largest combined size of the ExtIDs and Content
+	// of an Entry.
+	EntryMaxDataSize = 10240
+)
+
+// Binary Entry errors
+var (
+	ErrEntryTooShort   = errors.New("entry data shorter than the entry header")
+	ErrEntryTooLarge   = errors.New("entry data larger than 10KB")
+	ErrEntryVersion    = errors.New("unsupported entry version")
+	ErrEntryExtIDsSize = errors.New("entry extids do not match the extids size")
+)
+
 type Entry struct {
 	ChainID string   `json:"chainid"`
 	ExtIDs  [][]byte `json:"extids"`
@@ -94,6 +114,102 @@ func (e *Entry) MarshalExtIDsBinary() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// UnmarshalBinary decodes a binary Entry, such as the raw data returned by
+// GetRaw or held in a Receipt. The data must be exactly one Entry: the binary
+// Entry does not record the size of its Content, which is the data following
+// the ExtIDs.
+func (e *Entry) UnmarshalBinary(data []byte) error {
+	if len(data) < EntryHeaderSize {
+		return ErrEntryTooShort
+	}
+	if len(data) > EntryHeaderSize+EntryMaxDataSize {
+		return ErrEntryTooLarge
+	}
+	if data[0] != 0 {
+		return ErrEntryVersion
+	}
+	chainid := data[1:33]
+	size := int(binary.BigEndian.Uint16(data[33:35]))
+	data = data[EntryHeaderSize:]
+	if size > len(data) {
+		return ErrEntryExtIDsSize
+	}
+
+	ids := data[:size]
+	extids := make([][]byte, 0)
+	for len(ids) > 0 {
+		if len(ids) < 2 {
+			return ErrEntryExtIDsSize
+		}
+		n := int(binary.BigEndian.Uint16(ids[:2]))
+		if n > len(ids)-2 {
+			return ErrEntryExtIDsSize
+		}
+		extids = append(extids, append([]byte{}, ids[2:2+n]...))
+		ids = ids[2+n:]
+	}
+
+	e.ChainID = hex.EncodeToString(chainid)
+	e.ExtIDs = extids
+	e.Content = append([]byte{}, data[size:]...)
+
+	return nil
+}
+
+// MarshalEntries encodes a stream of binary Entries. Each Entry is preceded by
+// its size as a 4 byte big endian integer, as the binary Entry does not record
+// the size of its Content.
+func MarshalEntries(es []*Entry) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	for _, e := range es {
+		p, err := e.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		binary.Write(buf, binary.BigEndian, uint32(len(p)))
+		buf.Write(p)
+	}
+	return buf.Bytes(), nil
+}
+
+// entryStreamError is an error decoding an Entry of a stream, which unwraps
+// to the error from the Entry.
+type entryStreamError struct {
+	index int
+	err   error
+}
+
+func (e *entryStreamError) Error() string {
+	return fmt.Sprintf("entry %d: %v", e.index, e.err)
+}
+
+func (e *entryStreamError) Unwrap() error {
+	return e.err
+}
+
+// UnmarshalEntries decodes a stream of binary Entries written by
+// MarshalEntries. The error for a malformed Entry wraps the error from
+// Entry.UnmarshalBinary, or ErrBinaryTooShort if the stream is truncated.
+func UnmarshalEntries(data []byte) ([]*Entry, error) {
+	es := make([]*Entry, 0)
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, &entryStreamError{len(es), ErrBinaryTooShort}
+		}
+		n := binary.BigEndian.Uint32(data[:4])
+		if uint64(n) > uint64(len(data)-4) {
+			return nil, &entryStreamError{len(es), ErrBinaryTooShort}
+		}
+		e := new(Entry)
+		if err := e.UnmarshalBinary(data[4 : 4+n]); err != nil {
+			return nil, &entryStreamError{len(es), err}
+		}
+		es = append(es, e)
+		data = data[4+n:]
+	}
+	return es, nil
+}
+
 func (e *Entry) MarshalJSON() ([]byte, error) {
 	type js struct {
 		ChainID string   `json:"chainid"`
@@ -222,6 +338,13 @@ func ComposeEntryReveal(e *Entry) (*JSON2Request, error) {
 // the factom network. Once the payment is verified and the network is commited
 // to publishing the Entry it may be published with a call to RevealEntry.
 func CommitEntry(e *Entry, ec *ECAddress) (string, error) {
+	return DefaultClient.CommitEntry(context.Background(), e, ec)
+}
+
+// CommitEntry sends the signed Entry Hash and the Entry Credit public key to
+// the factom network. Once the payment is verified and the network is commited
+// to publishing the Entry it may be published with a call to RevealEntry.
+func (c *Client) CommitEntry(ctx context.Context, e *Entry, ec *ECAddress) (string, error) {
 	type commitResponse struct {
 		Message string `json:"message"`
 		TxID    string `json:"txid"`
@@ -232,7 +355,7 @@ func CommitEntry(e *Entry, ec *ECAddress) (string, error) {
 		return "", err
 	}
 
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -251,6 +374,12 @@ func CommitEntry(e *Entry, ec *ECAddress) (string, error) {
 // RevealEntrysends the Entry data to the factom network to create an Entry that
 // has previously been commited.
 func RevealEntry(e *Entry) (string, error) {
+	return DefaultClient.RevealEntry(context.Background(), e)
+}
+
+// RevealEntrysends the Entry data to the factom network to create an Entry that
+// has previously been commited.
+func (c *Client) RevealEntry(ctx context.Context, e *Entry) (string, error) {
 	type revealResponse struct {
 		Message string `json:"message"`
 		Entry   string `json:"entryhash"`
@@ -261,7 +390,7 @@ func RevealEntry(e *Entry) (string, error) {
 		return "", err
 	}
 
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -278,9 +407,14 @@ func RevealEntry(e *Entry) (string, error) {
 
 // GetEntry requests an Entry from the factomd API by its Entry Hash
 func GetEntry(hash string) (*Entry, error) {
+	return DefaultClient.GetEntry(context.Background(), hash)
+}
+
+// GetEntry requests an Entry from the factomd API by its Entry Hash
+func (c *Client) GetEntry(ctx context.Context, hash string) (*Entry, error) {
 	params := hashRequest{Hash: hash}
 	req := NewJSON2Request("entry", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -296,20 +430,109 @@ func GetEntry(hash string) (*Entry, error) {
 	return e, nil
 }
 
+// GetEntries requests a list of Entries from the factomd API by their Entry
+// Hashes and returns them in the same order.
+func GetEntries(hashes []string) ([]*Entry, error) {
+	return DefaultClient.GetEntries(context.Background(), hashes)
+}
+
+// GetEntries requests a list of Entries from the factomd API by their Entry
+// Hashes and returns them in the same order. The requests are sent as JSON RPC
+// batches of FactomdBatchSize Entries with up to FactomdConcurrency batches in
+// flight at once. GetEntries stops at the first error.
+func (c *Client) GetEntries(ctx context.Context, hashes []string) ([]*Entry, error) {
+	es := make([]*Entry, 0, len(hashes))
+
+	reqs := make([]*JSON2Request, 0, len(hashes))
+	for _, hash := range hashes {
+		params := hashRequest{Hash: hash}
+		reqs = append(reqs, NewJSON2Request("entry", APICounter(), params))
+	}
+
+	resps, err := c.factomdBatchRequest(ctx, reqs)
+	if err != nil {
+		return es, err
+	}
+
+	for _, resp := range resps {
+		if resp.Error != nil {
+			return es, resp.Error
+		}
+		e := new(Entry)
+		if err := json.Unmarshal(resp.JSONResult(), e); err != nil {
+			return es, err
+		}
+		es = append(es, e)
+	}
+
+	return es, nil
+}
+
 // GetPendingEntries requests a list of all Entries that are waiting to be
 // written into the next block on the Factom Blockchain.
 func GetPendingEntries() (string, error) {
+	return DefaultClient.GetPendingEntries(context.Background())
+}
+
+// GetPendingEntries requests a list of all Entries that are waiting to be
+// written into the next block on the Factom Blockchain.
+func (c *Client) GetPendingEntries(ctx context.Context) (string, error) {
 	req := NewJSON2Request("pending-entries", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 
 	if err != nil {
 		return "", err
 	}
 	if resp.Error != nil {
-		return "", err
+		return "", resp.Error
 	}
 
 	rBytes := resp.JSONResult()
 
 	return string(rBytes), nil
 }
+
+// PendingEntry is an Entry that has been acknowledged by factomd but is not
+// yet in an Entry Block.
+type PendingEntry struct {
+	EntryHash string `json:"entryhash"`
+	ChainID   string `json:"chainid"`
+	Status    string `json:"status"`
+}
+
+// ListPendingEntries returns the Entries that are waiting to be written into
+// the next block. If chainid is not empty only the Entries of that Chain are
+// returned.
+func ListPendingEntries(chainid string) ([]*PendingEntry, error) {
+	return DefaultClient.ListPendingEntries(context.Background(), chainid)
+}
+
+// ListPendingEntries returns the Entries that are waiting to be written into
+// the next block. If chainid is not empty only the Entries of that Chain are
+// returned.
+func (c *Client) ListPendingEntries(ctx context.Context, chainid string) ([]*PendingEntry, error) {
+	req := NewJSON2Request("pending-entries", APICounter(), nil)
+	resp, err := c.factomdRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	pending := make([]*PendingEntry, 0)
+	if err := json.Unmarshal(resp.JSONResult(), &pending); err != nil {
+		return nil, err
+	}
+	if chainid == "" {
+		return pending, nil
+	}
+
+	es := make([]*PendingEntry, 0)
+	for _, e := range pending {
+		if e.ChainID == chainid {
+			es = append(es, e)
+		}
+	}
+	return es, nil
+}
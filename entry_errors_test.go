@@ -0,0 +1,55 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build go1.13
+// +build go1.13
+
+package factom_test
+
+import (
+	"errors"
+
+	. "github.com/FactomProject/factom"
+
+	"testing"
+)
+
+// the errors for a malformed Entry stream match the Entry error sentinels
+func TestUnmarshalEntriesWrapped(t *testing.T) {
+	es := []*Entry{
+		NewEntryFromStrings(ZeroHash, "first", "id"),
+		NewEntryFromStrings(ZeroHash, "second", "id"),
+	}
+	stream, err := MarshalEntries(es)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := es[0].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := 4 + len(first) + 4 // the start of the second Entry
+
+	corrupt := func(f func(p []byte) []byte) []byte {
+		return f(append([]byte{}, stream...))
+	}
+	for _, v := range []struct {
+		data []byte
+		err  error
+	}{
+		{corrupt(func(p []byte) []byte { p[second] = 1; return p }), ErrEntryVersion},
+		{corrupt(func(p []byte) []byte { p[second+34] = 0xff; return p }), ErrEntryExtIDsSize},
+		{corrupt(func(p []byte) []byte {
+			p[second-1] = 30
+			return p[:second+30]
+		}), ErrEntryTooShort},
+		{stream[:len(stream)-1], ErrBinaryTooShort},
+		{stream[:second-2], ErrBinaryTooShort},
+	} {
+		_, err := UnmarshalEntries(v.data)
+		if !errors.Is(err, v.err) {
+			t.Errorf("expected %v, recieved %v", v.err, err)
+		}
+	}
+}
@@ -308,3 +308,112 @@ hello world
 		t.Errorf("expected:%s\nrecieved:%s", expectedResponse, response)
 	}
 }
+
+func TestUnmarshalBinary(t *testing.T) {
+	p, _ := hex.DecodeString("005a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be7090690035001854686973206973207468652066697273742065787469642e00195468697320697320746865207365636f6e642065787469642e546869732069732061207465737420456e7472792e")
+
+	e := new(Entry)
+	if err := e.UnmarshalBinary(p); err != nil {
+		t.Fatal(err)
+	}
+	if e.ChainID != "5a402200c5cf278e47905ce52d7d64529a0291829a7bd230072c5468be709069" ||
+		len(e.ExtIDs) != 2 || string(e.ExtIDs[1]) != "This is the second extid." ||
+		string(e.Content) != "This is a test Entry." {
+		t.Errorf("unexpected entry %s", e)
+	}
+	if q, _ := e.MarshalBinary(); !bytes.Equal(p, q) {
+		t.Errorf("expected:%x\nrecieved:%x", p, q)
+	}
+
+	bad := func(f func(q []byte) []byte) []byte {
+		return f(append([]byte{}, p...))
+	}
+	for _, v := range []struct {
+		data []byte
+		err  error
+	}{
+		{p[:34], ErrEntryTooShort},
+		{append(p, make([]byte, EntryMaxDataSize)...), ErrEntryTooLarge},
+		{bad(func(q []byte) []byte { q[0] = 1; return q }), ErrEntryVersion},
+		{bad(func(q []byte) []byte { q[34] = 0xff; return q }), ErrEntryExtIDsSize},
+		{bad(func(q []byte) []byte { q[34] = 0x34; return q }), ErrEntryExtIDsSize},
+		{bad(func(q []byte) []byte { q[36] = 0x19; return q }), ErrEntryExtIDsSize},
+	} {
+		if err := new(Entry).UnmarshalBinary(v.data); err != v.err {
+			t.Errorf("expected %v, recieved %v", v.err, err)
+		}
+	}
+
+	es := []*Entry{e, NewEntryFromStrings(e.ChainID, ""), NewEntryFromStrings(e.ChainID, "content", "", "id")}
+	stream, err := MarshalEntries(es)
+	if err != nil {
+		t.Fatal(err)
+	}
+	es2, err := UnmarshalEntries(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es2) != len(es) {
+		t.Fatalf("expected %d entries, recieved %d", len(es), len(es2))
+	}
+	for i := range es {
+		if !bytes.Equal(es[i].Hash(), es2[i].Hash()) {
+			t.Errorf("expected:%s\nrecieved:%s", es[i], es2[i])
+		}
+	}
+	if _, err := UnmarshalEntries(stream[:len(stream)-1]); err == nil {
+		t.Error("expected an error for a truncated entry stream")
+	}
+}
+
+func TestListPendingEntries(t *testing.T) {
+	factomdResponse := `{
+		"jsonrpc":"2.0",
+		"id":0,
+		"result":[
+			{
+				"entryhash":"1c840bc18be182e89e12f9e63fb8897d13b071b631ced7e656837ccea8fdb3ae",
+				"chainid":"df3ade9eec4b08d5379cc64270c30ea7315d8a8a1a69efe2b98a60ecdd69e604",
+				"status":"TransactionACK"
+			},
+			{
+				"entryhash":"be5216cc7a5a3ad44b49245aec298f47cbdfca9862dee13b0093e5880012b771",
+				"chainid":"954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4",
+				"status":"TransactionACK"
+			}
+		]
+	}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, factomdResponse)
+	}))
+	defer ts.Close()
+
+	SetFactomdServer(ts.URL[7:])
+
+	es, err := ListPendingEntries("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 2 || es[1].EntryHash != "be5216cc7a5a3ad44b49245aec298f47cbdfca9862dee13b0093e5880012b771" ||
+		es[1].Status != "TransactionACK" {
+		t.Errorf("unexpected pending entries %v", es)
+	}
+
+	es, err = ListPendingEntries("954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 1 || es[0].ChainID != "954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4" {
+		t.Errorf("unexpected pending entries %v", es)
+	}
+
+	// errors from factomd are returned
+	factomdResponse = `{"jsonrpc":"2.0","id":0,"error":{"code":-32603,"message":"Internal error"}}`
+	if _, err := GetPendingEntries(); err == nil {
+		t.Error("expected an error from GetPendingEntries")
+	}
+	if _, err := ListPendingEntries(""); err == nil {
+		t.Error("expected an error from ListPendingEntries")
+	}
+}
@@ -0,0 +1,413 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ed "github.com/FactomProject/ed25519"
+)
+
+// Factoid Transaction errors
+var (
+	ErrUnsupportedRCD  = errors.New("only type 1 RCDs are supported")
+	ErrMissingRCD      = errors.New("factoid transaction needs one RCD and signature per input")
+	ErrInvalidRCD      = errors.New("RCD does not match the input address")
+	ErrInvalidSig      = errors.New("invalid factoid transaction signature")
+	ErrTooManyIOs      = errors.New("factoid transaction has more than 255 inputs or outputs")
+	ErrInsufficientFee = errors.New("factoid transaction outputs exceed its inputs")
+	ErrAmountOverflow  = errors.New("factoid transaction amounts overflow")
+)
+
+// factoidTxVersion is the only Factoid Transaction version used by factomd.
+const factoidTxVersion = 2
+
+// FactoidTransaction is a Factoid Transaction as recorded in a Factoid Block:
+// a transfer of Factoids from the RCD Hashes of the Inputs to the Outputs and
+// the purchase of Entry Credits for the ECOutputs.
+//
+// The Address of each input and output is a public Factoid Address (FA...),
+// or a public Entry Credit Address (EC...) for the ECOutputs.
+type FactoidTransaction struct {
+	TxID           string
+	BlockHeight    int64
+	MilliTimestamp int64
+
+	Inputs    []*TransAddress
+	Outputs   []*TransAddress
+	ECOutputs []*TransAddress
+
+	// RCDs and Signatures redeem each of the Inputs in order.
+	RCDs       []*RCD1
+	Signatures [][]byte
+
+	// Coinbase is true for the first Transaction of a Factoid Block, which
+	// pays the block rewards and has no Inputs.
+	Coinbase bool
+
+	// Minute is the minute of the Factoid Block, from 0 to 9, in which the
+	// Transaction was included. It is only known for Transactions decoded from
+	// a binary Factoid Block.
+	Minute int
+
+	// keys are the Factoid Addresses of the Inputs added by AddInput, held
+	// until Sign.
+	keys []*FactoidAddress
+}
+
+func (t *FactoidTransaction) String() string {
+	var s string
+
+	s += fmt.Sprintln("TxID:", t.TxID)
+	s += fmt.Sprintln("MilliTimestamp:", t.MilliTimestamp)
+	if t.Coinbase {
+		s += fmt.Sprintln("Coinbase:", t.Coinbase)
+	}
+	s += fmt.Sprintln("Minute:", t.Minute)
+	for _, in := range t.Inputs {
+		s += fmt.Sprintln("Input:", in.Address, FactoshiToFactoid(in.Amount))
+	}
+	for _, out := range t.Outputs {
+		s += fmt.Sprintln("Output:", out.Address, FactoshiToFactoid(out.Amount))
+	}
+	for _, ec := range t.ECOutputs {
+		s += fmt.Sprintln("ECOutput:", ec.Address, FactoshiToFactoid(ec.Amount))
+	}
+
+	return s
+}
+
+// factoidTransAddress is an input or output in the factomd JSON form. Address
+// is the RCD Hash, or the public key of an Entry Credit output, and
+// UserAddress is the public address string.
+type factoidTransAddress struct {
+	Amount      uint64 `json:"amount"`
+	Address     string `json:"address"`
+	UserAddress string `json:"useraddress"`
+}
+
+type factoidSigBlock struct {
+	Signatures []string `json:"signatures"`
+}
+
+// factoidTransactionJSON is the factomd JSON form of a FactoidTransaction.
+type factoidTransactionJSON struct {
+	TxID           string                `json:"txid"`
+	BlockHeight    int64                 `json:"blockheight"`
+	MilliTimestamp int64                 `json:"millitimestamp"`
+	Inputs         []factoidTransAddress `json:"inputs"`
+	Outputs        []factoidTransAddress `json:"outputs"`
+	OutECs         []factoidTransAddress `json:"outecs"`
+	RCDs           []string              `json:"rcds"`
+	SigBlocks      []factoidSigBlock     `json:"sigblocks"`
+}
+
+// MarshalJSON encodes the FactoidTransaction in the form returned by factomd.
+func (t *FactoidTransaction) MarshalJSON() ([]byte, error) {
+	j := &factoidTransactionJSON{
+		TxID:           t.TxID,
+		BlockHeight:    t.BlockHeight,
+		MilliTimestamp: t.MilliTimestamp,
+		RCDs:           make([]string, 0, len(t.RCDs)),
+		SigBlocks:      make([]factoidSigBlock, 0, len(t.Signatures)),
+	}
+
+	var err error
+	if j.Inputs, err = marshalTransAddresses(t.Inputs, fcPubPrefix); err != nil {
+		return nil, err
+	}
+	if j.Outputs, err = marshalTransAddresses(t.Outputs, fcPubPrefix); err != nil {
+		return nil, err
+	}
+	if j.OutECs, err = marshalTransAddresses(t.ECOutputs, ecPubPrefix); err != nil {
+		return nil, err
+	}
+	for _, r := range t.RCDs {
+		j.RCDs = append(j.RCDs, hex.EncodeToString(append([]byte{r.Type()}, r.PubBytes()...)))
+	}
+	for _, sig := range t.Signatures {
+		j.SigBlocks = append(j.SigBlocks, factoidSigBlock{[]string{hex.EncodeToString(sig)}})
+	}
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a FactoidTransaction in the form returned by factomd.
+func (t *FactoidTransaction) UnmarshalJSON(data []byte) error {
+	j := new(factoidTransactionJSON)
+	if err := json.Unmarshal(data, j); err != nil {
+		return err
+	}
+
+	t.TxID = j.TxID
+	t.BlockHeight = j.BlockHeight
+	t.MilliTimestamp = j.MilliTimestamp
+
+	var err error
+	if t.Inputs, err = unmarshalTransAddresses(j.Inputs, fcPubPrefix); err != nil {
+		return err
+	}
+	if t.Outputs, err = unmarshalTransAddresses(j.Outputs, fcPubPrefix); err != nil {
+		return err
+	}
+	if t.ECOutputs, err = unmarshalTransAddresses(j.OutECs, ecPubPrefix); err != nil {
+		return err
+	}
+
+	t.RCDs = make([]*RCD1, 0, len(j.RCDs))
+	for _, v := range j.RCDs {
+		p, err := hex.DecodeString(v)
+		if err != nil {
+			return err
+		}
+		if len(p) != 33 || p[0] != 1 {
+			return ErrUnsupportedRCD
+		}
+		r := NewRCD1()
+		copy(r.Pub[:], p[1:])
+		t.RCDs = append(t.RCDs, r)
+	}
+	t.Signatures = make([][]byte, 0, len(j.SigBlocks))
+	for _, v := range j.SigBlocks {
+		if len(v.Signatures) != 1 {
+			return ErrUnsupportedRCD
+		}
+		sig, err := hex.DecodeString(v.Signatures[0])
+		if err != nil {
+			return err
+		}
+		t.Signatures = append(t.Signatures, sig)
+	}
+
+	return nil
+}
+
+func marshalTransAddresses(as []*TransAddress, prefix []byte) ([]factoidTransAddress, error) {
+	js := make([]factoidTransAddress, 0, len(as))
+	for _, a := range as {
+		key, err := decodePubAddress(prefix, a.Address)
+		if err != nil {
+			return nil, err
+		}
+		js = append(js, factoidTransAddress{a.Amount, hex.EncodeToString(key), a.Address})
+	}
+	return js, nil
+}
+
+func unmarshalTransAddresses(js []factoidTransAddress, prefix []byte) ([]*TransAddress, error) {
+	as := make([]*TransAddress, 0, len(js))
+	for _, j := range js {
+		key, err := hex.DecodeString(j.Address)
+		if err != nil {
+			return nil, err
+		}
+		if len(key) != 32 {
+			return nil, ErrInvalidAddress
+		}
+		as = append(as, &TransAddress{Address: encodePubAddress(prefix, key), Amount: j.Amount})
+	}
+	return as, nil
+}
+
+// MarshalLedgerBinary returns the binary Transaction without its RCDs and
+// Signatures. It is the data signed by each input, and its hash is the TxID.
+func (t *FactoidTransaction) MarshalLedgerBinary() ([]byte, error) {
+	if len(t.Inputs) > 255 || len(t.Outputs) > 255 || len(t.ECOutputs) > 255 {
+		return nil, ErrTooManyIOs
+	}
+
+	buf := new(bytes.Buffer)
+	writeVarInt(buf, factoidTxVersion)
+	buf.Write(milliTimeBytes(t.MilliTimestamp))
+	buf.WriteByte(byte(len(t.Inputs)))
+	buf.WriteByte(byte(len(t.Outputs)))
+	buf.WriteByte(byte(len(t.ECOutputs)))
+	for _, v := range []struct {
+		as     []*TransAddress
+		prefix []byte
+	}{{t.Inputs, fcPubPrefix}, {t.Outputs, fcPubPrefix}, {t.ECOutputs, ecPubPrefix}} {
+		for _, a := range v.as {
+			key, err := decodePubAddress(v.prefix, a.Address)
+			if err != nil {
+				return nil, err
+			}
+			writeVarInt(buf, a.Amount)
+			buf.Write(key)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary returns the binary Transaction with an RCD and Signature for
+// each input. Only type 1 RCDs are supported.
+func (t *FactoidTransaction) MarshalBinary() ([]byte, error) {
+	p, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return nil, err
+	}
+	if len(t.RCDs) != len(t.Inputs) || len(t.Signatures) != len(t.Inputs) {
+		return nil, ErrMissingRCD
+	}
+
+	buf := bytes.NewBuffer(p)
+	for i, r := range t.RCDs {
+		if len(t.Signatures[i]) != ed.SignatureSize {
+			return nil, ErrInvalidSig
+		}
+		buf.WriteByte(r.Type())
+		buf.Write(r.PubBytes())
+		buf.Write(t.Signatures[i])
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a binary Transaction and computes its TxID.
+func (t *FactoidTransaction) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+	if err := t.unmarshalBinary(r); err != nil {
+		return err
+	}
+	return r.done()
+}
+
+// unmarshalBinary reads a binary Transaction from a Factoid Block.
+func (t *FactoidTransaction) unmarshalBinary(r *binaryReader) error {
+	start := r.data
+	if v := r.varInt(); r.err == nil && v != factoidTxVersion {
+		return fmt.Errorf("unsupported factoid transaction version %d", v)
+	}
+	t.MilliTimestamp = r.milliTime()
+	in, out, ec := int(r.byte()), int(r.byte()), int(r.byte())
+
+	read := func(n int, prefix []byte) []*TransAddress {
+		as := make([]*TransAddress, 0, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			a := &TransAddress{Amount: r.varInt()}
+			a.Address = encodePubAddress(prefix, r.next(32))
+			as = append(as, a)
+		}
+		return as
+	}
+	t.Inputs = read(in, fcPubPrefix)
+	t.Outputs = read(out, fcPubPrefix)
+	t.ECOutputs = read(ec, ecPubPrefix)
+	if r.err != nil {
+		return r.err
+	}
+	txid := sha256.Sum256(start[:len(start)-len(r.data)])
+	t.TxID = hex.EncodeToString(txid[:])
+
+	t.RCDs = make([]*RCD1, 0, in)
+	t.Signatures = make([][]byte, 0, in)
+	for i := 0; i < in; i++ {
+		if typ := r.byte(); r.err == nil && typ != 1 {
+			return ErrUnsupportedRCD
+		}
+		rcd := NewRCD1()
+		copy(rcd.Pub[:], r.next(ed.PublicKeySize))
+		t.RCDs = append(t.RCDs, rcd)
+		t.Signatures = append(t.Signatures, append([]byte{}, r.next(ed.SignatureSize)...))
+	}
+
+	return r.err
+}
+
+// ComputeTxID returns the TxID, the hash of the ledger binary Transaction.
+func (t *FactoidTransaction) ComputeTxID() (string, error) {
+	p, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(p)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// TotalInputs returns the sum of the Inputs in factoshis.
+func (t *FactoidTransaction) TotalInputs() (uint64, error) {
+	return sumTransAddresses(t.Inputs)
+}
+
+// TotalOutputs returns the sum of the Outputs in factoshis.
+func (t *FactoidTransaction) TotalOutputs() (uint64, error) {
+	return sumTransAddresses(t.Outputs)
+}
+
+// TotalECOutputs returns the sum of the ECOutputs in factoshis.
+func (t *FactoidTransaction) TotalECOutputs() (uint64, error) {
+	return sumTransAddresses(t.ECOutputs)
+}
+
+// sumTransAddresses returns the sum of the amounts, or ErrAmountOverflow if it
+// does not fit in a uint64.
+func sumTransAddresses(as []*TransAddress) (uint64, error) {
+	var total uint64
+	for _, a := range as {
+		if total+a.Amount < total {
+			return 0, ErrAmountOverflow
+		}
+		total += a.Amount
+	}
+	return total, nil
+}
+
+// FeesPaid returns the fee paid by the Transaction in factoshis: the amount of
+// the Inputs not spent on the Outputs or ECOutputs. The coinbase Transaction
+// pays no fee.
+func (t *FactoidTransaction) FeesPaid() (uint64, error) {
+	if t.Coinbase {
+		return 0, nil
+	}
+	in, err := t.TotalInputs()
+	if err != nil {
+		return 0, err
+	}
+	out, err := sumTransAddresses(append(append([]*TransAddress{}, t.Outputs...), t.ECOutputs...))
+	if err != nil {
+		return 0, err
+	}
+	if out > in {
+		return 0, ErrInsufficientFee
+	}
+	return in - out, nil
+}
+
+// VerifySignatures checks that each input is redeemed by an RCD hashing to
+// its address with a valid Signature of the ledger binary Transaction.
+func (t *FactoidTransaction) VerifySignatures() error {
+	if len(t.RCDs) != len(t.Inputs) || len(t.Signatures) != len(t.Inputs) {
+		return ErrMissingRCD
+	}
+	msg, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return err
+	}
+
+	for i, in := range t.Inputs {
+		rcdHash, err := decodePubAddress(fcPubPrefix, in.Address)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(t.RCDs[i].Hash(), rcdHash) {
+			return ErrInvalidRCD
+		}
+		if len(t.Signatures[i]) != ed.SignatureSize {
+			return ErrInvalidSig
+		}
+		sig := new([ed.SignatureSize]byte)
+		copy(sig[:], t.Signatures[i])
+		if !ed.VerifyCanonical(t.RCDs[i].Pub, msg, sig) {
+			return ErrInvalidSig
+		}
+	}
+
+	return nil
+}
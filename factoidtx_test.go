@@ -0,0 +1,171 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+
+	. "github.com/FactomProject/factom"
+)
+
+func TestFactoidTransaction(t *testing.T) {
+	js := `{"txid":"1ec91421e01d95267f3deb9b9d5f29d3438387a0280a5ffa5e9a60f235212ae8","blockheight":0,"millitimestamp":1453149058599,"inputs":[{"amount":26268275436,"address":"3d956f129c08ac413025be3f6e47e3fb26461df35c9ccaf2fe4d53373e52536b","useraddress":"FA2SCdYb8iBYmMcmeUjHB8NhKx6DqH3wDovkumgbKt4oNkD3TJMg"}],"outputs":[{"amount":26267184636,"address":"ccf82cf94557f08a6859d8bf4a9b3ce361d0abae1e3bf5136b24638b74d32bc6","useraddress":"FA3XME5vdcjG8jPT188UFkum9BeAJJLgwyCkGB12QLsDA2qQaBET"}],"outecs":[],"rcds":["016664074524dd6a58e6593780717233b56d381a6798e5ee5ba75564bde589a6bf"],"sigblocks":[{"signatures":["efdab088b50d56ea2dfd4f600d5727a06cd7e9f3c353288e6898723ea32f4f044d27a80a199cfefec06cf53e18ea863b05b1075001d592b913e7f32c3d3f2204"]}]}`
+
+	tx := new(FactoidTransaction)
+	if err := json.Unmarshal([]byte(js), tx); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := json.Marshal(tx); err != nil || string(p) != js {
+		t.Errorf("expected:%s\nrecieved:%s %v", js, p, err)
+	}
+	if txid, err := tx.ComputeTxID(); err != nil || txid != tx.TxID {
+		t.Errorf("expected txid %s, recieved %s %v", tx.TxID, txid, err)
+	}
+	if fee, err := tx.FeesPaid(); err != nil || fee != 1090800 {
+		t.Errorf("expected fee 1090800, recieved %d %v", fee, err)
+	}
+	// the fee paid matches the fee calculated at the rate of the time
+	if fee, err := tx.CalculateFee(90900); err != nil || fee != 1090800 {
+		t.Errorf("expected calculated fee 1090800, recieved %d %v", fee, err)
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		t.Error(err)
+	}
+
+	p, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx2 := new(FactoidTransaction)
+	if err := tx2.UnmarshalBinary(p); err != nil {
+		t.Fatal(err)
+	}
+	if tx2.String() != tx.String() {
+		t.Errorf("expected:%s\nrecieved:%s", tx, tx2)
+	}
+	if q, _ := tx2.MarshalBinary(); !bytes.Equal(p, q) {
+		t.Errorf("expected:%x\nrecieved:%x", p, q)
+	}
+
+	tx2.Outputs[0].Amount++
+	if err := tx2.VerifySignatures(); err != ErrInvalidSig {
+		t.Errorf("expected %v, recieved %v", ErrInvalidSig, err)
+	}
+	in, err := tx2.TotalInputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx2.Outputs[0].Amount = in + 1
+	if _, err := tx2.FeesPaid(); err != ErrInsufficientFee {
+		t.Errorf("expected %v, recieved %v", ErrInsufficientFee, err)
+	}
+	tx2.Outputs[0].Amount = math.MaxUint64
+	ecs := tx2.ECOutputs
+	tx2.ECOutputs = append(append([]*TransAddress{}, ecs...), &TransAddress{Amount: 1})
+	if _, err := tx2.FeesPaid(); err != ErrAmountOverflow {
+		t.Errorf("expected %v, recieved %v", ErrAmountOverflow, err)
+	}
+	tx2.ECOutputs = ecs
+	tx2.Inputs[0].Address = tx2.Outputs[0].Address
+	if err := tx2.VerifySignatures(); err != ErrInvalidRCD {
+		t.Errorf("expected %v, recieved %v", ErrInvalidRCD, err)
+	}
+}
+
+func TestFactoidTransactionBuilder(t *testing.T) {
+	const (
+		fs     = "Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj"
+		ec     = "EC1m9mouvUQeEidmqpUYpYtXg8fvTYi6GNHaKg8KMLbdMBrFfmUa"
+		ecRate = 1000
+	)
+
+	from, err := GetFactoidAddress(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := MakeFactoidAddress(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fa := to.String()
+
+	tx := NewFactoidTransaction()
+	tx.AddInput(from, 300000)
+	if err := tx.AddOutput(fa, 100000); err != nil {
+		t.Error(err)
+	}
+	if err := tx.AddECOutput(ec, 200000); err != nil {
+		t.Error(err)
+	}
+	if err := tx.AddOutput(ec, 1); err != ErrInvalidAddress {
+		t.Errorf("expected %v, recieved %v", ErrInvalidAddress, err)
+	}
+	if err := tx.AddFee(fa, ecRate); err != ErrMissingInput {
+		t.Errorf("expected %v, recieved %v", ErrMissingInput, err)
+	}
+
+	// 1 KiB, 1 signature and 2 outputs
+	if fee, err := tx.CalculateFee(ecRate); err != nil || fee != 13000 {
+		t.Errorf("expected fee 13000, recieved %d %v", fee, err)
+	}
+	if err := tx.AddFee(from.String(), ecRate); err != nil {
+		t.Error(err)
+	}
+	if fee, err := tx.FeesPaid(); err != nil || fee != 13000 {
+		t.Errorf("expected fee paid 13000, recieved %d %v", fee, err)
+	}
+
+	if _, err := tx.Compose(); err != ErrMissingRCD {
+		t.Errorf("expected %v, recieved %v", ErrMissingRCD, err)
+	}
+	if err := tx.Sign(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		t.Error(err)
+	}
+	// the secret keys are not kept after signing
+	if err := tx.Sign(); err != ErrMissingKey {
+		t.Errorf("expected %v, recieved %v", ErrMissingKey, err)
+	}
+
+	h, err := tx.Compose()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := hex.DecodeString(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx2 := new(FactoidTransaction)
+	if err := tx2.UnmarshalBinary(p); err != nil {
+		t.Fatal(err)
+	}
+	if tx2.TxID != tx.TxID || tx2.String() != tx.String() {
+		t.Errorf("expected:%s\nrecieved:%s", tx, tx2)
+	}
+	if err := tx2.VerifySignatures(); err != nil {
+		t.Error(err)
+	}
+	if err := tx2.Sign(); err != ErrMissingKey {
+		t.Errorf("expected %v, recieved %v", ErrMissingKey, err)
+	}
+
+	// changing the Transaction removes the signatures
+	if err := tx.SubFee(fa, ecRate); err != nil {
+		t.Error(err)
+	}
+	if tx.Outputs[0].Amount != 87000 || tx.TxID != "" {
+		t.Errorf("expected output 87000 and no TxID, recieved %d %q",
+			tx.Outputs[0].Amount, tx.TxID)
+	}
+	if err := tx.VerifySignatures(); err != ErrMissingRCD {
+		t.Errorf("expected %v, recieved %v", ErrMissingRCD, err)
+	}
+}
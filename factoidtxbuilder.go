@@ -0,0 +1,186 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"encoding/hex"
+	"errors"
+	"time"
+
+	ed "github.com/FactomProject/ed25519"
+)
+
+// Factoid Transaction building errors
+var (
+	ErrMissingInput  = errors.New("factoid transaction has no input for the address")
+	ErrMissingOutput = errors.New("factoid transaction has no output for the address")
+	ErrMissingKey    = errors.New("factoid transaction input has no secret key to sign with")
+	ErrFeeTooLarge   = errors.New("factoid transaction fee is larger than the amount")
+)
+
+// NewFactoidTransaction returns an empty Factoid Transaction timestamped with
+// the current time. Inputs and outputs may be added and the Transaction signed
+// without walletd.
+func NewFactoidTransaction() *FactoidTransaction {
+	t := new(FactoidTransaction)
+	t.MilliTimestamp = time.Now().UnixNano() / 1e6
+	return t
+}
+
+// AddInput adds an input spending amount factoshis from the Factoid Address.
+// The secret key of the address is kept until the Transaction is signed.
+func (t *FactoidTransaction) AddInput(a *FactoidAddress, amount uint64) {
+	t.Inputs = append(t.Inputs, &TransAddress{Address: a.String(), Amount: amount})
+	t.keys = append(t.keys, a)
+	t.clearSignatures()
+}
+
+// AddOutput adds an output paying amount factoshis to the public Factoid
+// Address addr.
+func (t *FactoidTransaction) AddOutput(addr string, amount uint64) error {
+	if _, err := decodePubAddress(fcPubPrefix, addr); err != nil {
+		return err
+	}
+	t.Outputs = append(t.Outputs, &TransAddress{Address: addr, Amount: amount})
+	t.clearSignatures()
+	return nil
+}
+
+// AddECOutput adds an output buying amount factoshis worth of Entry Credits
+// for the public Entry Credit Address addr.
+func (t *FactoidTransaction) AddECOutput(addr string, amount uint64) error {
+	if _, err := decodePubAddress(ecPubPrefix, addr); err != nil {
+		return err
+	}
+	t.ECOutputs = append(t.ECOutputs, &TransAddress{Address: addr, Amount: amount})
+	t.clearSignatures()
+	return nil
+}
+
+// CalculateFee returns the fee in factoshis for the Transaction at the Entry
+// Credit rate ecRate (factoshis per Entry Credit). The fee is 1 Entry Credit
+// for each KiB of the signed binary Transaction, 10 for each input signature,
+// and 1 for each output and Entry Credit output. The Transaction need not be
+// signed yet.
+func (t *FactoidTransaction) CalculateFee(ecRate uint64) (uint64, error) {
+	p, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return 0, err
+	}
+	size := len(p) + len(t.Inputs)*(1+ed.PublicKeySize+ed.SignatureSize)
+
+	ec := uint64(size+1023) / 1024
+	ec += 10 * uint64(len(t.Inputs))
+	ec += uint64(len(t.Outputs) + len(t.ECOutputs))
+	return ec * ecRate, nil
+}
+
+// AddFee adds the fee at the Entry Credit rate ecRate to the input from the
+// Factoid Address addr.
+func (t *FactoidTransaction) AddFee(addr string, ecRate uint64) error {
+	var in *TransAddress
+	for _, v := range t.Inputs {
+		if v.Address == addr {
+			in = v
+			break
+		}
+	}
+	if in == nil {
+		return ErrMissingInput
+	}
+	t.clearSignatures()
+
+	// a larger amount may lengthen the Transaction and so raise the fee
+	base := in.Amount
+	for {
+		fee, err := t.CalculateFee(ecRate)
+		if err != nil {
+			in.Amount = base
+			return err
+		}
+		if in.Amount == base+fee {
+			return nil
+		}
+		in.Amount = base + fee
+	}
+}
+
+// SubFee subtracts the fee at the Entry Credit rate ecRate from the output to
+// the Factoid Address addr.
+func (t *FactoidTransaction) SubFee(addr string, ecRate uint64) error {
+	var out *TransAddress
+	for _, v := range t.Outputs {
+		if v.Address == addr {
+			out = v
+			break
+		}
+	}
+	if out == nil {
+		return ErrMissingOutput
+	}
+	t.clearSignatures()
+
+	fee, err := t.CalculateFee(ecRate)
+	if err != nil {
+		return err
+	}
+	if fee > out.Amount {
+		return ErrFeeTooLarge
+	}
+	out.Amount -= fee
+	return nil
+}
+
+// Sign signs each input with the secret key of the Factoid Address it was
+// added with, and sets the RCDs, Signatures and TxID of the Transaction. The
+// secret keys are then dropped from the Transaction, so a Transaction changed
+// after it is signed must be built again to be signed.
+func (t *FactoidTransaction) Sign() error {
+	if len(t.keys) != len(t.Inputs) {
+		return ErrMissingKey
+	}
+	msg, err := t.MarshalLedgerBinary()
+	if err != nil {
+		return err
+	}
+
+	rcds := make([]*RCD1, 0, len(t.Inputs))
+	sigs := make([][]byte, 0, len(t.Inputs))
+	for _, a := range t.keys {
+		rcd, ok := a.RCD.(*RCD1)
+		if !ok {
+			return ErrUnsupportedRCD
+		}
+		if a.Sec == nil {
+			return ErrMissingKey
+		}
+		rcds = append(rcds, rcd)
+		sigs = append(sigs, ed.Sign(a.SecFixed(), msg)[:])
+	}
+	t.RCDs = rcds
+	t.Signatures = sigs
+	t.keys = nil
+
+	t.TxID, err = t.ComputeTxID()
+	return err
+}
+
+// Compose returns the hex encoded binary of the signed Transaction, as sent to
+// factomd by FactoidSubmit.
+func (t *FactoidTransaction) Compose() (string, error) {
+	p, err := t.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(p), nil
+}
+
+// clearSignatures removes the RCDs and Signatures made before the Transaction
+// was changed.
+func (t *FactoidTransaction) clearSignatures() {
+	t.TxID = ""
+	t.RCDs = nil
+	t.Signatures = nil
+}
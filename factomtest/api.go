@@ -0,0 +1,964 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factomtest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/FactomProject/btcutil/base58"
+	ed "github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+)
+
+// Version is the factomd version reported by the Server.
+const Version = "factomtest"
+
+type method func(s *Server, params json.RawMessage) (interface{}, *factom.JSONError)
+
+// methods are the factomd API methods answered by the Server.
+var methods map[string]method
+
+func init() {
+	methods = map[string]method{
+		"ablock-by-height":      (*Server).ablockByHeight,
+		"ack":                   (*Server).ack,
+		"admin-block":           (*Server).adminBlock,
+		"authorities":           (*Server).authorities,
+		"chain-head":            (*Server).chainHead,
+		"commit-chain":          (*Server).commitChain,
+		"commit-entry":          (*Server).commitEntry,
+		"current-minute":        (*Server).currentMinute,
+		"dblock-by-height":      (*Server).dblockByHeight,
+		"directory-block":       (*Server).directoryBlock,
+		"directory-block-head":  (*Server).directoryBlockHead,
+		"ecblock-by-height":     (*Server).ecblockByHeight,
+		"entry":                 (*Server).entry,
+		"entry-block":           (*Server).entryBlock,
+		"entry-credit-balance":  (*Server).ecBalance,
+		"entry-credit-rate":     (*Server).ecRateMethod,
+		"entrycredit-block":     (*Server).entryCreditBlock,
+		"factoid-balance":       (*Server).factoidBalance,
+		"factoid-block":         (*Server).factoidBlock,
+		"factoid-submit":        (*Server).factoidSubmit,
+		"fblock-by-height":      (*Server).fblockByHeight,
+		"heights":               (*Server).heights,
+		"multiple-ec-balances":  (*Server).multipleECBalances,
+		"multiple-fct-balances": (*Server).multipleFCTBalances,
+		"pending-entries":       (*Server).pendingEntries,
+		"pending-transactions":  (*Server).pendingTransactions,
+		"properties":            (*Server).properties,
+		"raw-data":              (*Server).rawData,
+		"receipt":               (*Server).receipt,
+		"reveal-chain":          (*Server).revealChain,
+		"reveal-entry":          (*Server).revealEntry,
+		"send-raw-message":      (*Server).sendRawMessage,
+		"transaction":           (*Server).transaction,
+	}
+}
+
+// invalidParams returns an Invalid params error with a reason.
+func invalidParams(format string, a ...interface{}) *factom.JSONError {
+	return factom.NewJSONError(factom.ErrInvalidParams.Code, factom.ErrInvalidParams.Message, fmt.Sprintf(format, a...))
+}
+
+// decodeParams decodes the request params into v.
+func decodeParams(params json.RawMessage, v interface{}) *factom.JSONError {
+	if err := json.Unmarshal(params, v); err != nil {
+		return invalidParams("%v", err)
+	}
+	return nil
+}
+
+func (s *Server) heights(params json.RawMessage) (interface{}, *factom.JSONError) {
+	h := s.height - 1
+	return &factom.HeightsResponse{
+		DirectoryBlockHeight: h,
+		LeaderHeight:         s.height,
+		EntryBlockHeight:     h,
+		EntryHeight:          h,
+	}, nil
+}
+
+func (s *Server) currentMinute(params json.RawMessage) (interface{}, *factom.JSONError) {
+	return &factom.CurrentMinuteInfo{
+		LeaderHeight:            s.height,
+		DirectoryBlockHeight:    s.height - 1,
+		Minute:                  int64(s.minute),
+		CurrentBlockStartTime:   s.start.UnixNano(),
+		CurrentMinuteStartTime:  s.now().UnixNano(),
+		CurrentTime:             s.now().UnixNano(),
+		DirectoryBlockInSeconds: 600,
+	}, nil
+}
+
+func (s *Server) properties(params json.RawMessage) (interface{}, *factom.JSONError) {
+	return map[string]string{
+		"factomdversion":    Version,
+		"factomdapiversion": "2.0",
+	}, nil
+}
+
+func (s *Server) authorities(params json.RawMessage) (interface{}, *factom.JSONError) {
+	auths := s.auths
+	if auths == nil {
+		auths = make([]*factom.Authority, 0)
+	}
+	return map[string]interface{}{"authorities": auths}, nil
+}
+
+func (s *Server) ecRateMethod(params json.RawMessage) (interface{}, *factom.JSONError) {
+	return map[string]uint64{"rate": s.ecRate}, nil
+}
+
+func (s *Server) factoidBalance(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Address string `json:"address"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	if factom.AddressStringType(p.Address) != factom.FactoidPub {
+		return nil, invalidParams("invalid address %s", p.Address)
+	}
+	return map[string]uint64{"balance": s.fct[p.Address]}, nil
+}
+
+func (s *Server) ecBalance(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Address string `json:"address"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	if factom.AddressStringType(p.Address) != factom.ECPub {
+		return nil, invalidParams("invalid address %s", p.Address)
+	}
+	return map[string]uint64{"balance": s.ec[p.Address]}, nil
+}
+
+func (s *Server) multipleFCTBalances(params json.RawMessage) (interface{}, *factom.JSONError) {
+	return s.multipleBalances(params, s.fct, func(addr string) bool {
+		return factom.AddressStringType(addr) == factom.FactoidPub
+	})
+}
+
+func (s *Server) multipleECBalances(params json.RawMessage) (interface{}, *factom.JSONError) {
+	return s.multipleBalances(params, s.ec, func(addr string) bool {
+		return factom.AddressStringType(addr) == factom.ECPub
+	})
+}
+
+// multipleBalances reports the balances of a list of addresses. The Server
+// applies Transactions and commits as they are accepted, so the saved and
+// acknowledged balances are the same.
+func (s *Server) multipleBalances(params json.RawMessage, balances map[string]uint64, valid func(string) bool) (interface{}, *factom.JSONError) {
+	type balance struct {
+		Ack   uint64 `json:"ack"`
+		Saved uint64 `json:"saved"`
+		Err   string `json:"err"`
+	}
+	p := new(struct {
+		Addresses []string `json:"addresses"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+
+	bs := make([]balance, 0, len(p.Addresses))
+	for _, addr := range p.Addresses {
+		if !valid(addr) {
+			bs = append(bs, balance{Err: "Error decoding address"})
+			continue
+		}
+		bs = append(bs, balance{Ack: balances[addr], Saved: balances[addr]})
+	}
+	return map[string]interface{}{
+		"currentheight":   s.height,
+		"lastsavedheight": s.height - 1,
+		"balances":        bs,
+	}, nil
+}
+
+// commitTxID returns the TxID of a commit message, the hash of the data
+// before the EC public key and signature.
+func commitTxID(msg []byte) string {
+	h := sha256.Sum256(msg[:len(msg)-32-64])
+	return hex.EncodeToString(h[:])
+}
+
+// payCommit checks a commit message and charges its Entry Credits.
+func (s *Server) payCommit(msg []byte, entryHash, ecPub string, credits int, verify func() error) (*commit, *factom.JSONError) {
+	if err := verify(); err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	if c, ok := s.commits[entryHash]; ok && !c.revealed {
+		return nil, factom.ErrRepeatedCommit
+	}
+	addr := ecAddress(ecPub)
+	if s.ec[addr] < uint64(credits) {
+		return nil, invalidParams("insufficient entry credit balance")
+	}
+	s.ec[addr] -= uint64(credits)
+
+	c := &commit{txid: commitTxID(msg), entryHash: entryHash, height: s.height}
+	s.commits[entryHash] = c
+	s.commitTx[c.txid] = c
+	return c, nil
+}
+
+func (s *Server) commitEntry(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Message string `json:"message"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	ec, err := factom.DecodeEntryCommit(p.Message)
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	msg, _ := hex.DecodeString(p.Message)
+	c, jerr := s.payCommit(msg, ec.EntryHash, ec.ECPubKey, ec.Credits, ec.VerifySignature)
+	if jerr != nil {
+		return nil, jerr
+	}
+	c.entry = ec
+	s.ecbs = append(s.ecbs, &ecbEntry{e: ec, minute: s.minute})
+
+	return map[string]string{
+		"message":   "Entry Commit Success",
+		"txid":      c.txid,
+		"entryhash": ec.EntryHash,
+	}, nil
+}
+
+func (s *Server) commitChain(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Message string `json:"message"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	cc, err := factom.DecodeChainCommit(p.Message)
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	msg, _ := hex.DecodeString(p.Message)
+	c, jerr := s.payCommit(msg, cc.EntryHash, cc.ECPubKey, cc.Credits, cc.VerifySignature)
+	if jerr != nil {
+		return nil, jerr
+	}
+	c.chain = cc
+	s.ecbs = append(s.ecbs, &ecbEntry{e: cc, minute: s.minute})
+
+	return map[string]string{
+		"message":     "Chain Commit Success",
+		"txid":        c.txid,
+		"entryhash":   cc.EntryHash,
+		"chainidhash": cc.ChainIDHash,
+	}, nil
+}
+
+// reveal checks a revealed Entry against its commit and adds it to the
+// current block.
+func (s *Server) reveal(params json.RawMessage, chain bool) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Entry string `json:"entry"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(p.Entry)
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	e := new(factom.Entry)
+	if err := e.UnmarshalBinary(data); err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	hash := hex.EncodeToString(e.Hash())
+
+	c, ok := s.commits[hash]
+	if !ok || c.revealed {
+		return nil, invalidParams("no commit for entry %s", hash)
+	}
+	_, exists := s.chains[e.ChainID]
+	exists = exists || s.creating[e.ChainID]
+	switch {
+	case c.chain != nil:
+		if !chain {
+			return nil, invalidParams("entry %s was committed as a new chain", hash)
+		}
+		if exists {
+			return nil, invalidParams("chain %s already exists", e.ChainID)
+		}
+		if err := c.chain.VerifyChain(factom.NewChain(e)); err != nil {
+			return nil, invalidParams("%v", err)
+		}
+		if e.ChainID != factom.ChainIDFromFields(e.ExtIDs) {
+			return nil, invalidParams("invalid chainid %s", e.ChainID)
+		}
+		s.creating[e.ChainID] = true
+	default:
+		if !exists {
+			return nil, factom.ErrMissingChainHead
+		}
+		if err := c.entry.VerifyEntry(e); err != nil {
+			return nil, invalidParams("%v", err)
+		}
+	}
+
+	c.revealed = true
+	s.stored[hash] = e
+	s.entries = append(s.entries, &pendingEntry{entry: e, hash: hash, minute: s.minute, commit: c})
+
+	return map[string]string{
+		"message":   "Entry Reveal Success",
+		"entryhash": hash,
+		"chainid":   e.ChainID,
+	}, nil
+}
+
+func (s *Server) revealEntry(params json.RawMessage) (interface{}, *factom.JSONError) {
+	return s.reveal(params, false)
+}
+
+func (s *Server) revealChain(params json.RawMessage) (interface{}, *factom.JSONError) {
+	return s.reveal(params, true)
+}
+
+func (s *Server) factoidSubmit(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Transaction string `json:"transaction"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(p.Transaction)
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	tx := new(factom.FactoidTransaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	if len(tx.Inputs) == 0 {
+		return nil, invalidParams("transaction has no inputs")
+	}
+	if _, ok := s.txids[tx.TxID]; ok {
+		return nil, invalidParams("repeated transaction %s", tx.TxID)
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	paid, err := tx.FeesPaid()
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	fee, err := tx.CalculateFee(s.ecRate)
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	if paid < fee {
+		return nil, invalidParams("insufficient fee: paid %d of %d", paid, fee)
+	}
+	spend := make(map[string]uint64)
+	for _, in := range tx.Inputs {
+		spend[in.Address] += in.Amount
+	}
+	for addr, amount := range spend {
+		if s.fct[addr] < amount {
+			return nil, invalidParams("insufficient balance for %s", addr)
+		}
+	}
+
+	for addr, amount := range spend {
+		s.fct[addr] -= amount
+	}
+	for _, out := range tx.Outputs {
+		s.fct[out.Address] += out.Amount
+	}
+	for i, out := range tx.ECOutputs {
+		credits := out.Amount / s.ecRate
+		s.ec[out.Address] += credits
+		ecb := &factom.ECBalanceIncrease{TXID: tx.TxID, Index: uint64(i), NumEC: credits}
+		ecb.ECPubKey = addressKey(out.Address)
+		s.ecbs = append(s.ecbs, &ecbEntry{e: ecb, minute: s.minute})
+	}
+
+	tx.Minute = s.minute
+	s.txs = append(s.txs, tx)
+	s.txids[tx.TxID] = &txState{tx: tx, height: s.height}
+	s.raw[tx.TxID] = data
+
+	return map[string]string{
+		"message": "Successfully submitted the transaction",
+		"txid":    tx.TxID,
+	}, nil
+}
+
+// sendRawMessage accepts a hex encoded factomd message. The Server does not
+// decode or act on the message.
+func (s *Server) sendRawMessage(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Message string `json:"message"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	if _, err := hex.DecodeString(p.Message); err != nil || len(p.Message) == 0 {
+		return nil, invalidParams("invalid message %q", p.Message)
+	}
+	return map[string]string{"message": "Successfully sent the message"}, nil
+}
+
+// ecAddress returns the public Entry Credit Address of a hex public key.
+func ecAddress(pub string) string {
+	a := &factom.ECAddress{Pub: new([ed.PublicKeySize]byte)}
+	p, _ := hex.DecodeString(pub)
+	copy(a.Pub[:], p)
+	return a.PubString()
+}
+
+// addressKey returns the hex public key of a public Entry Credit Address, or
+// the hex RCD Hash of a public Factoid Address.
+func addressKey(addr string) string {
+	p := base58.Decode(addr)
+	return hex.EncodeToString(p[factom.PrefixLength:factom.BodyLength])
+}
+
+func (s *Server) ack(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Hash    string `json:"hash"`
+		ChainID string `json:"chainid"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+
+	switch p.ChainID {
+	case "f":
+		st := &factom.FactoidTxStatus{TxID: p.Hash}
+		st.Status = factom.StatusUnknown
+		if tx, ok := s.txids[p.Hash]; ok {
+			st.Status = txStatus(tx.confirmed)
+		}
+		return st, nil
+	case "c":
+		st := &factom.EntryStatus{CommitTxID: p.Hash}
+		st.CommitData.Status = factom.StatusUnknown
+		st.EntryData.Status = factom.StatusUnknown
+		if c, ok := s.commitTx[p.Hash]; ok {
+			s.commitStatus(st, c)
+		}
+		return st, nil
+	default:
+		st := &factom.EntryStatus{EntryHash: p.Hash}
+		st.CommitData.Status = factom.StatusUnknown
+		st.EntryData.Status = factom.StatusUnknown
+		if c, ok := s.commits[p.Hash]; ok {
+			s.commitStatus(st, c)
+		}
+		return st, nil
+	}
+}
+
+func (s *Server) commitStatus(st *factom.EntryStatus, c *commit) {
+	st.CommitTxID = c.txid
+	st.EntryHash = c.entryHash
+	st.CommitData.Status = txStatus(c.confirmed)
+	if c.revealed {
+		st.EntryData.Status = txStatus(c.entryConfirmed)
+	}
+}
+
+func txStatus(confirmed bool) string {
+	if confirmed {
+		return factom.StatusDBlockConfirmed
+	}
+	return factom.StatusTransactionACK
+}
+
+func (s *Server) pendingEntries(params json.RawMessage) (interface{}, *factom.JSONError) {
+	type pendingEntry struct {
+		EntryHash string `json:"entryhash"`
+		ChainID   string `json:"chainid"`
+		Status    string `json:"status"`
+	}
+	pending := make([]pendingEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		pending = append(pending, pendingEntry{e.hash, e.entry.ChainID, factom.StatusTransactionACK})
+	}
+	return pending, nil
+}
+
+func (s *Server) pendingTransactions(params json.RawMessage) (interface{}, *factom.JSONError) {
+	type transAddress struct {
+		Amount      uint64 `json:"amount"`
+		Address     string `json:"address"`
+		UserAddress string `json:"useraddress"`
+	}
+	type pendingTransaction struct {
+		TransactionID string         `json:"transactionid"`
+		DBHeight      int64          `json:"dbheight"`
+		Status        string         `json:"status"`
+		Fees          uint64         `json:"fees"`
+		Inputs        []transAddress `json:"inputs"`
+		Outputs       []transAddress `json:"outputs"`
+		ECOutputs     []transAddress `json:"ecoutputs"`
+	}
+	transAddresses := func(as []*factom.TransAddress) []transAddress {
+		js := make([]transAddress, 0, len(as))
+		for _, a := range as {
+			js = append(js, transAddress{a.Amount, addressKey(a.Address), a.Address})
+		}
+		return js
+	}
+
+	pending := make([]pendingTransaction, 0, len(s.txs))
+	for _, tx := range s.txs {
+		fee, _ := tx.FeesPaid()
+		pending = append(pending, pendingTransaction{
+			TransactionID: tx.TxID,
+			DBHeight:      s.height,
+			Status:        factom.StatusTransactionACK,
+			Fees:          fee,
+			Inputs:        transAddresses(tx.Inputs),
+			Outputs:       transAddresses(tx.Outputs),
+			ECOutputs:     transAddresses(tx.ECOutputs),
+		})
+	}
+	return pending, nil
+}
+
+// transaction looks up a Factoid Transaction or commit by TxID, or an Entry
+// by Entry Hash, and the blocks that include it once it is confirmed.
+func (s *Server) transaction(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Hash string `json:"hash"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+
+	resp := new(factom.TransactionResponse)
+	height := int64(-1)
+	if tx, ok := s.txids[p.Hash]; ok {
+		resp.FactoidTransaction = tx.tx
+		if tx.confirmed {
+			height = tx.height
+			resp.IncludedInTransactionBlock = s.fblocks[height].KeyMR
+		}
+	} else if c, ok := s.commitTx[p.Hash]; ok {
+		if c.chain != nil {
+			resp.ECTranasction = c.chain
+		} else {
+			resp.ECTranasction = c.entry
+		}
+		if c.confirmed {
+			height = c.height
+			resp.IncludedInTransactionBlock = s.ecblocks[height].HeaderHash
+		}
+	} else if e, ok := s.stored[p.Hash]; ok {
+		resp.Entry = e
+		if keymr, ok := s.ebByHash[p.Hash]; ok {
+			height = s.eblocks[keymr].Header.DBHeight
+			resp.IncludedInTransactionBlock = keymr
+		}
+	} else {
+		return nil, factom.ErrEntryNotFound
+	}
+
+	if height >= 0 {
+		resp.IncludedInDirectoryBlock = s.dblocks[height].KeyMR
+		resp.IncludedInDirectoryBlockHeight = height
+	}
+	return resp, nil
+}
+
+func (s *Server) chainHead(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		ChainID string `json:"chainid"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+
+	inPL := false
+	for _, e := range s.entries {
+		if e.entry.ChainID == p.ChainID {
+			inPL = true
+			break
+		}
+	}
+	head := ""
+	if c, ok := s.chains[p.ChainID]; ok {
+		head = c.head
+	} else if !inPL {
+		return nil, factom.ErrMissingChainHead
+	}
+
+	return map[string]interface{}{
+		"chainhead":          head,
+		"chaininprocesslist": inPL,
+	}, nil
+}
+
+func (s *Server) entry(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Hash string `json:"hash"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	e, ok := s.stored[p.Hash]
+	if !ok {
+		return nil, factom.ErrEntryNotFound
+	}
+	return e, nil
+}
+
+// receipt proves that a confirmed Entry is in its Entry Block and Directory
+// Block. The Server does not anchor its blocks, so the receipt has no
+// Bitcoin transaction.
+func (s *Server) receipt(params json.RawMessage) (interface{}, *factom.JSONError) {
+	type receipt struct {
+		Entry struct {
+			EntryHash string `json:"entryhash"`
+		} `json:"entry"`
+		MerkleBranch        []merkleNode `json:"merklebranch"`
+		EntryBlockKeyMR     string       `json:"entryblockkeymr"`
+		DirectoryBlockKeyMR string       `json:"directoryblockkeymr"`
+	}
+	p := new(struct {
+		Hash string `json:"hash"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	keymr, ok := s.ebByHash[p.Hash]
+	if !ok {
+		return nil, factom.ErrReceiptCreation
+	}
+	eb := s.eblocks[keymr]
+	db := s.dblocks[eb.Header.DBHeight]
+
+	r := new(receipt)
+	r.Entry.EntryHash = p.Hash
+	r.EntryBlockKeyMR = keymr
+	r.DirectoryBlockKeyMR = db.KeyMR
+
+	// from the Entry Hash to the Entry Block BodyMR and KeyMR
+	hashes, err := eb.BodyHashes()
+	if err != nil {
+		return nil, factom.NewJSONError(factom.ErrInternal.Code, factom.ErrInternal.Message, err.Error())
+	}
+	i := 0
+	for j, h := range hashes {
+		if hex.EncodeToString(h) == p.Hash {
+			i = j
+			break
+		}
+	}
+	r.MerkleBranch = append(r.MerkleBranch, merkleBranch(hashes, i)...)
+	header := sha256.Sum256(s.raw[keymr][:32*4+4*3])
+	r.MerkleBranch = append(r.MerkleBranch, merkleNode{
+		Left:  hex.EncodeToString(header[:]),
+		Right: eb.Header.BodyMR,
+		Top:   keymr,
+	})
+
+	// from the Entry Block KeyMR to the Directory Block BodyMR and KeyMR
+	hashes = make([][]byte, 0, len(db.DBEntries))
+	for j, v := range db.DBEntries {
+		c, _ := hex.DecodeString(v.ChainID)
+		k, _ := hex.DecodeString(v.KeyMR)
+		h := sha256.Sum256(append(c, k...))
+		hashes = append(hashes, h[:])
+		if v.KeyMR == keymr {
+			i = j
+			r.MerkleBranch = append(r.MerkleBranch, merkleNode{
+				Left:  v.ChainID,
+				Right: v.KeyMR,
+				Top:   hex.EncodeToString(h[:]),
+			})
+		}
+	}
+	r.MerkleBranch = append(r.MerkleBranch, merkleBranch(hashes, i)...)
+	r.MerkleBranch = append(r.MerkleBranch, merkleNode{
+		Left:  db.HeaderHash,
+		Right: db.Header.BodyMR,
+		Top:   db.KeyMR,
+	})
+
+	return map[string]interface{}{"receipt": r}, nil
+}
+
+func (s *Server) rawData(params json.RawMessage) (interface{}, *factom.JSONError) {
+	p := new(struct {
+		Hash string `json:"hash"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	raw, ok := s.raw[p.Hash]
+	if !ok {
+		return nil, factom.ErrObjectNotFound
+	}
+	return &factom.RawData{Data: hex.EncodeToString(raw)}, nil
+}
+
+// keyMRParam decodes the keymr of a block request.
+func keyMRParam(params json.RawMessage) (string, *factom.JSONError) {
+	p := new(struct {
+		KeyMR string `json:"keymr"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return "", err
+	}
+	return p.KeyMR, nil
+}
+
+// heightParam decodes the height of a block request.
+func heightParam(params json.RawMessage) (int64, *factom.JSONError) {
+	p := new(struct {
+		Height int64 `json:"height"`
+	})
+	if err := decodeParams(params, p); err != nil {
+		return 0, err
+	}
+	return p.Height, nil
+}
+
+// blockAt returns the index of a sealed block height.
+func (s *Server) blockAt(params json.RawMessage) (int, *factom.JSONError) {
+	h, err := heightParam(params)
+	if err != nil {
+		return 0, err
+	}
+	if h < 0 || h >= int64(len(s.dblocks)) {
+		return 0, factom.ErrBlockNotFound
+	}
+	return int(h), nil
+}
+
+func (s *Server) directoryBlockHead(params json.RawMessage) (interface{}, *factom.JSONError) {
+	return map[string]string{"keymr": s.dblocks[len(s.dblocks)-1].KeyMR}, nil
+}
+
+func (s *Server) directoryBlock(params json.RawMessage) (interface{}, *factom.JSONError) {
+	keymr, jerr := keyMRParam(params)
+	if jerr != nil {
+		return nil, jerr
+	}
+	db, ok := s.blocks[keymr].(*factom.DBlock)
+	if !ok {
+		return nil, factom.ErrBlockNotFound
+	}
+
+	type header struct {
+		PrevBlockKeyMR string `json:"prevblockkeymr"`
+		SequenceNumber int64  `json:"sequencenumber"`
+		Timestamp      int64  `json:"timestamp"`
+	}
+	return &struct {
+		DBHash         string      `json:"dbhash"`
+		Header         header      `json:"header"`
+		EntryBlockList interface{} `json:"entryblocklist"`
+	}{
+		DBHash: db.DBHash,
+		Header: header{
+			PrevBlockKeyMR: db.Header.PrevKeyMR,
+			SequenceNumber: db.SequenceNumber,
+			Timestamp:      int64(db.Header.Timestamp) * 60,
+		},
+		EntryBlockList: db.DBEntries,
+	}, nil
+}
+
+func (s *Server) dblockByHeight(params json.RawMessage) (interface{}, *factom.JSONError) {
+	i, jerr := s.blockAt(params)
+	if jerr != nil {
+		return nil, jerr
+	}
+	db := s.dblocks[i]
+	return map[string]interface{}{
+		"dblock":  db,
+		"rawdata": hex.EncodeToString(s.raw[db.KeyMR]),
+	}, nil
+}
+
+func (s *Server) entryBlock(params json.RawMessage) (interface{}, *factom.JSONError) {
+	keymr, jerr := keyMRParam(params)
+	if jerr != nil {
+		return nil, jerr
+	}
+	eb, ok := s.eblocks[keymr]
+	if !ok {
+		return nil, factom.ErrBlockNotFound
+	}
+	// factomd does not return the BodyMR or PrevFullHash
+	served := *eb
+	served.Header.BodyMR = ""
+	served.Header.PrevFullHash = ""
+	return &served, nil
+}
+
+func (s *Server) adminBlock(params json.RawMessage) (interface{}, *factom.JSONError) {
+	keymr, jerr := keyMRParam(params)
+	if jerr != nil {
+		return nil, jerr
+	}
+	ab, ok := s.blocks[keymr].(*factom.ABlock)
+	if !ok {
+		return nil, factom.ErrBlockNotFound
+	}
+	return s.ablockResult(ab)
+}
+
+func (s *Server) ablockByHeight(params json.RawMessage) (interface{}, *factom.JSONError) {
+	i, jerr := s.blockAt(params)
+	if jerr != nil {
+		return nil, jerr
+	}
+	return s.ablockResult(s.ablocks[i])
+}
+
+// ablockResult returns an Admin Block in the factomd JSON form, where each
+// Entry starts with its adminidtype.
+func (s *Server) ablockResult(ab *factom.ABlock) (interface{}, *factom.JSONError) {
+	entries := make([]json.RawMessage, 0, len(ab.ABEntries))
+	for _, v := range ab.ABEntries {
+		p, err := json.Marshal(v)
+		if err != nil || len(p) < 2 || p[0] != '{' {
+			return nil, factom.NewJSONError(factom.ErrInternal.Code, factom.ErrInternal.Message, fmt.Sprint(err))
+		}
+		buf := new(bytes.Buffer)
+		fmt.Fprintf(buf, `{"adminidtype":%d`, v.Type())
+		if p[1] != '}' {
+			buf.WriteByte(',')
+		}
+		buf.Write(p[1:])
+		entries = append(entries, buf.Bytes())
+	}
+
+	header := map[string]interface{}{
+		"prevbackrefhash": ab.PrevBackreferenceHash,
+		"dbheight":        ab.DBHeight,
+	}
+	return map[string]interface{}{
+		"ablock": map[string]interface{}{
+			"header":            header,
+			"backreferencehash": ab.BackReferenceHash,
+			"lookuphash":        ab.LookupHash,
+			"abentries":         entries,
+		},
+		"rawdata": hex.EncodeToString(s.raw[ab.LookupHash]),
+	}, nil
+}
+
+func (s *Server) entryCreditBlock(params json.RawMessage) (interface{}, *factom.JSONError) {
+	keymr, jerr := keyMRParam(params)
+	if jerr != nil {
+		return nil, jerr
+	}
+	ecb, ok := s.blocks[keymr].(*factom.ECBlock)
+	if !ok {
+		return nil, factom.ErrBlockNotFound
+	}
+	return s.ecblockResult(ecb), nil
+}
+
+func (s *Server) ecblockByHeight(params json.RawMessage) (interface{}, *factom.JSONError) {
+	i, jerr := s.blockAt(params)
+	if jerr != nil {
+		return nil, jerr
+	}
+	return s.ecblockResult(s.ecblocks[i]), nil
+}
+
+// ecblockResult returns an Entry Credit Block in the factomd JSON form, where
+// the commit millitimes are hex encoded.
+func (s *Server) ecblockResult(ecb *factom.ECBlock) interface{} {
+	entries := make([]interface{}, 0, len(ecb.Entries))
+	for _, v := range ecb.Entries {
+		switch v := v.(type) {
+		case *factom.ECChainCommit:
+			entries = append(entries, map[string]interface{}{
+				"version":     v.Version,
+				"millitime":   milliTimeHex(v.MilliTime),
+				"chainidhash": v.ChainIDHash,
+				"weld":        v.Weld,
+				"entryhash":   v.EntryHash,
+				"credits":     v.Credits,
+				"ecpubkey":    v.ECPubKey,
+				"sig":         v.Sig,
+			})
+		case *factom.ECEntryCommit:
+			entries = append(entries, map[string]interface{}{
+				"version":   v.Version,
+				"millitime": milliTimeHex(v.MilliTime),
+				"entryhash": v.EntryHash,
+				"credits":   v.Credits,
+				"ecpubkey":  v.ECPubKey,
+				"sig":       v.Sig,
+			})
+		default:
+			entries = append(entries, v)
+		}
+	}
+
+	return map[string]interface{}{
+		"ecblock": map[string]interface{}{
+			"header": map[string]interface{}{
+				"bodyhash":       ecb.Header.BodyHash,
+				"prevheaderhash": ecb.Header.PrevHeaderHash,
+				"prevfullhash":   ecb.Header.PrevFullHash,
+				"dbheight":       ecb.Header.DBHeight,
+			},
+			"headerhash": ecb.HeaderHash,
+			"fullhash":   ecb.FullHash,
+			"body":       map[string]interface{}{"entries": entries},
+		},
+		"rawdata": hex.EncodeToString(s.raw[ecb.HeaderHash]),
+	}
+}
+
+// milliTimeHex returns the 6 byte hex encoding of a millisecond timestamp.
+func milliTimeHex(m int64) string {
+	return strings.TrimPrefix(fmt.Sprintf("%016x", m), "0000")
+}
+
+func (s *Server) factoidBlock(params json.RawMessage) (interface{}, *factom.JSONError) {
+	keymr, jerr := keyMRParam(params)
+	if jerr != nil {
+		return nil, jerr
+	}
+	fb, ok := s.blocks[keymr].(*factom.FBlock)
+	if !ok {
+		return nil, factom.ErrBlockNotFound
+	}
+	return s.fblockResult(fb), nil
+}
+
+func (s *Server) fblockByHeight(params json.RawMessage) (interface{}, *factom.JSONError) {
+	i, jerr := s.blockAt(params)
+	if jerr != nil {
+		return nil, jerr
+	}
+	return s.fblockResult(s.fblocks[i]), nil
+}
+
+func (s *Server) fblockResult(fb *factom.FBlock) interface{} {
+	return map[string]interface{}{
+		"fblock":  fb,
+		"rawdata": hex.EncodeToString(s.raw[fb.KeyMR]),
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factomtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+// seal builds the blocks of the current height from the commits, reveals and
+// Transactions made since the last block, adds them to the blockchain, and
+// starts the next height.
+func (s *Server) seal() {
+	if err := s.buildBlocks(); err != nil {
+		// the Server only builds blocks from data it has already checked
+		panic(fmt.Sprintf("factomtest: building block %d: %v", s.height, err))
+	}
+
+	for _, c := range s.commitTx {
+		c.confirmed = true
+	}
+	for _, e := range s.entries {
+		e.commit.entryConfirmed = true
+	}
+	for _, tx := range s.txs {
+		s.txids[tx.TxID].confirmed = true
+	}
+
+	s.height++
+	s.minute = 0
+	s.start = s.start.Add(10 * time.Minute)
+	s.entries = nil
+	s.txs = nil
+	s.ecbs = nil
+	s.abs = nil
+	s.creating = make(map[string]bool)
+}
+
+func (s *Server) buildBlocks() error {
+	ts := s.start.Unix()
+	type dbEntry struct {
+		ChainID string `json:"chainid"`
+		KeyMR   string `json:"keymr"`
+	}
+	dbEntries := make([]dbEntry, 0, 3)
+
+	ab, err := s.buildABlock()
+	if err != nil {
+		return err
+	}
+	dbEntries = append(dbEntries, dbEntry{factom.AdminChainID, ab.LookupHash})
+
+	ecb, err := s.buildECBlock()
+	if err != nil {
+		return err
+	}
+	dbEntries = append(dbEntries, dbEntry{factom.ECChainID, ecb.HeaderHash})
+
+	fb, err := s.buildFBlock()
+	if err != nil {
+		return err
+	}
+	dbEntries = append(dbEntries, dbEntry{factom.FactoidChainID, fb.KeyMR})
+
+	// the Entries of each Chain in the order they were revealed
+	chainEntries := make(map[string][]*pendingEntry)
+	for _, e := range s.entries {
+		chainEntries[e.entry.ChainID] = append(chainEntries[e.entry.ChainID], e)
+	}
+	chainids := make([]string, 0, len(chainEntries))
+	for chainid := range chainEntries {
+		chainids = append(chainids, chainid)
+	}
+	sort.Strings(chainids)
+	for _, chainid := range chainids {
+		keymr, err := s.buildEBlock(chainid, chainEntries[chainid], ts)
+		if err != nil {
+			return err
+		}
+		dbEntries = append(dbEntries, dbEntry{chainid, keymr})
+	}
+
+	db := new(factom.DBlock)
+	db.Header.PrevKeyMR = factom.ZeroHash
+	db.Header.PrevFullHash = factom.ZeroHash
+	if n := len(s.dblocks); n > 0 {
+		db.Header.PrevKeyMR = s.dblocks[n-1].KeyMR
+		db.Header.PrevFullHash = s.dblocks[n-1].DBHash
+	}
+	db.Header.Timestamp = int(ts / 60)
+	db.Header.DBHeight = int(s.height)
+	db.Header.BlockCount = len(dbEntries)
+	for _, v := range dbEntries {
+		db.DBEntries = append(db.DBEntries, v)
+	}
+	if db.Header.BodyMR, err = db.ComputeBodyMR(); err != nil {
+		return err
+	}
+	raw, err := db.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := db.UnmarshalBinary(raw); err != nil {
+		return err
+	}
+	db.SequenceNumber = s.height
+	s.dblocks = append(s.dblocks, db)
+	s.blocks[db.KeyMR] = db
+	s.raw[db.KeyMR] = raw
+
+	return nil
+}
+
+func (s *Server) buildABlock() (*factom.ABlock, error) {
+	ab := new(factom.ABlock)
+	ab.PrevBackreferenceHash = factom.ZeroHash
+	if n := len(s.ablocks); n > 0 {
+		ab.PrevBackreferenceHash = s.ablocks[n-1].BackReferenceHash
+	}
+	ab.DBHeight = s.height
+	ab.ABEntries = s.abs
+
+	raw, err := ab.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if err := ab.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	s.ablocks = append(s.ablocks, ab)
+	s.blocks[ab.LookupHash] = ab
+	s.raw[ab.LookupHash] = raw
+	return ab, nil
+}
+
+func (s *Server) buildECBlock() (*factom.ECBlock, error) {
+	ecb := new(factom.ECBlock)
+	ecb.Header.PrevHeaderHash = factom.ZeroHash
+	ecb.Header.PrevFullHash = factom.ZeroHash
+	if n := len(s.ecblocks); n > 0 {
+		ecb.Header.PrevHeaderHash = s.ecblocks[n-1].HeaderHash
+		ecb.Header.PrevFullHash = s.ecblocks[n-1].FullHash
+	}
+	ecb.Header.DBHeight = s.height
+
+	// the Entries of each minute are followed by its minute number
+	ecb.Entries = append(ecb.Entries, &factom.ECServerIndexNumber{})
+	i := 0
+	for minute := 0; minute < 10; minute++ {
+		for ; i < len(s.ecbs) && s.ecbs[i].minute == minute; i++ {
+			ecb.Entries = append(ecb.Entries, s.ecbs[i].e)
+		}
+		ecb.Entries = append(ecb.Entries, &factom.ECMinuteNumber{Number: minute + 1})
+	}
+
+	raw, err := ecb.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if err := ecb.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	s.ecblocks = append(s.ecblocks, ecb)
+	s.blocks[ecb.HeaderHash] = ecb
+	s.raw[ecb.HeaderHash] = raw
+	return ecb, nil
+}
+
+func (s *Server) buildFBlock() (*factom.FBlock, error) {
+	fb := new(factom.FBlock)
+	fb.PrevKeyMR = factom.ZeroHash
+	fb.PrevLedgerKeyMR = factom.ZeroHash
+	if n := len(s.fblocks); n > 0 {
+		fb.PrevKeyMR = s.fblocks[n-1].KeyMR
+	}
+	fb.ExchRate = int64(s.ecRate)
+	fb.DBHeight = s.height
+
+	coinbase := &factom.FactoidTransaction{
+		MilliTimestamp: s.start.UnixNano() / 1e6,
+		Coinbase:       true,
+	}
+	fb.Transactions = append([]*factom.FactoidTransaction{coinbase}, s.txs...)
+
+	hashes := make([][]byte, 0, len(fb.Transactions))
+	for _, tx := range fb.Transactions {
+		p, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.Sum256(p)
+		hashes = append(hashes, h[:])
+	}
+	fb.BodyMR = hex.EncodeToString(merkleRoot(hashes))
+
+	raw, err := fb.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if err := fb.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	for _, tx := range fb.Transactions {
+		tx.BlockHeight = s.height
+	}
+	s.fblocks = append(s.fblocks, fb)
+	s.blocks[fb.KeyMR] = fb
+	s.raw[fb.KeyMR] = raw
+	return fb, nil
+}
+
+// buildEBlock adds the next Entry Block of a Chain and returns its KeyMR.
+func (s *Server) buildEBlock(chainid string, es []*pendingEntry, ts int64) (string, error) {
+	eb := new(factom.EBlock)
+	eb.Header.ChainID = chainid
+	eb.Header.PrevKeyMR = factom.ZeroHash
+	eb.Header.PrevFullHash = factom.ZeroHash
+	if c, ok := s.chains[chainid]; ok {
+		eb.Header.PrevKeyMR = c.head
+		eb.Header.PrevFullHash = c.fullHash
+		eb.Header.BlockSequenceNumber = c.seq + 1
+	}
+	eb.Header.Timestamp = ts
+	eb.Header.DBHeight = s.height
+	for _, e := range es {
+		eb.EntryList = append(eb.EntryList, factom.EBEntry{
+			EntryHash: e.hash,
+			Timestamp: ts + 60*int64(e.minute+1),
+		})
+	}
+
+	bodyMR, err := eb.ComputeBodyMR()
+	if err != nil {
+		return "", err
+	}
+	eb.Header.BodyMR = bodyMR
+	raw, err := eb.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	// KeyMR = sha256(sha256(header) + BodyMR), the header being every field
+	// before the body hashes
+	header := sha256.Sum256(raw[:32*4+4*3])
+	body, _ := hex.DecodeString(bodyMR)
+	k := sha256.Sum256(append(header[:], body...))
+	keymr := hex.EncodeToString(k[:])
+	full := sha256.Sum256(raw)
+
+	s.chains[chainid] = &chainState{
+		head:     keymr,
+		fullHash: hex.EncodeToString(full[:]),
+		seq:      eb.Header.BlockSequenceNumber,
+	}
+	s.eblocks[keymr] = eb
+	s.blocks[keymr] = eb
+	s.raw[keymr] = raw
+
+	for _, e := range es {
+		p, err := e.entry.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		s.raw[e.hash] = p
+		s.ebByHash[e.hash] = keymr
+	}
+
+	return keymr, nil
+}
+
+// merkleRoot returns the Merkle root of the hashes, pairing the last hash of
+// an odd level with itself.
+func merkleRoot(hashes [][]byte) []byte {
+	if len(hashes) == 0 {
+		return make([]byte, 32)
+	}
+	for len(hashes) > 1 {
+		next := make([][]byte, 0, (len(hashes)+1)/2)
+		for i := 0; i < len(hashes); i += 2 {
+			j := i + 1
+			if j == len(hashes) {
+				j = i
+			}
+			h := sha256.Sum256(append(append([]byte{}, hashes[i]...), hashes[j]...))
+			next = append(next, h[:])
+		}
+		hashes = next
+	}
+	return hashes[0]
+}
+
+// merkleNode is a node of the Merkle branch of a receipt.
+type merkleNode struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	Top   string `json:"top"`
+}
+
+// merkleBranch returns the nodes of the Merkle tree of the hashes from the
+// leaf at index i to the root, as built by merkleRoot.
+func merkleBranch(hashes [][]byte, i int) []merkleNode {
+	var branch []merkleNode
+	for len(hashes) > 1 {
+		next := make([][]byte, 0, (len(hashes)+1)/2)
+		for j := 0; j < len(hashes); j += 2 {
+			k := j + 1
+			if k == len(hashes) {
+				k = j
+			}
+			h := sha256.Sum256(append(append([]byte{}, hashes[j]...), hashes[k]...))
+			next = append(next, h[:])
+			if j == i || k == i {
+				branch = append(branch, merkleNode{
+					Left:  hex.EncodeToString(hashes[j]),
+					Right: hex.EncodeToString(hashes[k]),
+					Top:   hex.EncodeToString(h[:]),
+				})
+			}
+		}
+		hashes = next
+		i /= 2
+	}
+	return branch
+}
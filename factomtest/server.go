@@ -0,0 +1,335 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+// Package factomtest provides an in-memory factomd for tests and local
+// development.
+//
+// A Server answers the factomd v2 JSON RPC API used by package factom. It
+// accepts commits, reveals, and Factoid Transactions, tracks Factoid and Entry
+// Credit balances, and builds Admin, Entry Credit, Factoid, Entry, and
+// Directory Blocks on a simulated clock that only moves when the test calls
+// NextMinute or NextBlock.
+//
+//	s := factomtest.NewServer()
+//	defer s.Close()
+//	s.SetECBalance(ec.PubString(), 100)
+//	c := s.Client()
+//	c.CommitChain(ctx, chain, ec)
+//	c.RevealChain(ctx, chain)
+//	s.NextBlock()
+//
+// The blocks use the binary formats of factomd and hash to their Key Merkle
+// Roots, but the Server is not a factomd node: there is no consensus,
+// signatures of the Directory Blocks, or anchoring, and the Factoid Block
+// BodyMR is a simple Merkle root of the Transaction hashes.
+package factomtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+// DefaultECRate is the initial Entry Credit rate of a Server in factoshis per
+// Entry Credit.
+const DefaultECRate = 1000
+
+// Server is an in-memory factomd serving the v2 JSON RPC API over HTTP.
+type Server struct {
+	srv *httptest.Server
+
+	mu     sync.Mutex
+	batch  bool
+	ecRate uint64
+	auths  []*factom.Authority
+	fct    map[string]uint64 // Factoid balances by public address
+	ec     map[string]uint64 // Entry Credit balances by public address
+
+	// the block being built
+	height   int64
+	minute   int
+	start    time.Time
+	commits  map[string]*commit // latest commit by Entry Hash
+	entries  []*pendingEntry
+	txs      []*factom.FactoidTransaction
+	ecbs     []*ecbEntry
+	abs      []factom.ABEntry
+	creating map[string]bool // Chains revealed in the current block
+
+	// the blockchain
+	dblocks  []*factom.DBlock
+	ablocks  []*factom.ABlock
+	ecblocks []*factom.ECBlock
+	fblocks  []*factom.FBlock
+	eblocks  map[string]*factom.EBlock
+	chains   map[string]*chainState
+	blocks   map[string]interface{} // blocks by KeyMR
+	raw      map[string][]byte      // binary blocks, Entries and Transactions
+	stored   map[string]*factom.Entry
+	txids    map[string]*txState
+	commitTx map[string]*commit // commits by TxID
+	ebByHash map[string]string  // Entry Block KeyMRs by Entry Hash
+}
+
+// pendingEntry is an Entry revealed in the current block.
+type pendingEntry struct {
+	entry  *factom.Entry
+	hash   string
+	minute int
+	commit *commit
+}
+
+// ecbEntry is an Entry Credit Block Entry made in the current block.
+type ecbEntry struct {
+	e      factom.ECBEntry
+	minute int
+}
+
+// commit is a commit-entry or commit-chain message.
+type commit struct {
+	txid      string
+	entryHash string
+	entry     *factom.ECEntryCommit
+	chain     *factom.ECChainCommit
+	height    int64 // the height of the block including the commit
+	revealed  bool
+	confirmed bool // the commit is in an Entry Credit Block
+
+	entryConfirmed bool // the revealed Entry is in an Entry Block
+}
+
+// chainState is the last Entry Block of a Chain.
+type chainState struct {
+	head     string
+	fullHash string
+	seq      int64
+}
+
+// txState is a Factoid Transaction accepted by the Server.
+type txState struct {
+	tx        *factom.FactoidTransaction
+	height    int64 // the height of the block including the Transaction
+	confirmed bool
+}
+
+// NewServer starts a Server at Directory Block height 1, minute 0, with an
+// empty Directory Block at height 0 and no balances.
+func NewServer() *Server {
+	s := new(Server)
+	s.ecRate = DefaultECRate
+	s.fct = make(map[string]uint64)
+	s.ec = make(map[string]uint64)
+	s.start = time.Now().Truncate(time.Minute)
+	s.commits = make(map[string]*commit)
+	s.creating = make(map[string]bool)
+	s.eblocks = make(map[string]*factom.EBlock)
+	s.chains = make(map[string]*chainState)
+	s.blocks = make(map[string]interface{})
+	s.raw = make(map[string][]byte)
+	s.stored = make(map[string]*factom.Entry)
+	s.txids = make(map[string]*txState)
+	s.commitTx = make(map[string]*commit)
+	s.ebByHash = make(map[string]string)
+	s.seal()
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the base URL of the Server, which may be used as the
+// FactomdServer of an RPCConfig.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Client returns a new factom.Client for the Server.
+func (s *Server) Client() *factom.Client {
+	return factom.NewClient(&factom.RPCConfig{FactomdServer: s.srv.URL})
+}
+
+// Close shuts down the Server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// SetBatch enables or disables JSON RPC batch requests. Batches are disabled
+// by default, like factomd, which answers a batch with a Parse error.
+func (s *Server) SetBatch(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batch = enabled
+}
+
+// SetECRate sets the Entry Credit rate in factoshis per Entry Credit.
+func (s *Server) SetECRate(rate uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ecRate = rate
+}
+
+// SetFactoidBalance sets the balance in factoshis of a public Factoid
+// Address.
+func (s *Server) SetFactoidBalance(addr string, amount uint64) error {
+	if factom.AddressStringType(addr) != factom.FactoidPub {
+		return factom.ErrInvalidAddress
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fct[addr] = amount
+	return nil
+}
+
+// SetECBalance sets the balance in Entry Credits of a public Entry Credit
+// Address.
+func (s *Server) SetECBalance(addr string, credits uint64) error {
+	if factom.AddressStringType(addr) != factom.ECPub {
+		return factom.ErrInvalidAddress
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ec[addr] = credits
+	return nil
+}
+
+// SetAuthorities sets the authorities reported by the authorities method.
+// The Server does not derive them from its Admin Blocks.
+func (s *Server) SetAuthorities(authorities []*factom.Authority) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auths = authorities
+}
+
+// AddAdminEntry adds an Admin Block Entry to the current block. It returns an
+// error if the Entry cannot be encoded in an Admin Block.
+func (s *Server) AddAdminEntry(e factom.ABEntry) error {
+	ab := &factom.ABlock{PrevBackreferenceHash: factom.ZeroHash, ABEntries: []factom.ABEntry{e}}
+	raw, err := ab.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := new(factom.ABlock).UnmarshalBinary(raw); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.abs = append(s.abs, e)
+	return nil
+}
+
+// Height returns the height of the Directory Block being built.
+func (s *Server) Height() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.height
+}
+
+// Minute returns the current minute, from 0 to 9, of the block being built.
+func (s *Server) Minute() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.minute
+}
+
+// Time returns the simulated time at the start of the current minute.
+func (s *Server) Time() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now()
+}
+
+func (s *Server) now() time.Time {
+	return s.start.Add(time.Duration(s.minute) * time.Minute)
+}
+
+// NextMinute advances the simulated clock by a minute. At the end of minute 9
+// the current blocks are sealed into the blockchain.
+func (s *Server) NextMinute() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minute++
+	if s.minute == 10 {
+		s.seal()
+	}
+}
+
+// NextBlock advances the simulated clock to the start of the next block,
+// sealing the current blocks into the blockchain.
+func (s *Server) NextBlock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seal()
+}
+
+// serveHTTP answers a JSON RPC request, or a batch of requests if batches are
+// enabled.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	s.mu.Lock()
+	batch := s.batch
+	s.mu.Unlock()
+	if body = bytes.TrimSpace(body); batch && len(body) > 0 && body[0] == '[' {
+		var reqs []*factom.JSON2Request
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			json.NewEncoder(w).Encode(errorResponse(nil, factom.ErrParse))
+			return
+		}
+		resps := make([]*factom.JSON2Response, 0, len(reqs))
+		for _, req := range reqs {
+			resps = append(resps, s.handle(req))
+		}
+		json.NewEncoder(w).Encode(resps)
+		return
+	}
+
+	req := new(factom.JSON2Request)
+	if err := json.Unmarshal(body, req); err != nil {
+		json.NewEncoder(w).Encode(errorResponse(nil, factom.ErrParse))
+		return
+	}
+	json.NewEncoder(w).Encode(s.handle(req))
+}
+
+// handle answers a single JSON RPC request.
+func (s *Server) handle(req *factom.JSON2Request) *factom.JSON2Response {
+	f, ok := methods[req.Method]
+	if !ok {
+		return errorResponse(req.ID, factom.ErrMethodNotFound)
+	}
+
+	s.mu.Lock()
+	result, jerr := f(s, req.Params)
+	s.mu.Unlock()
+	if jerr != nil {
+		return errorResponse(req.ID, jerr)
+	}
+
+	p, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, factom.NewJSONError(factom.ErrInternal.Code, factom.ErrInternal.Message, err.Error()))
+	}
+	resp := factom.NewJSON2Response()
+	resp.ID = req.ID
+	resp.Result = p
+	return resp
+}
+
+func errorResponse(id interface{}, jerr *factom.JSONError) *factom.JSON2Response {
+	resp := factom.NewJSON2Response()
+	resp.ID = id
+	resp.Error = jerr
+	return resp
+}
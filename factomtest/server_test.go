@@ -0,0 +1,315 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factomtest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	. "github.com/FactomProject/factom/factomtest"
+)
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	c := s.Client()
+	c.Config.FactomdVerifyBlocks = true
+
+	ec, err := factom.GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetECBalance(ec.PubString(), 100); err != nil {
+		t.Fatal(err)
+	}
+
+	// create a Chain and add an Entry in the next minute
+	ch := factom.NewChainFromStrings("hello", "factomtest", "chain")
+	txid, err := c.CommitChain(ctx, ch, ec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RevealChain(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	if head, inPL, err := c.GetChainHead(ctx, ch.ChainID); err != nil || head != "" || !inPL {
+		t.Errorf("expected pending chain, recieved %q %v %v", head, inPL, err)
+	}
+
+	s.NextMinute()
+	e := factom.NewEntryFromStrings(ch.ChainID, "world", "second")
+	if _, err := c.CommitEntry(ctx, e, ec); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RevealEntry(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RevealEntry(ctx, e); !isJSONError(err, factom.ErrInvalidParams) {
+		t.Errorf("expected %v, recieved %v", factom.ErrInvalidParams, err)
+	}
+	missing := factom.NewEntryFromStrings(factom.ZeroHash, "no chain")
+	if _, err := c.CommitEntry(ctx, missing, ec); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RevealEntry(ctx, missing); !isJSONError(err, factom.ErrMissingChainHead) {
+		t.Errorf("expected %v, recieved %v", factom.ErrMissingChainHead, err)
+	}
+	if p, err := c.ListPendingEntries(ctx, ch.ChainID); err != nil || len(p) != 2 {
+		t.Errorf("expected 2 pending entries of %s, recieved %v %v", ch.ChainID, p, err)
+	}
+	if st, err := c.EntryCommitACK(ctx, txid, ""); err != nil || st.CommitData.Status != factom.StatusTransactionACK {
+		t.Errorf("expected %s, recieved %v %v", factom.StatusTransactionACK, st, err)
+	}
+
+	s.NextBlock()
+	if s.Height() != 2 || s.Minute() != 0 {
+		t.Errorf("expected height 2 minute 0, recieved %d %d", s.Height(), s.Minute())
+	}
+	es, err := c.GetAllChainEntries(ctx, ch.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 2 || string(es[0].Content) != "hello" || string(es[1].Content) != "world" {
+		t.Errorf("unexpected chain entries %v", es)
+	}
+	// 11 for the Chain and 1 for each Entry
+	if b, err := c.GetECBalance(ctx, ec.PubString()); err != nil || b != 87 {
+		t.Errorf("expected balance 87, recieved %d %v", b, err)
+	}
+	if st, err := c.EntryCommitACK(ctx, txid, ""); err != nil ||
+		st.CommitData.Status != factom.StatusDBlockConfirmed || st.EntryData.Status != factom.StatusDBlockConfirmed {
+		t.Errorf("expected %s, recieved %v %v", factom.StatusDBlockConfirmed, st, err)
+	}
+	if h, err := c.GetHeights(ctx); err != nil || h.DirectoryBlockHeight != 1 {
+		t.Errorf("expected height 1, recieved %v %v", h, err)
+	}
+
+	db, _, err := c.GetDBlockByHeight(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head, err := c.GetDBlockHead(ctx); err != nil || head != db.KeyMR {
+		t.Errorf("expected head %s, recieved %s %v", db.KeyMR, head, err)
+	}
+	if db2, _, err := c.GetDBlock(ctx, db.KeyMR); err != nil || db2.KeyMR != db.KeyMR {
+		t.Errorf("expected dblock %s, recieved %v %v", db.KeyMR, db2, err)
+	}
+	if len(db.DBEntries) != 4 || db.DBEntries[3].ChainID != ch.ChainID {
+		t.Errorf("unexpected dblock entries %v", db.DBEntries)
+	}
+	ecb, raw, err := c.GetECBlockByHeight(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ecb.HeaderHash != db.DBEntries[1].KeyMR || len(ecb.Entries) != 14 {
+		t.Errorf("unexpected ecblock %s", ecb)
+	}
+	ecb2 := new(factom.ECBlock)
+	if err := ecb2.UnmarshalBinary(raw); err != nil || ecb2.String() != ecb.String() {
+		t.Errorf("expected:%s\nrecieved:%s %v", ecb, ecb2, err)
+	}
+
+	// fund an address and buy Entry Credits without walletd
+	from, err := factom.GetFactoidAddress("Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := factom.MakeFactoidAddress(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetFactoidBalance(from.String(), 1e8)
+	s.AddAdminEntry(&factom.AdminAddFederatedServer{IdentityChainID: ch.ChainID, DBHeight: 3})
+
+	newTx := func() *factom.FactoidTransaction {
+		tx := factom.NewFactoidTransaction()
+		tx.AddInput(from, 5e7)
+		tx.AddOutput(to.String(), 4e7)
+		tx.AddECOutput(ec.PubString(), 1e7)
+		return tx
+	}
+	rate, err := c.GetECRate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := submit(ctx, c, newTx()); !isJSONError(err, factom.ErrInvalidParams) {
+		t.Errorf("expected %v, recieved %v", factom.ErrInvalidParams, err)
+	}
+	tx := newTx()
+	if err := tx.AddFee(from.String(), rate); err != nil {
+		t.Fatal(err)
+	}
+	_, txid, err = submit(ctx, c, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txid != tx.TxID {
+		t.Errorf("expected txid %s, recieved %s", tx.TxID, txid)
+	}
+	if st, err := c.FactoidACK(ctx, txid, ""); err != nil || st.Status != factom.StatusTransactionACK {
+		t.Errorf("expected %s, recieved %v %v", factom.StatusTransactionACK, st, err)
+	}
+	if p, err := c.ListPendingTransactions(ctx, ec.PubString()); err != nil || len(p) != 1 ||
+		p[0].TxID != txid || p[0].Inputs[0].Address != from.String() {
+		t.Errorf("expected pending transaction %s, recieved %v %v", txid, p, err)
+	}
+	if b, err := c.GetFactoidBalance(ctx, to.String()); err != nil || b != 4e7 {
+		t.Errorf("expected balance 4e7, recieved %d %v", b, err)
+	}
+	if b, err := c.GetECBalance(ctx, ec.PubString()); err != nil || b != 87+1e7/DefaultECRate {
+		t.Errorf("expected balance %d, recieved %d %v", 87+int64(1e7/DefaultECRate), b, err)
+	}
+
+	s.NextBlock()
+	fb, _, err := c.GetFBlockByHeight(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fb.Transactions) != 2 || fb.Transactions[1].TxID != txid {
+		t.Errorf("unexpected fblock %s", fb)
+	}
+	if err := fb.Transactions[1].VerifySignatures(); err != nil {
+		t.Error(err)
+	}
+	if st, err := c.FactoidACK(ctx, txid, ""); err != nil || st.Status != factom.StatusDBlockConfirmed {
+		t.Errorf("expected %s, recieved %v %v", factom.StatusDBlockConfirmed, st, err)
+	}
+	ab, _, err := c.GetABlockByHeight(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ab.ABEntries) != 1 {
+		t.Errorf("unexpected ablock %s", ab)
+	}
+	if _, _, err := c.GetDBlockByHeight(ctx, 3); !isJSONError(err, factom.ErrBlockNotFound) {
+		t.Errorf("expected %v, recieved %v", factom.ErrBlockNotFound, err)
+	}
+
+	// look up what was confirmed
+	if r, err := c.GetTransaction(ctx, txid); err != nil || r.FactoidTransaction == nil ||
+		r.IncludedInTransactionBlock != fb.KeyMR || r.IncludedInDirectoryBlockHeight != 2 {
+		t.Errorf("expected transaction in %s, recieved %v %v", fb.KeyMR, r, err)
+	}
+	hash := hex.EncodeToString(e.Hash())
+	if r, err := c.GetTransaction(ctx, hash); err != nil || r.Entry == nil ||
+		r.IncludedInTransactionBlock != db.DBEntries[3].KeyMR || r.IncludedInDirectoryBlock != db.KeyMR {
+		t.Errorf("expected entry in %s, recieved %v %v", db.DBEntries[3].KeyMR, r, err)
+	}
+	if _, err := c.GetTransaction(ctx, factom.ZeroHash); !isJSONError(err, factom.ErrEntryNotFound) {
+		t.Errorf("expected %v, recieved %v", factom.ErrEntryNotFound, err)
+	}
+	r, err := c.GetReceipt(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.VerifyDBlock(db); err != nil {
+		t.Error(err)
+	}
+	if _, err := c.GetReceipt(ctx, hex.EncodeToString(missing.Hash())); !isJSONError(err, factom.ErrReceiptCreation) {
+		t.Errorf("expected %v, recieved %v", factom.ErrReceiptCreation, err)
+	}
+
+	bs, err := c.GetMultipleFCTBalances(ctx, to.String(), ec.PubString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs.Balances) != 2 || bs.Balances[0].Ack != 4e7 || bs.Balances[0].Saved != 4e7 || bs.Balances[1].Err == "" {
+		t.Errorf("unexpected balances %v", bs)
+	}
+	if bs, err := c.GetMultipleECBalances(ctx, ec.PubString()); err != nil || len(bs.Balances) != 1 ||
+		bs.Balances[0].Ack != 87+1e7/DefaultECRate {
+		t.Errorf("unexpected balances %v %v", bs, err)
+	}
+
+	if _, err := c.SendRawMsg(ctx, "00"); err != nil {
+		t.Error(err)
+	}
+	if _, err := c.SendRawMsg(ctx, "not hex"); !isJSONError(err, factom.ErrInvalidParams) {
+		t.Errorf("expected %v, recieved %v", factom.ErrInvalidParams, err)
+	}
+}
+
+// submit composes and submits a Factoid Transaction.
+func submit(ctx context.Context, c *factom.Client, tx *factom.FactoidTransaction) (string, string, error) {
+	if err := tx.Sign(); err != nil {
+		return "", "", err
+	}
+	h, err := tx.Compose()
+	if err != nil {
+		return "", "", err
+	}
+	return c.FactoidSubmit(ctx, h)
+}
+
+func isJSONError(err error, target *factom.JSONError) bool {
+	jerr, ok := err.(*factom.JSONError)
+	return ok && jerr.Is(target)
+}
+
+// make sure unknown methods are reported as such
+func TestServerMethodNotFound(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	req := factom.NewJSON2Request("no-such-method", 1, nil)
+	resp, err := s.Client().SendFactomdRequest(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == nil || !resp.Error.Is(factom.ErrMethodNotFound) {
+		p, _ := json.Marshal(resp)
+		t.Errorf("expected %v, recieved %s", factom.ErrMethodNotFound, p)
+	}
+}
+
+// batches are answered only when enabled
+func TestServerBatch(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	post := func() *bytes.Buffer {
+		body := `[{"jsonrpc":"2.0","id":1,"method":"heights"},{"jsonrpc":"2.0","id":2,"method":"properties"}]`
+		resp, err := http.Post(s.URL(), "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(resp.Body)
+		return buf
+	}
+
+	resp := new(factom.JSON2Response)
+	if err := json.Unmarshal(post().Bytes(), resp); err != nil || resp.Error == nil || !resp.Error.Is(factom.ErrParse) {
+		t.Errorf("expected %v, recieved %v %v", factom.ErrParse, resp.Error, err)
+	}
+
+	s.SetBatch(true)
+	var resps []*factom.JSON2Response
+	if err := json.Unmarshal(post().Bytes(), &resps); err != nil || len(resps) != 2 {
+		t.Errorf("expected 2 responses, recieved %v %v", resps, err)
+	}
+}
+
+// Admin Block Entries that cannot be encoded are rejected
+func TestServerAddAdminEntry(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	if err := s.AddAdminEntry(&factom.AdminAddFederatedServer{IdentityChainID: "not hex"}); err == nil {
+		t.Errorf("expected invalid entry error, recieved %v", err)
+	}
+	s.NextBlock()
+	if s.Height() != 2 {
+		t.Errorf("expected height 2, recieved %d", s.Height())
+	}
+}
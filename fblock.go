@@ -5,25 +5,33 @@
 package factom
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 )
 
+// FactoidChainID is the Chain ID of the Factoid Blocks.
+const FactoidChainID = "000000000000000000000000000000000000000000000000000000000000000f"
+
 // FBlock represents a Factoid Block returned from factomd.
 // Note: the FBlock api return does not use a "Header" field like the other
 // block types do for some reason.
 type FBlock struct {
-	BodyMR          string            `json:"bodymr"`          // Merkle root of the Factoid transactions which accompany this block.
-	PrevKeyMR       string            `json:"prevkeymr"`       // Key Merkle root of previous block.
-	PrevLedgerKeyMR string            `json:"prevledgerkeymr"` // Sha3 of the previous Factoid Block
-	ExchRate        int64             `json:"exchrate"`        // Factoshis per Entry Credit
-	DBHeight        int64             `json:"dbheight"`        // Directory Block height
-	Transactions    []json.RawMessage `json:"transactions"`
+	BodyMR          string                `json:"bodymr"`          // Merkle root of the Factoid transactions which accompany this block.
+	PrevKeyMR       string                `json:"prevkeymr"`       // Key Merkle root of previous block.
+	PrevLedgerKeyMR string                `json:"prevledgerkeymr"` // Sha3 of the previous Factoid Block
+	ExchRate        int64                 `json:"exchrate"`        // Factoshis per Entry Credit
+	DBHeight        int64                 `json:"dbheight"`        // Directory Block height
+	Transactions    []*FactoidTransaction `json:"transactions"`
 
 	ChainID     string `json:"chainid,omitempty"`
 	KeyMR       string `json:"keymr,omitempty"`
 	LedgerKeyMR string `json:"ledgerkeymr,omitempty"`
+
+	HeaderExpansionArea []byte `json:"headerexpansionarea,omitempty"`
 }
 
 func (f *FBlock) String() string {
@@ -37,19 +45,173 @@ func (f *FBlock) String() string {
 
 	s += fmt.Sprintln("Transactions {")
 	for _, t := range f.Transactions {
-		s += fmt.Sprintln(string(t))
+		s += fmt.Sprintln(t)
 	}
 	s += fmt.Sprintln("}")
 
 	return s
 }
 
+// UnmarshalJSON decodes a Factoid Block in the form returned by factomd and
+// marks the coinbase Transaction.
+func (f *FBlock) UnmarshalJSON(data []byte) error {
+	type fblock FBlock
+	tmp := (*fblock)(f)
+	if err := json.Unmarshal(data, tmp); err != nil {
+		return err
+	}
+	for i, t := range f.Transactions {
+		t.Coinbase = i == 0
+	}
+	return nil
+}
+
+// FeesPaid returns the total fees paid by the Transactions of the Factoid
+// Block in factoshis.
+func (f *FBlock) FeesPaid() (uint64, error) {
+	var total uint64
+	for _, t := range f.Transactions {
+		fee, err := t.FeesPaid()
+		if err != nil {
+			return 0, fmt.Errorf("transaction %s: %v", t.TxID, err)
+		}
+		total += fee
+	}
+	return total, nil
+}
+
+// MarshalBinary returns the binary Factoid Block as stored by factomd. Each
+// Transaction is placed in the block by its Minute.
+func (f *FBlock) MarshalBinary() ([]byte, error) {
+	body := new(bytes.Buffer)
+	minute := 0
+	for _, t := range f.Transactions {
+		if t.Minute < minute || t.Minute > 9 {
+			return nil, fmt.Errorf("transaction %s has minute %d after minute %d", t.TxID, t.Minute, minute)
+		}
+		// the Transactions of each minute are followed by a 0 byte marker
+		for ; minute < t.Minute; minute++ {
+			body.WriteByte(0)
+		}
+		p, err := t.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		body.Write(p)
+	}
+	for ; minute < 10; minute++ {
+		body.WriteByte(0)
+	}
+
+	buf := new(bytes.Buffer)
+	err := writeHashes(buf, FactoidChainID, f.BodyMR, f.PrevKeyMR, f.PrevLedgerKeyMR)
+	if err != nil {
+		return nil, err
+	}
+	binary.Write(buf, binary.BigEndian, uint64(f.ExchRate))
+	binary.Write(buf, binary.BigEndian, uint32(f.DBHeight))
+	writeVarInt(buf, uint64(len(f.HeaderExpansionArea)))
+	buf.Write(f.HeaderExpansionArea)
+	binary.Write(buf, binary.BigEndian, uint32(len(f.Transactions)))
+	binary.Write(buf, binary.BigEndian, uint32(body.Len()))
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a binary Factoid Block, such as the raw data
+// returned by GetFBlock, and computes its KeyMR. The LedgerKeyMR is not
+// computed and is left empty.
+func (f *FBlock) UnmarshalBinary(data []byte) error {
+	r := &binaryReader{data: data}
+
+	if id := r.hash(); r.err == nil && id != FactoidChainID {
+		return fmt.Errorf("invalid factoid block chainid %s", id)
+	}
+	f.BodyMR = r.hash()
+	f.PrevKeyMR = r.hash()
+	f.PrevLedgerKeyMR = r.hash()
+	f.ExchRate = int64(r.uint64())
+	f.DBHeight = int64(r.uint32())
+	f.HeaderExpansionArea = nil
+	if n := r.varInt(); n > 0 {
+		f.HeaderExpansionArea = append([]byte{}, r.next(int(n))...)
+	}
+	count := int(r.uint32())
+	size := int(r.uint32())
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) != size {
+		return fmt.Errorf("factoid block body is %d bytes not %d", len(r.data), size)
+	}
+	header := data[:len(data)-size]
+
+	f.Transactions = f.Transactions[:0]
+	for minute := 0; minute < 10; {
+		if len(r.data) > 0 && r.data[0] == 0 {
+			r.next(1)
+			minute++
+			continue
+		}
+		if len(f.Transactions) == count {
+			return fmt.Errorf("factoid block has more than %d transactions", count)
+		}
+		t := new(FactoidTransaction)
+		if err := t.unmarshalBinary(r); err != nil {
+			return err
+		}
+		t.Coinbase = len(f.Transactions) == 0
+		t.Minute = minute
+		f.Transactions = append(f.Transactions, t)
+	}
+	if len(f.Transactions) != count {
+		return fmt.Errorf("factoid block has %d transactions not %d", len(f.Transactions), count)
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	bodyMR, _ := hex.DecodeString(f.BodyMR)
+	f.ChainID = FactoidChainID
+	f.KeyMR = hex.EncodeToString(computeKeyMR(header, bodyMR))
+	f.LedgerKeyMR = ""
+
+	return nil
+}
+
+// setMinutes sets the Minute of each Transaction from the minute markers of
+// the binary FBlock raw, since the JSON returned by factomd does not include
+// them.
+func (f *FBlock) setMinutes(raw []byte) error {
+	if f == nil {
+		return nil
+	}
+	b := new(FBlock)
+	if err := b.UnmarshalBinary(raw); err != nil {
+		return err
+	}
+	if len(b.Transactions) != len(f.Transactions) {
+		return fmt.Errorf("factoid block has %d transactions not %d", len(f.Transactions), len(b.Transactions))
+	}
+	for i, t := range b.Transactions {
+		f.Transactions[i].Minute = t.Minute
+	}
+	return nil
+}
+
 // GetFblock requests a specified Factoid Block from factomd. It returns the
 // FBlock struct, the raw binary FBlock, and an error if present.
 func GetFBlock(keymr string) (fblock *FBlock, raw []byte, err error) {
+	return DefaultClient.GetFBlock(context.Background(), keymr)
+}
+
+// GetFblock requests a specified Factoid Block from factomd. It returns the
+// FBlock struct, the raw binary FBlock, and an error if present.
+func (c *Client) GetFBlock(ctx context.Context, keymr string) (fblock *FBlock, raw []byte, err error) {
 	params := keyMRRequest{KeyMR: keymr}
 	req := NewJSON2Request("factoid-block", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
@@ -71,6 +233,9 @@ func GetFBlock(keymr string) (fblock *FBlock, raw []byte, err error) {
 	if err != nil {
 		return
 	}
+	if err = wrap.FBlock.setMinutes(raw); err != nil {
+		return nil, nil, err
+	}
 
 	return wrap.FBlock, raw, nil
 }
@@ -78,9 +243,15 @@ func GetFBlock(keymr string) (fblock *FBlock, raw []byte, err error) {
 // GetFBlockByHeight requests a specified Factoid Block from factomd, returning
 // the FBlock struct, the raw binary FBlock, and an error if present.
 func GetFBlockByHeight(height int64) (ablock *FBlock, raw []byte, err error) {
+	return DefaultClient.GetFBlockByHeight(context.Background(), height)
+}
+
+// GetFBlockByHeight requests a specified Factoid Block from factomd, returning
+// the FBlock struct, the raw binary FBlock, and an error if present.
+func (c *Client) GetFBlockByHeight(ctx context.Context, height int64) (ablock *FBlock, raw []byte, err error) {
 	params := heightRequest{Height: height}
 	req := NewJSON2Request("fblock-by-height", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
@@ -100,6 +271,9 @@ func GetFBlockByHeight(height int64) (ablock *FBlock, raw []byte, err error) {
 	if err != nil {
 		return
 	}
+	if err = wrap.FBlock.setMinutes(raw); err != nil {
+		return nil, nil, err
+	}
 
 	return wrap.FBlock, raw, nil
 }
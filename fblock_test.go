@@ -5,10 +5,13 @@
 package factom_test
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 
@@ -105,6 +108,48 @@ func TestGetFBlock(t *testing.T) {
 	}
 	t.Log(fb)
 	t.Log(fmt.Printf("%x\n", raw))
+
+	fb2 := new(FBlock)
+	if err := fb2.UnmarshalBinary(raw); err != nil {
+		t.Fatal(err)
+	}
+	if fb2.KeyMR != fb.KeyMR || fb2.BodyMR != fb.BodyMR || fb2.DBHeight != fb.DBHeight ||
+		fb2.ExchRate != fb.ExchRate || len(fb2.Transactions) != len(fb.Transactions) {
+		t.Fatalf("expected:%s\nrecieved:%s", fb, fb2)
+	}
+	for i := range fb.Transactions {
+		want, _ := json.Marshal(fb.Transactions[i])
+		got, _ := json.Marshal(fb2.Transactions[i])
+		if !bytes.Equal(got, want) {
+			t.Errorf("expected:%s\nrecieved:%s", want, got)
+		}
+		if fb2.Transactions[i].Coinbase != (i == 0) || fb.Transactions[i].Coinbase != (i == 0) {
+			t.Errorf("expected only the first transaction to be the coinbase")
+		}
+	}
+	if fee, err := fb2.FeesPaid(); err != nil || fee != 1090800 {
+		t.Errorf("expected fees 1090800, recieved %d %v", fee, err)
+	}
+	for _, f := range []*FBlock{fb, fb2} {
+		if p, err := f.MarshalBinary(); err != nil || !bytes.Equal(p, raw) {
+			t.Errorf("expected:%x\nrecieved:%x %v", raw, p, err)
+		}
+	}
+
+	// the minutes are read from the raw block
+	fb2.Transactions[1].Minute = 3
+	raw3, err := fb2.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	factomdResponse = strings.Replace(factomdResponse, hex.EncodeToString(raw), hex.EncodeToString(raw3), 1)
+	fb3, _, err := GetFBlock("cfcac07b29ccfa413aeda646b5d386006468189939dfdfa6415b97cc35f2ea1a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := fb3.Transactions[1].Minute; m != 3 {
+		t.Errorf("expected minute 3, recieved %d", m)
+	}
 }
 
 func TestGetFBlockByHeight(t *testing.T) {
@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -32,8 +33,13 @@ func (d *HeightsResponse) String() string {
 
 // GetHeights requests the list of heights from the factomd API.
 func GetHeights() (*HeightsResponse, error) {
+	return DefaultClient.GetHeights(context.Background())
+}
+
+// GetHeights requests the list of heights from the factomd API.
+func (c *Client) GetHeights(ctx context.Context) (*HeightsResponse, error) {
 	req := NewJSON2Request("heights", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
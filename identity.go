@@ -2,10 +2,13 @@ package factom
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 
 	"github.com/FactomProject/btcutil/base58"
 	ed "github.com/FactomProject/ed25519"
@@ -17,6 +20,60 @@ type Identity struct {
 	ChainID string
 	Name    []string
 	Keys    []*IdentityKey
+
+	// Height is the Directory Block height the Identity was resolved at by
+	// GetIdentityAtHeight, and CreatedHeight is the height of the first Entry
+	// of its Chain.
+	Height        int64
+	CreatedHeight int64
+
+	// ActiveKeys describes each of the Keys in priority order, Replacements
+	// lists the key replacements applied to reach them, and Rejected lists
+	// the key replacement Entries that were not valid.
+	ActiveKeys   []*ActiveIdentityKey
+	Replacements []*IdentityKeyReplacement
+	Rejected     []*RejectedIdentityEntry
+}
+
+// Reasons for rejecting an Identity key replacement Entry.
+var (
+	ErrReplaceKeyFormat    = errors.New("malformed key replacement")
+	ErrReplaceKeyNotActive = errors.New("replaced key is not active")
+	ErrReplaceKeyReused    = errors.New("new key has already been used by the identity")
+	ErrReplaceKeySigner    = errors.New("signer is not an active key of the same or higher priority")
+	ErrReplaceKeySignature = errors.New("invalid key replacement signature")
+)
+
+// ActiveIdentityKey is a key of an Identity with its priority Level, 0 being
+// the highest, and the height of the Directory Block containing the Entry that
+// made it active.
+type ActiveIdentityKey struct {
+	Key       string
+	Level     int
+	Height    int64
+	EntryHash string
+}
+
+// IdentityKeyReplacement is a valid key replacement Entry of an Identity.
+type IdentityKeyReplacement struct {
+	EntryHash string
+	Height    int64
+	Level     int
+	OldKey    string
+	NewKey    string
+
+	// SignerKey is the active key, at SignerLevel, that signed the
+	// replacement.
+	SignerKey   string
+	SignerLevel int
+}
+
+// RejectedIdentityEntry is a key replacement Entry of an Identity that was not
+// applied, and the reason it was rejected.
+type RejectedIdentityEntry struct {
+	EntryHash string
+	Height    int64
+	Reason    error
 }
 
 type IdentityAttribute struct {
@@ -60,27 +117,63 @@ func NewIdentityChain(name []string, keys []string) (*Chain, error) {
 // GetActiveIdentityKeys returns the identity's public keys that were/are active at the highest saved block height,
 // along with that blockheight
 func GetActiveIdentityKeys(chainID string) ([]string, int64, error) {
-	heights, err := GetHeights()
+	return DefaultClient.GetActiveIdentityKeys(context.Background(), chainID)
+}
+
+// GetActiveIdentityKeys returns the identity's public keys that were/are active at the highest saved block height,
+// along with that blockheight
+func (c *Client) GetActiveIdentityKeys(ctx context.Context, chainID string) ([]string, int64, error) {
+	heights, err := c.GetHeights(ctx)
 	if err != nil {
 		return nil, -1, err
 	}
-	keys, err := GetActiveIdentityKeysAtHeight(chainID, heights.DirectoryBlockHeight)
+	keys, err := c.GetActiveIdentityKeysAtHeight(ctx, chainID, heights.DirectoryBlockHeight)
 	return keys, heights.DirectoryBlockHeight, err
 }
 
 // GetActiveIdentityKeysAtHeight returns the identity's public keys that were active at the specified block height
 func GetActiveIdentityKeysAtHeight(chainID string, height int64) ([]string, error) {
-	if !ChainExists(chainID) {
-		return nil, fmt.Errorf("chain does not exist")
+	return DefaultClient.GetActiveIdentityKeysAtHeight(context.Background(), chainID, height)
+}
+
+// GetActiveIdentityKeysAtHeight returns the identity's public keys that were active at the specified block height
+func (c *Client) GetActiveIdentityKeysAtHeight(ctx context.Context, chainID string, height int64) ([]string, error) {
+	id, err := c.GetIdentityAtHeight(ctx, chainID, height)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []string
+	for _, k := range id.Keys {
+		resp = append(resp, k.PubString())
 	}
+	return resp, nil
+}
 
-	entries, err := GetAllChainEntriesAtHeight(chainID, height)
+// GetIdentityAtHeight returns the state of the identity at the specified block height: its name, its active keys,
+// the key replacements that led to them, and the key replacement entries that were rejected.
+func GetIdentityAtHeight(chainID string, height int64) (*Identity, error) {
+	return DefaultClient.GetIdentityAtHeight(context.Background(), chainID, height)
+}
+
+// GetIdentityAtHeight returns the state of the identity at the specified block height: its name, its active keys,
+// the key replacements that led to them, and the key replacement entries that were rejected.
+func (c *Client) GetIdentityAtHeight(ctx context.Context, chainID string, height int64) (*Identity, error) {
+	entries, heights, err := c.chainEntriesAtHeight(ctx, chainID, height)
+	if jerr, ok := err.(*JSONError); ok && jerr.Is(ErrMissingChainHead) {
+		return nil, notIdentityError("chain does not exist")
+	}
+	if err == ErrChainPending {
+		err = nil
+	}
 	if err != nil {
 		return nil, err
-	} else if len(entries) == 0 {
-		return nil, fmt.Errorf("chain did not yet exist at height %d", height)
+	}
+
+	if len(entries) == 0 {
+		return nil, notIdentityError(fmt.Sprintf("chain did not yet exist at height %d", height))
 	} else if len(entries[0].ExtIDs) == 0 || !bytes.Equal(entries[0].ExtIDs[0], []byte("IdentityChain")) {
-		return nil, fmt.Errorf("no identity found at chain ID: %s", chainID)
+		return nil, notIdentityError(fmt.Sprintf("no identity found at chain ID: %s", chainID))
 	}
 
 	var identityInfo struct {
@@ -90,87 +183,126 @@ func GetActiveIdentityKeysAtHeight(chainID string, height int64) ([]string, erro
 	initialKeysJSON := entries[0].Content
 	err = json.Unmarshal(initialKeysJSON, &identityInfo)
 	if err != nil {
-		return nil, fmt.Errorf("no identity found at chain ID: %s", chainID)
+		return nil, notIdentityError(fmt.Sprintf("no identity found at chain ID: %s", chainID))
+	}
+
+	id := &Identity{ChainID: chainID, Height: height, CreatedHeight: heights[0]}
+	for _, part := range entries[0].ExtIDs[1:] {
+		id.Name = append(id.Name, string(part))
 	}
 
-	var activeKeys []*IdentityKey
 	allKeys := make(map[string]bool)
+	firstHash := hex.EncodeToString(entries[0].Hash())
 	for _, pubString := range identityInfo.InitialKeys {
 		if IdentityKeyStringType(pubString) != IDPub {
-			return nil, fmt.Errorf("invalid identity public key string in first entry: %s", pubString)
+			return nil, notIdentityError(fmt.Sprintf("invalid identity public key string in first entry: %s", pubString))
 		} else if _, present := allKeys[pubString]; present {
 			continue
 		}
-		pub := base58.Decode(pubString)
-		k := NewIdentityKey()
-		copy(k.Pub[:], pub[IDKeyPrefixLength:IDKeyBodyLength])
-		activeKeys = append(activeKeys, k)
+		id.ActiveKeys = append(id.ActiveKeys, &ActiveIdentityKey{
+			Key:       pubString,
+			Level:     len(id.ActiveKeys),
+			Height:    heights[0],
+			EntryHash: firstHash,
+		})
 		allKeys[pubString] = true
 	}
 
-	for _, e := range entries {
-		if len(e.ExtIDs) < 5 || bytes.Compare(e.ExtIDs[0], []byte("ReplaceKey")) != 0 {
+	for i, e := range entries[1:] {
+		if len(e.ExtIDs) == 0 || !bytes.Equal(e.ExtIDs[0], []byte("ReplaceKey")) {
 			continue
 		}
-		if len(e.ExtIDs[1]) != 55 || len(e.ExtIDs[2]) != 55 || len(e.ExtIDs[3]) != ed.SignatureSize {
+		r, err := id.replaceKey(e, allKeys)
+		if err != nil {
+			id.Rejected = append(id.Rejected, &RejectedIdentityEntry{
+				EntryHash: hex.EncodeToString(e.Hash()),
+				Height:    heights[i+1],
+				Reason:    err,
+			})
 			continue
 		}
-
-		var oldKey [ed.PublicKeySize]byte
-		oldPubString := string(e.ExtIDs[1])
-		if IdentityKeyStringType(oldPubString) != IDPub {
-			continue
+		r.Height = heights[i+1]
+		id.ActiveKeys[r.Level] = &ActiveIdentityKey{
+			Key:       r.NewKey,
+			Level:     r.Level,
+			Height:    r.Height,
+			EntryHash: r.EntryHash,
 		}
-		b := base58.Decode(oldPubString)
-		copy(oldKey[:], b[IDKeyPrefixLength:IDKeyBodyLength])
+		id.Replacements = append(id.Replacements, r)
+	}
 
-		var newKey [ed.PublicKeySize]byte
-		newPubString := string(e.ExtIDs[2])
-		if IdentityKeyStringType(newPubString) != IDPub {
-			continue
-		}
-		b = base58.Decode(newPubString)
-		copy(newKey[:], b[IDKeyPrefixLength:IDKeyBodyLength])
+	for _, k := range id.ActiveKeys {
+		pub := base58.Decode(k.Key)
+		key := NewIdentityKey()
+		copy(key.Pub[:], pub[IDKeyPrefixLength:IDKeyBodyLength])
+		id.Keys = append(id.Keys, key)
+	}
+	return id, nil
+}
 
-		// Disallow re-adding retired or currently active keys
-		if _, present := allKeys[newPubString]; present {
-			continue
-		}
+// replaceKey checks a ReplaceKey Entry against the active keys of the Identity
+// and the keys it has ever used, returning the replacement it makes.
+func (id *Identity) replaceKey(e *Entry, allKeys map[string]bool) (*IdentityKeyReplacement, error) {
+	if len(e.ExtIDs) < 5 {
+		return nil, ErrReplaceKeyFormat
+	}
+	if len(e.ExtIDs[1]) != 55 || len(e.ExtIDs[2]) != 55 || len(e.ExtIDs[3]) != ed.SignatureSize {
+		return nil, ErrReplaceKeyFormat
+	}
 
-		var signature [ed.SignatureSize]byte
-		copy(signature[:], e.ExtIDs[3])
-		signerPubString := string(e.ExtIDs[4])
+	oldPubString := string(e.ExtIDs[1])
+	newPubString := string(e.ExtIDs[2])
+	if IdentityKeyStringType(oldPubString) != IDPub || IdentityKeyStringType(newPubString) != IDPub {
+		return nil, ErrReplaceKeyFormat
+	}
 
-		levelToReplace := -1
-		for level, key := range activeKeys {
-			if bytes.Compare(oldKey[:], key.PubBytes()) == 0 {
-				levelToReplace = level
-			}
-		}
-		if levelToReplace == -1 {
-			// oldkey not in the set of valid keys when this entry was published
-			continue
+	// Disallow re-adding retired or currently active keys
+	if _, present := allKeys[newPubString]; present {
+		return nil, ErrReplaceKeyReused
+	}
+
+	levelToReplace := -1
+	for _, k := range id.ActiveKeys {
+		if k.Key == oldPubString {
+			levelToReplace = k.Level
 		}
+	}
+	if levelToReplace == -1 {
+		// oldkey not in the set of valid keys when this entry was published
+		return nil, ErrReplaceKeyNotActive
+	}
 
-		message := []byte(chainID + oldPubString + newPubString)
-		for level, key := range activeKeys {
-			if level > levelToReplace {
-				// low priority key trying to replace high priority key, disregard
-				break
-			}
-			if key.PubString() == signerPubString && ed.Verify(key.Pub, message, &signature) {
-				activeKeys[levelToReplace].Pub = &newKey
-				allKeys[newPubString] = true
-				break
-			}
+	// a key may only be replaced by a key of the same or higher priority
+	signerPubString := string(e.ExtIDs[4])
+	signerLevel := -1
+	for _, k := range id.ActiveKeys[:levelToReplace+1] {
+		if k.Key == signerPubString {
+			signerLevel = k.Level
 		}
 	}
+	if signerLevel == -1 {
+		return nil, ErrReplaceKeySigner
+	}
 
-	var resp []string
-	for _, k := range activeKeys {
-		resp = append(resp, k.PubString())
+	var signerKey [ed.PublicKeySize]byte
+	b := base58.Decode(signerPubString)
+	copy(signerKey[:], b[IDKeyPrefixLength:IDKeyBodyLength])
+	var signature [ed.SignatureSize]byte
+	copy(signature[:], e.ExtIDs[3])
+	message := []byte(id.ChainID + oldPubString + newPubString)
+	if !ed.Verify(&signerKey, message, &signature) {
+		return nil, ErrReplaceKeySignature
 	}
-	return resp, nil
+
+	allKeys[newPubString] = true
+	return &IdentityKeyReplacement{
+		EntryHash:   hex.EncodeToString(e.Hash()),
+		Level:       levelToReplace,
+		OldKey:      oldPubString,
+		NewKey:      newPubString,
+		SignerKey:   signerPubString,
+		SignerLevel: signerLevel,
+	}, nil
 }
 
 // NewIdentityKeyReplacementEntry creates and returns a new Entry struct for the key replacement. Publish it to the
@@ -291,3 +423,174 @@ func IsValidEndorsement(e *Entry) bool {
 	msg := e.ChainID + string(e.Content)
 	return ed.Verify(&signerKey, []byte(msg), &signature)
 }
+
+// VerifiedAttribute is an Identity attribute Entry whose signature was made by
+// a key of the signer Identity that was active at the height of the Entry.
+type VerifiedAttribute struct {
+	EntryHash       string
+	Height          int64
+	ReceiverChainID string
+	SignerChainID   string
+
+	// SignerKey is the key that signed the attribute, at priority
+	// SignerKeyLevel of the signer Identity.
+	SignerKey      string
+	SignerKeyLevel int
+
+	// Content is the attribute JSON. Attributes is the Content decoded as a
+	// list of key and value pairs, or nil if it is not one.
+	Content    []byte
+	Attributes []IdentityAttribute
+
+	Endorsements []*VerifiedEndorsement
+}
+
+// VerifiedEndorsement is an endorsement of an attribute signed by a key of the
+// endorsing Identity that was active at the height of the Entry.
+type VerifiedEndorsement struct {
+	EntryHash      string
+	Height         int64
+	SignerChainID  string
+	SignerKey      string
+	SignerKeyLevel int
+}
+
+// GetIdentityAttributes scans the destination chain for the attributes assigned to the receiver identity, or to any
+// identity if receiverChainID is empty, along with the endorsements of each attribute. Only attributes and endorsements
+// with a valid signature by a key that was active for the signer identity at the height of the entry are returned. The
+// signer keys are those of the identity at the end of that block, as resolved by GetIdentityAtHeight.
+func GetIdentityAttributes(destinationChainID string, receiverChainID string) ([]*VerifiedAttribute, error) {
+	return DefaultClient.GetIdentityAttributes(context.Background(), destinationChainID, receiverChainID)
+}
+
+// GetIdentityAttributes scans the destination chain for the attributes assigned to the receiver identity, or to any
+// identity if receiverChainID is empty, along with the endorsements of each attribute. Only attributes and endorsements
+// with a valid signature by a key that was active for the signer identity at the height of the entry are returned. The
+// signer keys are those of the identity at the end of that block, as resolved by GetIdentityAtHeight.
+func (c *Client) GetIdentityAttributes(ctx context.Context, destinationChainID string, receiverChainID string) ([]*VerifiedAttribute, error) {
+	entries, heights, err := c.chainEntriesAtHeight(ctx, destinationChainID, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+
+	signers := newIdentitySigners(c)
+	attrs := make([]*VerifiedAttribute, 0)
+	byHash := make(map[string]*VerifiedAttribute)
+	for i, e := range entries {
+		if !IsValidAttribute(e) {
+			continue
+		}
+		receiver, signer, key := string(e.ExtIDs[1]), string(e.ExtIDs[4]), string(e.ExtIDs[3])
+		if receiverChainID != "" && receiver != receiverChainID {
+			continue
+		}
+		level, err := signers.keyLevel(ctx, signer, key, heights[i])
+		if err != nil {
+			return nil, err
+		}
+		if level < 0 {
+			continue
+		}
+
+		a := &VerifiedAttribute{
+			EntryHash:       hex.EncodeToString(e.Hash()),
+			Height:          heights[i],
+			ReceiverChainID: receiver,
+			SignerChainID:   signer,
+			SignerKey:       key,
+			SignerKeyLevel:  level,
+			Content:         e.Content,
+		}
+		if err := json.Unmarshal(e.Content, &a.Attributes); err != nil {
+			a.Attributes = nil
+		}
+		attrs = append(attrs, a)
+		byHash[a.EntryHash] = a
+	}
+
+	for i, e := range entries {
+		if !IsValidEndorsement(e) {
+			continue
+		}
+		a, ok := byHash[string(e.Content)]
+		if !ok {
+			continue
+		}
+		signer, key := string(e.ExtIDs[3]), string(e.ExtIDs[2])
+		level, err := signers.keyLevel(ctx, signer, key, heights[i])
+		if err != nil {
+			return nil, err
+		}
+		if level < 0 {
+			continue
+		}
+		a.Endorsements = append(a.Endorsements, &VerifiedEndorsement{
+			EntryHash:      hex.EncodeToString(e.Hash()),
+			Height:         heights[i],
+			SignerChainID:  signer,
+			SignerKey:      key,
+			SignerKeyLevel: level,
+		})
+	}
+
+	return attrs, nil
+}
+
+// notIdentityError is returned by GetIdentityAtHeight when the Chain is not a
+// valid Identity at the height, as opposed to when factomd could not be asked.
+type notIdentityError string
+
+func (e notIdentityError) Error() string {
+	return string(e)
+}
+
+// identitySigners caches the Identities resolved while verifying signers.
+// Each Identity is resolved once at the latest height and its earlier keys
+// are found by undoing its later key replacements.
+type identitySigners struct {
+	c   *Client
+	ids map[string]*Identity
+}
+
+func newIdentitySigners(c *Client) *identitySigners {
+	return &identitySigners{c: c, ids: make(map[string]*Identity)}
+}
+
+// keyLevel returns the priority of a key of an Identity at a height, or -1 if
+// the key was not active or the Chain was not an Identity. Errors resolving
+// the Identity are returned and not cached.
+func (s *identitySigners) keyLevel(ctx context.Context, chainID, key string, height int64) (int, error) {
+	if _, err := hex.DecodeString(chainID); err != nil {
+		return -1, nil
+	}
+	id, ok := s.ids[chainID]
+	if !ok {
+		var err error
+		id, err = s.c.GetIdentityAtHeight(ctx, chainID, math.MaxInt64)
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		if _, ok := err.(notIdentityError); !ok && err != nil {
+			return -1, err
+		}
+		s.ids[chainID] = id
+	}
+	if id == nil || height < id.CreatedHeight {
+		return -1, nil
+	}
+
+	keys := make([]string, len(id.ActiveKeys))
+	for i, k := range id.ActiveKeys {
+		keys[i] = k.Key
+	}
+	for i := len(id.Replacements) - 1; i >= 0 && id.Replacements[i].Height > height; i-- {
+		r := id.Replacements[i]
+		keys[r.Level] = r.OldKey
+	}
+	for level, k := range keys {
+		if k == key {
+			return level, nil
+		}
+	}
+	return -1, nil
+}
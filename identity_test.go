@@ -1,11 +1,16 @@
 package factom_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/hex"
 	"encoding/json"
+	"strings"
 
 	ed "github.com/FactomProject/ed25519"
 
 	. "github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/factomtest"
 
 	"testing"
 )
@@ -148,3 +153,265 @@ func TestNewIdentityAttributeEndorsementEntry(t *testing.T) {
 		t.Errorf("Improperly formatted attribute endorsement")
 	}
 }
+
+// identityTestServer writes Identity Chains and Entries to a factomtest
+// Server.
+type identityTestServer struct {
+	*factomtest.Server
+	t  *testing.T
+	c  *Client
+	ec *ECAddress
+}
+
+func newIdentityTestServer(t *testing.T) *identityTestServer {
+	ec, err := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &identityTestServer{Server: factomtest.NewServer(), t: t, ec: ec}
+	s.c = s.Client()
+	s.SetECBalance(ec.PubString(), 10000)
+	return s
+}
+
+func (s *identityTestServer) write(e *Entry) {
+	if _, err := s.c.CommitEntry(context.Background(), e, s.ec); err != nil {
+		s.t.Fatal(err)
+	}
+	if _, err := s.c.RevealEntry(context.Background(), e); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+func (s *identityTestServer) writeChain(ch *Chain) {
+	if _, err := s.c.CommitChain(context.Background(), ch, s.ec); err != nil {
+		s.t.Fatal(err)
+	}
+	if _, err := s.c.RevealChain(context.Background(), ch); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// newIdentity writes a new Identity Chain with the given keys.
+func (s *identityTestServer) newIdentity(name string, keys ...*IdentityKey) string {
+	pubs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pubs = append(pubs, k.PubString())
+	}
+	ch, err := NewIdentityChain([]string{"Test", name}, pubs)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.writeChain(ch)
+	return ch.ChainID
+}
+
+func (s *identityTestServer) key(b byte) *IdentityKey {
+	k, err := MakeIdentityKey(bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return k
+}
+
+func TestGetIdentityAtHeight(t *testing.T) {
+	ctx := context.Background()
+	s := newIdentityTestServer(t)
+	defer s.Close()
+	c, write, key := s.c, s.write, s.key
+	k0, k1, k2, n1, n2, n3 := key(1), key(2), key(3), key(4), key(5), key(6)
+	replace := func(chainID string, oldKey, newKey, signer *IdentityKey) *Entry {
+		e, err := NewIdentityKeyReplacementEntry(chainID, oldKey.PubString(), newKey.PubString(), signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	// height 1
+	ch, err := NewIdentityChain([]string{"Test", "Identity"}, []string{k0.PubString(), k1.PubString(), k2.PubString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.writeChain(ch)
+	s.NextBlock()
+
+	// height 2
+	valid := replace(ch.ChainID, k2, n1, k1)
+	write(valid)
+	write(replace(ch.ChainID, k0, n2, k2))
+	write(replace(ch.ChainID, k1, k0, k0))
+	s.NextBlock()
+
+	// height 3
+	write(replace(ch.ChainID, k2, n3, k0))
+	badSig := replace(ch.ChainID, n1, n3, k0)
+	badSig.ExtIDs[3] = k0.Sign([]byte("something else"))[:]
+	write(badSig)
+	write(NewEntryFromStrings(ch.ChainID, "", "ReplaceKey"))
+	write(NewEntryFromStrings(ch.ChainID, "not a key replacement"))
+	s.NextBlock()
+
+	id, err := c.GetIdentityAtHeight(ctx, ch.ChainID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.ChainID != ch.ChainID || len(id.Name) != 2 || id.Name[1] != "Identity" ||
+		len(id.Keys) != 3 || id.Keys[2].PubString() != k2.PubString() ||
+		len(id.Replacements) != 0 || len(id.Rejected) != 0 {
+		t.Errorf("unexpected identity at height 1 %+v", id)
+	}
+	if k := id.ActiveKeys[2]; k.Level != 2 || k.Height != 1 || k.EntryHash != hex.EncodeToString(ch.FirstEntry.Hash()) {
+		t.Errorf("unexpected key %+v", k)
+	}
+
+	id, err = c.GetIdentityAtHeight(ctx, ch.ChainID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(id.Keys) != 3 || id.Keys[2].PubString() != n1.PubString() {
+		t.Errorf("unexpected keys at height 2 %+v", id.ActiveKeys)
+	}
+	if k := id.ActiveKeys[2]; k.Key != n1.PubString() || k.Height != 2 {
+		t.Errorf("unexpected key %+v", k)
+	}
+	if len(id.Replacements) != 1 {
+		t.Fatalf("unexpected replacements %+v", id.Replacements)
+	}
+	if r := id.Replacements[0]; r.Level != 2 || r.OldKey != k2.PubString() || r.NewKey != n1.PubString() ||
+		r.SignerKey != k1.PubString() || r.SignerLevel != 1 || r.Height != 2 {
+		t.Errorf("unexpected replacement %+v", r)
+	}
+	if len(id.Rejected) != 2 || id.Rejected[0].Reason != ErrReplaceKeySigner ||
+		id.Rejected[1].Reason != ErrReplaceKeyReused || id.Rejected[1].Height != 2 {
+		t.Errorf("unexpected rejected entries %+v", id.Rejected)
+	}
+
+	id, err = c.GetIdentityAtHeight(ctx, ch.ChainID, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(id.Rejected) != 5 || id.Rejected[2].Reason != ErrReplaceKeyNotActive ||
+		id.Rejected[3].Reason != ErrReplaceKeySignature || id.Rejected[4].Reason != ErrReplaceKeyFormat {
+		t.Errorf("unexpected rejected entries %+v", id.Rejected)
+	}
+
+	keys, err := c.GetActiveIdentityKeysAtHeight(ctx, ch.ChainID, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 || keys[0] != k0.PubString() || keys[2] != n1.PubString() {
+		t.Errorf("unexpected active keys %v", keys)
+	}
+
+	if _, err := c.GetIdentityAtHeight(ctx, ch.ChainID, 0); err == nil {
+		t.Error("expected an error for the identity before its chain existed")
+	}
+}
+
+func TestGetIdentityAttributes(t *testing.T) {
+	ctx := context.Background()
+	s := newIdentityTestServer(t)
+	defer s.Close()
+	key := s.key
+	receiverKey, signerKey, endorserKey, newEndorserKey, otherKey := key(1), key(2), key(3), key(4), key(5)
+
+	// height 1
+	receiver := s.newIdentity("Receiver", receiverKey)
+	signer := s.newIdentity("Signer", signerKey)
+	endorser := s.newIdentity("Endorser", endorserKey)
+	destination := NewChainFromStrings("attributes", "destination")
+	s.writeChain(destination)
+	s.NextBlock()
+
+	// height 2
+	attr := `[{"key":"email","value":"test@example.com"}]`
+	valid := NewIdentityAttributeEntry(receiver, destination.ChainID, attr, signerKey, signer)
+	s.write(valid)
+	validHash := hex.EncodeToString(valid.Hash())
+	badSig := NewIdentityAttributeEntry(receiver, destination.ChainID, attr, signerKey, signer)
+	badSig.Content = []byte(`[{"key":"email","value":"changed@example.com"}]`)
+	s.write(badSig)
+	s.write(NewIdentityAttributeEntry(receiver, destination.ChainID, `{"not":"a list"}`, otherKey, signer))
+	s.write(NewIdentityAttributeEntry(signer, destination.ChainID, attr, signerKey, signer))
+	s.write(NewIdentityAttributeEndorsementEntry(destination.ChainID, validHash, endorserKey, endorser))
+	endorsement := NewIdentityAttributeEndorsementEntry(destination.ChainID, validHash, endorserKey, endorser)
+	endorsement.ExtIDs[1] = endorserKey.Sign([]byte("something else"))[:]
+	s.write(endorsement)
+	s.NextBlock()
+
+	// height 3
+	replacement, err := NewIdentityKeyReplacementEntry(endorser, endorserKey.PubString(), newEndorserKey.PubString(), endorserKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.write(replacement)
+	s.NextBlock()
+
+	// height 4: the replaced key is no longer active
+	s.write(NewIdentityAttributeEndorsementEntry(destination.ChainID, validHash, endorserKey, endorser))
+	s.write(NewIdentityAttributeEndorsementEntry(destination.ChainID, validHash, newEndorserKey, endorser))
+	s.NextBlock()
+
+	attrs, err := s.c.GetIdentityAttributes(ctx, destination.ChainID, receiver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attrs) != 1 {
+		t.Fatalf("expected 1 attribute, recieved %d", len(attrs))
+	}
+	a := attrs[0]
+	if a.EntryHash != validHash || a.Height != 2 || a.ReceiverChainID != receiver || a.SignerChainID != signer ||
+		a.SignerKey != signerKey.PubString() || a.SignerKeyLevel != 0 || string(a.Content) != attr ||
+		len(a.Attributes) != 1 || a.Attributes[0].Key != "email" {
+		t.Errorf("unexpected attribute %+v", a)
+	}
+	if len(a.Endorsements) != 2 {
+		t.Fatalf("expected 2 endorsements, recieved %d", len(a.Endorsements))
+	}
+	if e := a.Endorsements[0]; e.Height != 2 || e.SignerChainID != endorser || e.SignerKey != endorserKey.PubString() {
+		t.Errorf("unexpected endorsement %+v", e)
+	}
+	if e := a.Endorsements[1]; e.Height != 4 || e.SignerKey != newEndorserKey.PubString() {
+		t.Errorf("unexpected endorsement %+v", e)
+	}
+
+	all, err := s.c.GetIdentityAttributes(ctx, destination.ChainID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[1].ReceiverChainID != signer {
+		t.Errorf("unexpected attributes %+v", all)
+	}
+
+	// each signer Identity is resolved once, not at every height it signed at
+	var heads int
+	ts := faultServer(s.Server, func(req *JSON2Request) string {
+		if req.Method == "chain-head" && strings.Contains(string(req.Params), endorser) {
+			heads++
+		}
+		return ""
+	})
+	defer ts.Close()
+	attrs, err = NewClient(&RPCConfig{FactomdServer: ts.URL}).GetIdentityAttributes(ctx, destination.ChainID, receiver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attrs) != 1 || len(attrs[0].Endorsements) != 2 || heads != 1 {
+		t.Errorf("expected 1 chain head request for the endorser, recieved %d", heads)
+	}
+
+	// a failure resolving a signer is returned instead of dropping its
+	// attributes as unverified
+	ts = faultServer(s.Server, func(req *JSON2Request) string {
+		if req.Method == "chain-head" && strings.Contains(string(req.Params), signer) {
+			return `"error":{"code":-32603,"message":"Internal error"}`
+		}
+		return ""
+	})
+	defer ts.Close()
+	_, err = NewClient(&RPCConfig{FactomdServer: ts.URL}).GetIdentityAttributes(ctx, destination.ChainID, receiver)
+	if jerr, ok := err.(*JSONError); !ok || !jerr.Is(ErrInternal) {
+		t.Errorf("expected %v, recieved %v", ErrInternal, err)
+	}
+}
@@ -6,13 +6,13 @@ package factom
 
 import (
 	"bytes"
-	"crypto/tls"
-	"crypto/x509"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -32,6 +32,50 @@ type RPCConfig struct {
 	FactomdRPCPassword string
 	FactomdServer      string
 	FactomdTimeout     time.Duration
+
+	// FactomdServers is a list of factomd API servers used in place of
+	// FactomdServer. Requests are sent to the healthy server that has synced
+	// furthest, failing over to the other servers on errors. If
+	// FactomdRoundRobin is set, read requests are spread over all of the
+	// synced servers. Server health is checked in the background at most
+	// once every FactomdHealthInterval, or DefaultFactomdHealthInterval if
+	// unset.
+	FactomdServers        []string
+	FactomdRoundRobin     bool
+	FactomdHealthInterval time.Duration
+
+	// Connection pool limits shared by the factomd and wallet API clients.
+	// Zero values use DefaultMaxIdleConns, DefaultMaxIdleConnsPerHost, and
+	// DefaultIdleConnTimeout.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+
+	// FactomdRetryPolicy decides if failed factomd requests are retried. A nil
+	// FactomdRetryPolicy never retries. Requests that commit, reveal, or
+	// submit data to the network are only retried if FactomdRetryWrites is
+	// set.
+	FactomdRetryPolicy RetryPolicy
+	FactomdRetryWrites bool
+
+	// FactomdBatchSize is the maximum number of requests sent to factomd in a
+	// single JSON RPC batch. Zero uses DefaultFactomdBatchSize.
+	FactomdBatchSize int
+
+	// FactomdConcurrency is the maximum number of JSON RPC batches sent to
+	// factomd at once when requesting many Entries. Zero uses
+	// DefaultFactomdConcurrency.
+	FactomdConcurrency int
+
+	// FactomdVerifyBlocks checks that the Entry Blocks and Directory Blocks
+	// returned by factomd hash to their Key Merkle Roots. Verifying an Entry
+	// Block requires a second request for its raw data.
+	FactomdVerifyBlocks bool
+
+	// FactomdACKBackoff sets the time between status requests made while
+	// waiting for an acknowledgement, or DefaultACKBackoff if nil. Its
+	// MaxAttempts and Retryable are not used; the wait ends with its context.
+	FactomdACKBackoff *Backoff
 }
 
 func EncodeJSON(data interface{}) ([]byte, error) {
@@ -72,6 +116,58 @@ func (e *JSONError) Error() string {
 	return s
 }
 
+// JSON RPC errors returned by factomd and factom-walletd. The API functions
+// return the *JSONError sent by the server, which matches these values with
+// errors.Is, or their Is method, when it has the same code and message. The
+// errors for a whole code, such as ErrInvalidParams or ErrObjectNotFound,
+// match any message with that code.
+var (
+	// JSON RPC 2.0 errors
+	ErrParse          = NewJSONError(-32700, "Parse error", nil)
+	ErrInvalidRequest = NewJSONError(-32600, "Invalid Request", nil)
+	ErrMethodNotFound = NewJSONError(-32601, "Method not found", nil)
+	ErrInvalidParams  = NewJSONError(-32602, "Invalid params", nil)
+	ErrInternal       = NewJSONError(-32603, "Internal error", nil)
+
+	// factomd errors
+	ErrObjectNotFound   = NewJSONError(-32008, "Object not found", nil)
+	ErrBlockNotFound    = NewJSONError(-32008, "Block not found", nil)
+	ErrEntryNotFound    = NewJSONError(-32008, "Entry not found", nil)
+	ErrMissingChainHead = NewJSONError(-32009, "Missing Chain Head", nil)
+	ErrReceiptCreation  = NewJSONError(-32010, "Receipt creation error", nil)
+	ErrRepeatedCommit   = NewJSONError(-32011, "Repeated Commit", nil)
+
+	// factom-walletd errors
+	ErrWalletIsLocked      = NewJSONError(-32001, "Wallet is locked", nil)
+	ErrIncorrectPassphrase = NewJSONError(-32003, "Incorrect passphrase", nil)
+)
+
+// codeErrors are the errors matching every JSONError with their code.
+var codeErrors = map[int]*JSONError{
+	ErrParse.Code:               ErrParse,
+	ErrInvalidRequest.Code:      ErrInvalidRequest,
+	ErrMethodNotFound.Code:      ErrMethodNotFound,
+	ErrInvalidParams.Code:       ErrInvalidParams,
+	ErrInternal.Code:            ErrInternal,
+	ErrObjectNotFound.Code:      ErrObjectNotFound,
+	ErrMissingChainHead.Code:    ErrMissingChainHead,
+	ErrReceiptCreation.Code:     ErrReceiptCreation,
+	ErrRepeatedCommit.Code:      ErrRepeatedCommit,
+	ErrWalletIsLocked.Code:      ErrWalletIsLocked,
+	ErrIncorrectPassphrase.Code: ErrIncorrectPassphrase,
+}
+
+// Is reports whether the error matches target for errors.Is. A JSONError
+// matches a target JSONError with the same code and message, or the error for
+// its whole code.
+func (e *JSONError) Is(target error) bool {
+	t, ok := target.(*JSONError)
+	if !ok || t.Code != e.Code {
+		return false
+	}
+	return codeErrors[t.Code] == t || t.Message == e.Message
+}
+
 type JSON2Request struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      interface{}     `json:"id"`
@@ -189,6 +285,29 @@ func GetWalletEncryption() (bool, string) {
 	return RpcConfig.WalletTLSEnable, RpcConfig.WalletTLSCertFile
 }
 
+// SetConnectionPool sets the keep-alive connection pool limits used for the
+// factomd and wallet API servers.
+func SetConnectionPool(maxIdle, maxIdlePerHost int, idleTimeout time.Duration) {
+	RpcConfig.MaxIdleConns = maxIdle
+	RpcConfig.MaxIdleConnsPerHost = maxIdlePerHost
+	RpcConfig.IdleConnTimeout = idleTimeout
+}
+
+func GetConnectionPool() (int, int, time.Duration) {
+	return RpcConfig.MaxIdleConns, RpcConfig.MaxIdleConnsPerHost, RpcConfig.IdleConnTimeout
+}
+
+// SetFactomdRetryPolicy sets the RetryPolicy used for factomd requests and
+// whether requests which write to the network are also retried.
+func SetFactomdRetryPolicy(policy RetryPolicy, retryWrites bool) {
+	RpcConfig.FactomdRetryPolicy = policy
+	RpcConfig.FactomdRetryWrites = retryWrites
+}
+
+func GetFactomdRetryPolicy() (RetryPolicy, bool) {
+	return RpcConfig.FactomdRetryPolicy, RpcConfig.FactomdRetryWrites
+}
+
 // SetFactomdServer sets where to find the factomd server, and tells the server its public ip
 func SetFactomdServer(s string) {
 	RpcConfig.FactomdServer = s
@@ -211,42 +330,97 @@ func WalletServer() string {
 
 // SendFactomdRequest sends a json object to factomd
 func SendFactomdRequest(req *JSON2Request) (*JSON2Response, error) {
-	return factomdRequest(req)
+	return DefaultClient.SendFactomdRequest(context.Background(), req)
+}
+
+// SendFactomdRequest sends a json object to factomd
+func (c *Client) SendFactomdRequest(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	return c.factomdRequest(ctx, req)
 }
 
 // factomdRequest sends a JSON RPC request to the factomd API server and returns
 // the corresponding API response.
-func factomdRequest(req *JSON2Request) (*JSON2Response, error) {
-	j, err := json.Marshal(req)
-	if err != nil {
+func (c *Client) factomdRequest(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
+	var r *JSON2Response
+	err := c.retry(ctx, []*JSON2Request{req}, func() error {
+		r = nil
+		body, err := c.factomdPost(ctx, []*JSON2Request{req}, req)
+		if err != nil {
+			return err
+		}
+
+		r = NewJSON2Response()
+		if err := json.Unmarshal(body, r); err != nil {
+			r = nil
+			return err
+		}
+		if r.Error != nil {
+			return r.Error
+		}
+		return nil
+	})
+	// JSON RPC errors are returned to the caller in the response
+	if err != nil && (r == nil || r.Error == nil) {
 		return nil, err
 	}
 
-	factomdTls, factomdCertPath := GetFactomdEncryption()
+	return r, nil
+}
 
-	var client *http.Client
-	var scheme, host string
+// factomdPost sends a JSON encoded list of requests to a factomd API server and
+// returns the body of the HTTP response. If several factomd servers are
+// configured the request is sent to the preferred server, failing over to the
+// others on network and HTTP errors.
+func (c *Client) factomdPost(ctx context.Context, reqs []*JSON2Request, v interface{}) ([]byte, error) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
 
-	if factomdTls == true {
-		caCert, err := ioutil.ReadFile(factomdCertPath)
-		if err != nil {
+	servers := c.factomdServers(ctx, !hasWriteMethod(reqs))
+	if len(servers) == 1 {
+		return c.factomdPostTo(ctx, servers[0], j)
+	}
+
+	// requests which write to the network are only sent to a second server if
+	// they may be retried
+	failover := !hasWriteMethod(reqs) || c.config().FactomdRetryWrites
+	for i, server := range servers {
+		body, err := c.factomdPostTo(ctx, server, j)
+		if err == nil || !isNodeError(err) {
+			return body, err
+		}
+		c.markFactomdNode(server, err)
+		if !failover || i == len(servers)-1 || ctx.Err() != nil {
 			return nil, err
 		}
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: caCertPool}}
-		client = &http.Client{Transport: tr, Timeout: GetFactomdTimeout()}
+	}
+	return nil, fmt.Errorf("No factomd server configured")
+}
+
+// factomdPostTo sends a JSON encoded request body to the given factomd API
+// server and returns the body of the HTTP response.
+func (c *Client) factomdPostTo(ctx context.Context, server string, j []byte) ([]byte, error) {
+	cfg := c.config()
+
+	client, err := cfg.factomdHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var scheme, host string
+
+	if cfg.FactomdTLSEnable == true {
 		scheme = "https"
-		host = RpcConfig.FactomdServer
+		host = server
 
 	} else {
-		client = &http.Client{Timeout: GetFactomdTimeout()}
-		if index := strings.Index(RpcConfig.FactomdServer, "://"); index != -1 {
-			scheme = RpcConfig.FactomdServer[0:index]
-			host = RpcConfig.FactomdServer[index+3:]
+		if index := strings.Index(server, "://"); index != -1 {
+			scheme = server[0:index]
+			host = server[index+3:]
 		} else {
 			scheme = "http"
-			host = RpcConfig.FactomdServer
+			host = server
 		}
 	}
 	re, err := http.NewRequest(
@@ -257,9 +431,9 @@ func factomdRequest(req *JSON2Request) (*JSON2Response, error) {
 	if err != nil {
 		return nil, err
 	}
+	re = re.WithContext(ctx)
 
-	user, pass := GetFactomdRpcConfig()
-	re.SetBasicAuth(user, pass)
+	re.SetBasicAuth(cfg.FactomdRPCUser, cfg.FactomdRPCPassword)
 	re.Header.Add("Content-Type", "application/json")
 	resp, err := client.Do(re)
 	if err != nil {
@@ -278,54 +452,47 @@ func factomdRequest(req *JSON2Request) (*JSON2Response, error) {
 	if resp.StatusCode == http.StatusUnauthorized {
 		return nil, fmt.Errorf("Factomd username/password incorrect.  Edit factomd.conf or\ncall factom-cli with -factomduser=<user> -factomdpassword=<pass>")
 	}
-	r := NewJSON2Response()
-	if err := json.Unmarshal(body, r); err != nil {
-		return nil, err
+	if resp.StatusCode >= 500 && !json.Valid(body) {
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
-	return r, nil
+	return body, nil
 }
 
 // walletRequest sends a JSON RPC request to the factom wallet API server and
 // returns the corresponding API response.
-func walletRequest(req *JSON2Request) (*JSON2Response, error) {
+func (c *Client) walletRequest(ctx context.Context, req *JSON2Request) (*JSON2Response, error) {
 	j, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	walletTls, walletCertPath := GetWalletEncryption()
+	cfg := c.config()
 
-	var client *http.Client
-	var httpx string
+	client, err := cfg.walletHTTPClient()
+	if err != nil {
+		return nil, err
+	}
 
-	if walletTls == true {
-		caCert, err := ioutil.ReadFile(walletCertPath)
-		if err != nil {
-			return nil, err
-		}
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: caCertPool}}
+	var httpx string
 
-		client = &http.Client{Transport: tr, Timeout: GetWalletTimeout()}
+	if cfg.WalletTLSEnable == true {
 		httpx = "https"
 	} else {
-		client = &http.Client{Timeout: GetWalletTimeout()}
 		httpx = "http"
 	}
 
 	re, err := http.NewRequest(
 		"POST",
-		fmt.Sprintf("%s://%s/v2", httpx, RpcConfig.WalletServer),
+		fmt.Sprintf("%s://%s/v2", httpx, cfg.WalletServer),
 		bytes.NewBuffer(j),
 	)
 	if err != nil {
 		return nil, err
 	}
+	re = re.WithContext(ctx)
 
-	user, pass := GetWalletRpcConfig()
-	re.SetBasicAuth(user, pass)
+	re.SetBasicAuth(cfg.WalletRPCUser, cfg.WalletRPCPassword)
 	re.Header.Add("Content-Type", "application/json")
 	resp, err := client.Do(re)
 	if err != nil {
@@ -354,10 +521,9 @@ func walletRequest(req *JSON2Request) (*JSON2Response, error) {
 
 // newCounter is used to generate the ID field for the JSON2Request
 func newCounter() func() int {
-	count := 0
+	var count int64
 	return func() int {
-		count += 1
-		return count
+		return int(atomic.AddInt64(&count, 1))
 	}
 }
 
@@ -0,0 +1,61 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build go1.13
+// +build go1.13
+
+package factom_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/FactomProject/factom"
+
+	"testing"
+)
+
+// the API errors match their sentinels through wrapping errors
+func TestJSONErrorWrapped(t *testing.T) {
+	newErrorServer := func(code int, message string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":0,"error":{"code":%d,"message":%q}}`, code, message)
+		}))
+	}
+	factomd := newErrorServer(-32008, "Entry not found")
+	defer factomd.Close()
+	walletd := newErrorServer(-32001, "Wallet is locked")
+	defer walletd.Close()
+
+	c := NewClient(&RPCConfig{FactomdServer: factomd.URL[7:], WalletServer: walletd.URL[7:]})
+	_, err := c.GetEntry(context.Background(), ZeroHash)
+	err = fmt.Errorf("fetching entry: %w", err)
+	for _, target := range []error{ErrEntryNotFound, ErrObjectNotFound} {
+		if !errors.Is(err, target) {
+			t.Errorf("expected %q to match %q", err, target)
+		}
+	}
+	for _, target := range []error{ErrBlockNotFound, ErrMissingChainHead, ErrWalletIsLocked} {
+		if errors.Is(err, target) {
+			t.Errorf("expected %q not to match %q", err, target)
+		}
+	}
+
+	_, err = c.GetWalletHeight(context.Background())
+	err = fmt.Errorf("wallet height: %w", err)
+	if !errors.Is(err, ErrWalletIsLocked) {
+		t.Errorf("expected %q to match %q", err, ErrWalletIsLocked)
+	}
+	if errors.Is(err, ErrIncorrectPassphrase) {
+		t.Errorf("expected %q not to match %q", err, ErrIncorrectPassphrase)
+	}
+	var jerr *JSONError
+	if !errors.As(err, &jerr) || jerr.Code != ErrWalletIsLocked.Code {
+		t.Errorf("expected a JSONError, recieved %v", err)
+	}
+}
@@ -6,8 +6,11 @@ package factom_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 
 	. "github.com/FactomProject/factom"
 
@@ -85,3 +88,34 @@ func TestJSON2Response(t *testing.T) {
 		t.Error(e)
 	}
 }
+
+func TestJSONErrorIs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"jsonrpc":"2.0","id":0,"error":{"code":-32008,"message":"Entry not found"}}`)
+	}))
+	defer ts.Close()
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+	_, err := c.GetEntry(context.Background(), ZeroHash)
+	jerr, ok := err.(*JSONError)
+	if !ok {
+		t.Fatalf("expected a JSONError, recieved %v", err)
+	}
+
+	for _, target := range []error{ErrEntryNotFound, ErrObjectNotFound} {
+		if !jerr.Is(target) {
+			t.Errorf("expected %q to match %q", jerr, target)
+		}
+	}
+	for _, target := range []error{ErrBlockNotFound, ErrMissingChainHead, ErrWalletIsLocked, fmt.Errorf("Entry not found")} {
+		if jerr.Is(target) {
+			t.Errorf("expected %q not to match %q", jerr, target)
+		}
+	}
+
+	withData := NewJSONError(-32602, "Invalid params", "Invalid Address")
+	if !withData.Is(ErrInvalidParams) {
+		t.Errorf("expected %q to match %q", withData, ErrInvalidParams)
+	}
+}
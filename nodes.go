@@ -0,0 +1,230 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultFactomdHealthInterval is the default time between health checks of
+// the factomd servers in RPCConfig.FactomdServers.
+const DefaultFactomdHealthInterval = 30 * time.Second
+
+// FactomdNode is the last known status of a factomd API server.
+type FactomdNode struct {
+	Server  string
+	Heights *HeightsResponse
+	Err     error
+	Checked time.Time
+}
+
+// Healthy returns true if the last request to the node succeeded.
+func (n *FactomdNode) Healthy() bool {
+	return n.Err == nil && n.Heights != nil
+}
+
+func (n *FactomdNode) String() string {
+	if n.Err != nil {
+		return fmt.Sprintf("%s: %v", n.Server, n.Err)
+	}
+	if n.Heights == nil {
+		return fmt.Sprintf("%s: unchecked", n.Server)
+	}
+	return fmt.Sprintf("%s: height %d", n.Server, n.Heights.DirectoryBlockHeight)
+}
+
+// nodeState is the health of the factomd servers used by a Client.
+type nodeState struct {
+	sync.Mutex
+	checked  time.Time
+	checking chan struct{} // closed when the health check in flight is done
+	nodes    map[string]*FactomdNode
+	next     int
+}
+
+// CheckFactomdNodes checks the health of every configured factomd server and
+// returns their status in the configured order.
+func CheckFactomdNodes() []*FactomdNode {
+	return DefaultClient.CheckFactomdNodes(context.Background())
+}
+
+// CheckFactomdNodes checks the health of every configured factomd server and
+// returns their status in the configured order.
+func (c *Client) CheckFactomdNodes(ctx context.Context) []*FactomdNode {
+	servers := c.config().FactomdServers
+	if len(servers) == 0 {
+		servers = []string{c.config().FactomdServer}
+	}
+
+	c.nodes.Lock()
+	c.nodes.checked = time.Now()
+	c.nodes.Unlock()
+
+	status := make([]*FactomdNode, len(servers))
+	wg := new(sync.WaitGroup)
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, server string) {
+			defer wg.Done()
+			n := &FactomdNode{Server: server}
+			n.Heights, n.Err = c.nodeClient(server).GetHeights(ctx)
+			n.Checked = time.Now()
+			status[i] = n
+		}(i, server)
+	}
+	wg.Wait()
+
+	c.nodes.Lock()
+	defer c.nodes.Unlock()
+	if c.nodes.nodes == nil {
+		c.nodes.nodes = make(map[string]*FactomdNode)
+	}
+	for _, n := range status {
+		// a canceled check says nothing about the node
+		if n.Err != nil && ctx.Err() != nil {
+			continue
+		}
+		c.nodes.nodes[n.Server] = n
+	}
+
+	return status
+}
+
+// nodeClient returns a Client sending requests to a single factomd server with
+// the Client's configuration.
+func (c *Client) nodeClient(server string) *Client {
+	cfg := *c.config()
+	cfg.FactomdServer = server
+	cfg.FactomdServers = nil
+	cfg.FactomdRetryPolicy = nil
+	return NewClient(&cfg)
+}
+
+// factomdServers returns the configured factomd servers in the order they
+// should be tried. Healthy servers come first, the servers that have synced
+// furthest ahead of the others, followed by the unchecked and then the
+// unhealthy servers. Reads are spread over the furthest synced servers if
+// FactomdRoundRobin is set.
+//
+// When the health of the servers is stale a single check is started in the
+// background and the last known health is used meanwhile. Only the requests
+// made before the first check is done wait for it.
+func (c *Client) factomdServers(ctx context.Context, read bool) []string {
+	cfg := c.config()
+	if len(cfg.FactomdServers) == 0 {
+		return []string{cfg.FactomdServer}
+	}
+	if len(cfg.FactomdServers) == 1 {
+		return cfg.FactomdServers
+	}
+
+	interval := cfg.FactomdHealthInterval
+	if interval <= 0 {
+		interval = DefaultFactomdHealthInterval
+	}
+	c.nodes.Lock()
+	done := c.nodes.checking
+	if done == nil && time.Since(c.nodes.checked) > interval {
+		done = make(chan struct{})
+		c.nodes.checking = done
+		go c.checkFactomdNodes(done, interval)
+	}
+	unknown := c.nodes.nodes == nil
+	c.nodes.Unlock()
+	if unknown && done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+
+	c.nodes.Lock()
+	defer c.nodes.Unlock()
+
+	rank := func(server string) (int, int64) {
+		n, ok := c.nodes.nodes[server]
+		switch {
+		case !ok:
+			return 1, 0
+		case n.Healthy():
+			return 0, n.Heights.DirectoryBlockHeight
+		case n.Err == nil:
+			return 1, 0
+		}
+		return 2, 0
+	}
+
+	servers := append([]string{}, cfg.FactomdServers...)
+	sort.SliceStable(servers, func(i, j int) bool {
+		ri, hi := rank(servers[i])
+		rj, hj := rank(servers[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return hi > hj
+	})
+
+	if read && cfg.FactomdRoundRobin {
+		r, top := rank(servers[0])
+		n := 1
+		for ; n < len(servers); n++ {
+			if rn, h := rank(servers[n]); rn != r || h != top {
+				break
+			}
+		}
+		if n > 1 {
+			k := c.nodes.next % n
+			c.nodes.next++
+			lead := append([]string{}, servers[k:n]...)
+			lead = append(lead, servers[:k]...)
+			copy(servers, lead)
+		}
+	}
+
+	return servers
+}
+
+// checkFactomdNodes runs a background health check, taking at most timeout,
+// and closes done when it is finished.
+func (c *Client) checkFactomdNodes(done chan struct{}, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	c.CheckFactomdNodes(ctx)
+
+	c.nodes.Lock()
+	c.nodes.checking = nil
+	c.nodes.Unlock()
+	close(done)
+}
+
+// markFactomdNode records a failed request to a factomd server. The server is
+// tried last until the next health check finds it healthy again.
+func (c *Client) markFactomdNode(server string, err error) {
+	c.nodes.Lock()
+	defer c.nodes.Unlock()
+	if c.nodes.nodes == nil {
+		c.nodes.nodes = make(map[string]*FactomdNode)
+	}
+	c.nodes.nodes[server] = &FactomdNode{Server: server, Err: err, Checked: time.Now()}
+}
+
+// isNodeError returns true for errors caused by the factomd server being
+// unreachable or failing rather than by the request itself.
+func isNodeError(err error) bool {
+	switch e := err.(type) {
+	case *url.Error:
+		// any failure to complete the HTTP request, such as a refused or
+		// dropped connection, unless the request was canceled
+		return e.Err != context.Canceled && e.Err != context.DeadlineExceeded
+	case *HTTPError:
+		return e.StatusCode >= 500
+	}
+	return false
+}
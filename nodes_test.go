@@ -0,0 +1,159 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/FactomProject/factom"
+
+	"testing"
+)
+
+// newNodeTestServer returns a factomd stand-in at the given Directory Block
+// height that counts the ec rate requests it answers.
+func newNodeTestServer(height int64, rates *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		json.NewDecoder(r.Body).Decode(req)
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Method {
+		case "heights":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":0,"result":{"directoryblockheight":%d}}`, height)
+		default:
+			atomic.AddInt32(rates, 1)
+			fmt.Fprintln(w, `{"jsonrpc":"2.0","id":0,"result":{"rate":95369}}`)
+		}
+	}))
+}
+
+func TestFactomdFailover(t *testing.T) {
+	var behind, ahead int32
+	ts1 := newNodeTestServer(10, &behind)
+	defer ts1.Close()
+	ts2 := newNodeTestServer(11, &ahead)
+	defer ts2.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	c := NewClient(&RPCConfig{
+		FactomdServers: []string{down.URL[7:], ts1.URL[7:], ts2.URL[7:]},
+	})
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetECRate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if atomic.LoadInt32(&ahead) != 3 || atomic.LoadInt32(&behind) != 0 {
+		t.Errorf("expected requests to go to the furthest synced node, recieved %d %d", behind, ahead)
+	}
+
+	nodes := c.CheckFactomdNodes(context.Background())
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 nodes, recieved %d", len(nodes))
+	}
+	if nodes[0].Healthy() || !nodes[1].Healthy() || !nodes[2].Healthy() {
+		t.Errorf("unexpected node health: %v", nodes)
+	}
+
+	// the preferred node fails between health checks
+	ts2.Close()
+	if _, err := c.GetECRate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&behind); n != 1 {
+		t.Errorf("expected failover to the remaining node, recieved %d requests", n)
+	}
+}
+
+func TestFactomdRoundRobin(t *testing.T) {
+	var a, b, lagging int32
+	ts1 := newNodeTestServer(10, &a)
+	defer ts1.Close()
+	ts2 := newNodeTestServer(10, &b)
+	defer ts2.Close()
+	ts3 := newNodeTestServer(9, &lagging)
+	defer ts3.Close()
+
+	c := NewClient(&RPCConfig{
+		FactomdServers:        []string{ts3.URL[7:], ts1.URL[7:], ts2.URL[7:]},
+		FactomdRoundRobin:     true,
+		FactomdHealthInterval: time.Hour,
+	})
+	for i := 0; i < 10; i++ {
+		if _, err := c.GetECRate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a != 5 || b != 5 || lagging != 0 {
+		t.Errorf("expected requests spread over the synced nodes, recieved %d %d %d", a, b, lagging)
+	}
+}
+
+func TestFactomdHealthCheckBackground(t *testing.T) {
+	var heights, rates int32
+	block := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		req := new(JSON2Request)
+		json.NewDecoder(r.Body).Decode(req)
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Method {
+		case "heights":
+			if atomic.AddInt32(&heights, 1) > 2 {
+				<-block
+			}
+			fmt.Fprintln(w, `{"jsonrpc":"2.0","id":0,"result":{"directoryblockheight":10}}`)
+		default:
+			atomic.AddInt32(&rates, 1)
+			fmt.Fprintln(w, `{"jsonrpc":"2.0","id":0,"result":{"rate":95369}}`)
+		}
+	}
+	ts1 := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts1.Close()
+	ts2 := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts2.Close()
+	defer close(block)
+
+	c := NewClient(&RPCConfig{
+		FactomdServers:        []string{ts1.URL[7:], ts2.URL[7:]},
+		FactomdHealthInterval: 50 * time.Millisecond,
+	})
+	if _, err := c.GetECRate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the stale health is checked once, without holding up the requests
+	time.Sleep(100 * time.Millisecond)
+	errs := make(chan error)
+	for i := 0; i < 10; i++ {
+		go func() {
+			_, err := c.GetECRate(context.Background())
+			errs <- err
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("requests waited for the health check")
+		}
+	}
+	for deadline := time.Now().Add(5 * time.Second); atomic.LoadInt32(&heights) < 4 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&heights); n != 4 {
+		t.Errorf("expected a single health check in flight, recieved %d heights requests", n)
+	}
+}
@@ -0,0 +1,635 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrEntryQueued is returned when writing an Entry that is already in the
+	// Outbox.
+	ErrEntryQueued = errors.New("entry is already in the outbox")
+	// ErrRevealAttempts is reported when an Entry is dropped from the Outbox
+	// after MaxRevealAttempts failed reveals.
+	ErrRevealAttempts = errors.New("too many failed reveal attempts")
+	// ErrCommitExpired is reported when an Entry is dropped from the Outbox
+	// because its commit is older than factomd accepts and the Entry was
+	// never acknowledged.
+	ErrCommitExpired = errors.New("entry commit has expired")
+)
+
+// DefaultEntryWriterInterval is the default time between passes of an
+// EntryWriter over its Outbox.
+const DefaultEntryWriterInterval = 5 * time.Second
+
+// commitWindow is how long factomd accepts a commit after it is signed.
+const commitWindow = time.Hour
+
+// WriteState is the progress of an Entry through an EntryWriter.
+type WriteState int
+
+const (
+	// WriteQueued Entries are stored in the Outbox but not yet committed.
+	WriteQueued WriteState = iota
+	// WriteCommitted Entries have been committed but not revealed.
+	WriteCommitted
+	// WriteRevealed Entries have been revealed and wait for factomd to
+	// acknowledge them.
+	WriteRevealed
+	// WriteConfirmed Entries are acknowledged by factomd and have been
+	// removed from the Outbox.
+	WriteConfirmed
+	// WriteFailed Entries were rejected by factomd and have been removed
+	// from the Outbox.
+	WriteFailed
+)
+
+func (s WriteState) String() string {
+	switch s {
+	case WriteQueued:
+		return "Queued"
+	case WriteCommitted:
+		return "Committed"
+	case WriteRevealed:
+		return "Revealed"
+	case WriteConfirmed:
+		return "Confirmed"
+	case WriteFailed:
+		return "Failed"
+	}
+	return fmt.Sprintf("WriteState(%d)", int(s))
+}
+
+// WriteStatus reports the progress of an Entry written by an EntryWriter.
+type WriteStatus struct {
+	EntryHash string
+	ChainID   string
+	// NewChain is true if the Entry is the First Entry of a new Chain.
+	NewChain bool
+	// TxID is the commit Transaction ID once factomd has accepted the commit.
+	TxID  string
+	State WriteState
+	// Attempts is the number of failed reveals of the Entry, including
+	// reveals that factomd accepted but later did not know.
+	Attempts int
+	// Err is the error of the last request for the Entry, if any.
+	Err error
+}
+
+func (s *WriteStatus) String() string {
+	var r string
+	r += fmt.Sprintln("EntryHash:", s.EntryHash)
+	r += fmt.Sprintln("ChainID:", s.ChainID)
+	r += fmt.Sprintln("TxID:", s.TxID)
+	r += fmt.Sprintln("State:", s.State)
+	if s.Err != nil {
+		r += fmt.Sprintln("Error:", s.Err)
+	}
+	return r
+}
+
+// outboxRecord is an Entry stored in an Outbox with its signed commit.
+type outboxRecord struct {
+	Entry    string     `json:"entry"`  // hex binary Entry
+	Commit   string     `json:"commit"` // hex commit-entry or commit-chain message
+	NewChain bool       `json:"newchain"`
+	TxID     string     `json:"txid,omitempty"`
+	State    WriteState `json:"state"`
+	Attempts int        `json:"attempts"`
+	Created  int64      `json:"created"` // unix nanoseconds
+
+	entry *Entry
+}
+
+func (r *outboxRecord) status(err error) *WriteStatus {
+	return &WriteStatus{
+		EntryHash: hex.EncodeToString(r.entry.Hash()),
+		ChainID:   r.entry.ChainID,
+		NewChain:  r.NewChain,
+		TxID:      r.TxID,
+		State:     r.State,
+		Attempts:  r.Attempts,
+		Err:       err,
+	}
+}
+
+// expired returns true if factomd no longer accepts the signed commit.
+func (r *outboxRecord) expired() bool {
+	msg, err := hex.DecodeString(r.Commit)
+	if err != nil || len(msg) < 7 {
+		return false
+	}
+	br := &binaryReader{data: msg[1:7]}
+	signed := time.Unix(0, br.milliTime()*int64(time.Millisecond))
+	return time.Since(signed) > commitWindow
+}
+
+// Outbox is a directory of Entries waiting to be committed, revealed, and
+// acknowledged. Each Entry is stored with its signed commit message in a file
+// named by its Entry Hash, and every change is written to a new file that
+// replaces the old one, so the Outbox survives the process stopping at any
+// point.
+//
+// The commit messages carry the time they were signed, and factomd rejects
+// commits more than an hour old, so an Outbox should not be left unprocessed
+// for longer than that.
+type Outbox struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// OpenOutbox opens the Outbox in a directory, creating the directory if it
+// does not exist.
+func OpenOutbox(dir string) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Outbox{dir: dir}, nil
+}
+
+// Pending returns the status of every Entry in the Outbox in the order they
+// were written.
+func (o *Outbox) Pending() ([]*WriteStatus, error) {
+	rs, err := o.list()
+	if err != nil {
+		return nil, err
+	}
+	ss := make([]*WriteStatus, 0, len(rs))
+	for _, r := range rs {
+		ss = append(ss, r.status(nil))
+	}
+	return ss, nil
+}
+
+func (o *Outbox) path(entryhash string) string {
+	return filepath.Join(o.dir, entryhash+".json")
+}
+
+// add stores a new record, failing if its Entry is already in the Outbox.
+func (o *Outbox) add(r *outboxRecord) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, err := os.Stat(o.path(hex.EncodeToString(r.entry.Hash()))); err == nil {
+		return ErrEntryQueued
+	}
+	return o.write(r)
+}
+
+// save replaces the stored record of an Entry.
+func (o *Outbox) save(r *outboxRecord) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.write(r)
+}
+
+func (o *Outbox) write(r *outboxRecord) error {
+	p, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(o.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), o.path(hex.EncodeToString(r.entry.Hash()))); err != nil {
+		return err
+	}
+	return o.syncDir()
+}
+
+// syncDir flushes the directory so that renamed and removed records survive
+// a crash. Windows cannot sync a directory, and does not need to.
+func (o *Outbox) syncDir() error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(o.dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// remove deletes the record of an Entry.
+func (o *Outbox) remove(r *outboxRecord) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	err := os.Remove(o.path(hex.EncodeToString(r.entry.Hash())))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return o.syncDir()
+}
+
+// list returns the stored records in the order they were written.
+func (o *Outbox) list() ([]*outboxRecord, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	names, err := filepath.Glob(filepath.Join(o.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	rs := make([]*outboxRecord, 0, len(names))
+	for _, name := range names {
+		p, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		r := new(outboxRecord)
+		if err := json.Unmarshal(p, r); err != nil {
+			return nil, fmt.Errorf("outbox record %s: %v", filepath.Base(name), err)
+		}
+		data, err := hex.DecodeString(r.Entry)
+		if err != nil {
+			return nil, fmt.Errorf("outbox record %s: %v", filepath.Base(name), err)
+		}
+		r.entry = new(Entry)
+		if err := r.entry.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("outbox record %s: %v", filepath.Base(name), err)
+		}
+		if hex.EncodeToString(r.entry.Hash()) != strings.TrimSuffix(filepath.Base(name), ".json") {
+			return nil, fmt.Errorf("outbox record %s: entry hash mismatch", filepath.Base(name))
+		}
+		rs = append(rs, r)
+	}
+
+	sort.SliceStable(rs, func(i, j int) bool {
+		return rs[i].Created < rs[j].Created
+	})
+	return rs, nil
+}
+
+// EntryWriter commits and reveals Entries through an Outbox, so that an Entry
+// that has been paid for is revealed even if the process stops between the
+// commit and the reveal.
+//
+// Entries are stored in the Outbox with their signed commit before anything
+// is sent to factomd. Run then commits them, reveals them, and checks the
+// reveals with EntryRevealACK, retrying failed reveals until factomd
+// acknowledges the Entry. A commit that factomd reports as repeated is taken
+// to have been sent before the process stopped. Only commits that factomd
+// rejects as invalid, or that have expired, are dropped from the Outbox;
+// other errors are retried on the next pass.
+type EntryWriter struct {
+	// Interval is the time between passes over the Outbox, or
+	// DefaultEntryWriterInterval if zero.
+	Interval time.Duration
+
+	// RequestInterval is the minimum time between requests to factomd.
+	RequestInterval time.Duration
+
+	// MaxRevealAttempts is the number of failed reveals after which an Entry
+	// is dropped from the Outbox with ErrRevealAttempts. A reveal that factomd
+	// accepts but later does not know counts as failed. Zero retries until
+	// the commit expires.
+	MaxRevealAttempts int
+
+	// WaitForDBlock keeps Entries in the Outbox until they are in a
+	// Directory Block, instead of only until factomd acknowledges them.
+	WaitForDBlock bool
+
+	c      *Client
+	ec     *ECAddress
+	outbox *Outbox
+	wake   chan struct{}
+	last   time.Time // time of the last request
+}
+
+// NewEntryWriter returns an EntryWriter paying for Entries with an Entry
+// Credit Address.
+func NewEntryWriter(outbox *Outbox, ec *ECAddress) *EntryWriter {
+	return DefaultClient.NewEntryWriter(outbox, ec)
+}
+
+// NewEntryWriter returns an EntryWriter paying for Entries with an Entry
+// Credit Address.
+func (c *Client) NewEntryWriter(outbox *Outbox, ec *ECAddress) *EntryWriter {
+	w := new(EntryWriter)
+	w.c = c
+	w.ec = ec
+	w.outbox = outbox
+	w.wake = make(chan struct{}, 1)
+	return w
+}
+
+// WriteEntry signs the commit of an Entry and stores it in the Outbox to be
+// sent by Run. It returns the Entry Hash.
+func (w *EntryWriter) WriteEntry(e *Entry) (string, error) {
+	req, err := ComposeEntryCommit(e, w.ec)
+	if err != nil {
+		return "", err
+	}
+	return w.add(e, req, false)
+}
+
+// WriteChain signs the commit of a new Chain and stores its First Entry in
+// the Outbox to be sent by Run. It returns the Entry Hash of the First Entry.
+func (w *EntryWriter) WriteChain(ch *Chain) (string, error) {
+	req, err := ComposeChainCommit(ch, w.ec)
+	if err != nil {
+		return "", err
+	}
+	return w.add(ch.FirstEntry, req, true)
+}
+
+func (w *EntryWriter) add(e *Entry, req *JSON2Request, newChain bool) (string, error) {
+	data, err := e.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	params := new(messageRequest)
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		return "", err
+	}
+
+	r := &outboxRecord{
+		Entry:    hex.EncodeToString(data),
+		Commit:   params.Message,
+		NewChain: newChain,
+		Created:  time.Now().UnixNano(),
+		entry:    e,
+	}
+	if err := w.outbox.add(r); err != nil {
+		return "", err
+	}
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return hex.EncodeToString(e.Hash()), nil
+}
+
+// Run sends the Entries in the Outbox to factomd until the context is done,
+// sending the status of an Entry to updates, if it is not nil, every time the
+// Entry changes state or a request for it fails. Errors reading or writing
+// the Outbox stop Run.
+func (w *EntryWriter) Run(ctx context.Context, updates chan<- *WriteStatus) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultEntryWriterInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		rs, err := w.outbox.list()
+		if err != nil {
+			return err
+		}
+		for _, r := range rs {
+			st, err := w.process(ctx, r)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				return err
+			}
+			if st == nil || updates == nil {
+				continue
+			}
+			select {
+			case updates <- st:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.wake:
+		case <-t.C:
+		}
+	}
+}
+
+// process moves an Entry through its next state, returning its status if
+// there is something to report, or an error if the Outbox could not be
+// updated.
+func (w *EntryWriter) process(ctx context.Context, r *outboxRecord) (*WriteStatus, error) {
+	switch r.State {
+	case WriteQueued:
+		return w.commit(ctx, r)
+	case WriteCommitted:
+		return w.reveal(ctx, r)
+	case WriteRevealed:
+		return w.check(ctx, r)
+	}
+	return nil, nil
+}
+
+func (w *EntryWriter) commit(ctx context.Context, r *outboxRecord) (*WriteStatus, error) {
+	if r.expired() {
+		// the commit may have been sent before the process stopped
+		if st, err := w.ack(ctx, r); err == nil && isAcknowledged(st.EntryData.Status) {
+			return w.acknowledged(r, st)
+		}
+		r.State = WriteFailed
+		return r.status(ErrCommitExpired), w.outbox.remove(r)
+	}
+
+	method := "commit-entry"
+	if r.NewChain {
+		method = "commit-chain"
+	}
+	req := NewJSON2Request(method, APICounter(), messageRequest{Message: r.Commit})
+	result := new(struct {
+		TxID string `json:"txid"`
+	})
+	err := w.request(ctx, req, result)
+	if jerr, ok := err.(*JSONError); ok && jerr.Is(ErrRepeatedCommit) {
+		err = nil
+	}
+	if err != nil {
+		if isCommitRejected(err) {
+			// factomd rejected the commit so the Entry was never paid for
+			r.State = WriteFailed
+			return r.status(err), w.outbox.remove(r)
+		}
+		return r.status(err), nil
+	}
+
+	r.TxID = result.TxID
+	r.State = WriteCommitted
+	if err := w.outbox.save(r); err != nil {
+		return nil, err
+	}
+	return r.status(nil), nil
+}
+
+func (w *EntryWriter) reveal(ctx context.Context, r *outboxRecord) (*WriteStatus, error) {
+	method := "reveal-entry"
+	if r.NewChain {
+		method = "reveal-chain"
+	}
+	req := NewJSON2Request(method, APICounter(), entryRequest{Entry: r.Entry})
+	if err := w.request(ctx, req, nil); err != nil {
+		// the Entry may have been revealed before the process stopped
+		if st, aerr := w.ack(ctx, r); aerr == nil && isAcknowledged(st.EntryData.Status) {
+			return w.acknowledged(r, st)
+		}
+		return w.retry(r, WriteCommitted, err)
+	}
+
+	r.State = WriteRevealed
+	if err := w.outbox.save(r); err != nil {
+		return nil, err
+	}
+	return r.status(nil), nil
+}
+
+// retry counts a failed reveal and moves the Entry back to state to be sent
+// again, or drops it from the Outbox once MaxRevealAttempts is reached or its
+// commit has expired.
+func (w *EntryWriter) retry(r *outboxRecord, state WriteState, err error) (*WriteStatus, error) {
+	r.Attempts++
+	if w.MaxRevealAttempts > 0 && r.Attempts >= w.MaxRevealAttempts {
+		r.State = WriteFailed
+		return r.status(ErrRevealAttempts), w.outbox.remove(r)
+	}
+	if r.expired() {
+		r.State = WriteFailed
+		return r.status(ErrCommitExpired), w.outbox.remove(r)
+	}
+	r.State = state
+	if err := w.outbox.save(r); err != nil {
+		return nil, err
+	}
+	return r.status(err), nil
+}
+
+// check asks factomd for the status of a revealed Entry, and sends it again
+// if factomd does not know it: the commit as well if factomd has lost it too.
+func (w *EntryWriter) check(ctx context.Context, r *outboxRecord) (*WriteStatus, error) {
+	st, err := w.ack(ctx, r)
+	if err != nil {
+		return r.status(err), nil
+	}
+	if isAcknowledged(st.EntryData.Status) {
+		return w.acknowledged(r, st)
+	}
+	if st.EntryData.Status != StatusUnknown {
+		return nil, nil
+	}
+
+	// factomd has lost the reveal
+	if st.CommitData.Status == StatusUnknown {
+		return w.retry(r, WriteQueued, nil)
+	}
+	return w.retry(r, WriteCommitted, nil)
+}
+
+// ack returns the status of the Entry reported by EntryRevealACK.
+func (w *EntryWriter) ack(ctx context.Context, r *outboxRecord) (*EntryStatus, error) {
+	params := ackRequest{Hash: hex.EncodeToString(r.entry.Hash()), ChainID: r.entry.ChainID}
+	req := NewJSON2Request("ack", APICounter(), params)
+	st := new(EntryStatus)
+	if err := w.request(ctx, req, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func isAcknowledged(status string) bool {
+	return status == StatusTransactionACK || status == StatusDBlockConfirmed
+}
+
+// isCommitRejected returns true for the errors factomd returns for a commit
+// that it will never accept.
+func isCommitRejected(err error) bool {
+	jerr, ok := err.(*JSONError)
+	if !ok {
+		return false
+	}
+	return jerr.Is(ErrInvalidParams) || jerr.Is(ErrInvalidRequest) || jerr.Is(ErrParse)
+}
+
+// acknowledged removes an Entry acknowledged by factomd from the Outbox, or
+// keeps it as revealed if the EntryWriter waits for it to be in a Directory
+// Block.
+func (w *EntryWriter) acknowledged(r *outboxRecord, st *EntryStatus) (*WriteStatus, error) {
+	txid := r.TxID
+	if txid == "" {
+		txid = st.CommitTxID
+	}
+	if st.EntryData.Status != StatusDBlockConfirmed && w.WaitForDBlock {
+		if r.State == WriteRevealed && r.TxID == txid {
+			return nil, nil
+		}
+		r.TxID = txid
+		r.State = WriteRevealed
+		if err := w.outbox.save(r); err != nil {
+			return nil, err
+		}
+		return r.status(nil), nil
+	}
+
+	r.TxID = txid
+	r.State = WriteConfirmed
+	return r.status(nil), w.outbox.remove(r)
+}
+
+// request sends a request to factomd no sooner than RequestInterval after the
+// last one, and decodes its result into v if v is not nil.
+func (w *EntryWriter) request(ctx context.Context, req *JSON2Request, v interface{}) error {
+	if wait := w.RequestInterval - time.Since(w.last); wait > 0 {
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	w.last = time.Now()
+
+	resp, err := w.c.factomdRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.JSONResult(), v)
+}
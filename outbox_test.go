@@ -0,0 +1,255 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/FactomProject/factom"
+	"github.com/FactomProject/factom/factomtest"
+)
+
+func TestEntryWriter(t *testing.T) {
+	s := factomtest.NewServer()
+	defer s.Close()
+	c := s.Client()
+
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ec, err := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetECBalance(ec.PubString(), 100)
+
+	waitFor := func(w *EntryWriter, entryhash string, state WriteState) {
+		runEntryWriter(t, w, func(st *WriteStatus) bool {
+			return st.EntryHash == entryhash && st.State == state
+		})
+	}
+
+	outbox, err := OpenOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := c.NewEntryWriter(outbox, ec)
+	w.Interval = 10 * time.Millisecond
+	w.RequestInterval = time.Millisecond
+
+	ch := NewChainFromStrings("outbox", "test", "chain")
+	chainHash, err := w.WriteChain(ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteChain(ch); err != ErrEntryQueued {
+		t.Errorf("expected %v, recieved %v", ErrEntryQueued, err)
+	}
+
+	// stop the process once the Chain is committed
+	waitFor(w, chainHash, WriteCommitted)
+
+	// commit the Entry without the Outbox, as if the commit was sent just
+	// before the process stopped
+	e := NewEntryFromStrings(ch.ChainID, "second entry")
+	entryHash, err := w.WriteEntry(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CommitEntry(context.Background(), e, ec); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := outbox.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].State != WriteCommitted || pending[0].TxID == "" ||
+		pending[1].State != WriteQueued {
+		t.Errorf("unexpected outbox %v", pending)
+	}
+
+	// resume from the Outbox on disk
+	outbox, err = OpenOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = c.NewEntryWriter(outbox, ec)
+	w.Interval = 10 * time.Millisecond
+	waitFor(w, entryHash, WriteConfirmed)
+
+	if pending, err := outbox.Pending(); err != nil || len(pending) != 0 {
+		t.Errorf("expected empty outbox, recieved %v %v", pending, err)
+	}
+	s.NextBlock()
+	es, err := c.GetAllChainEntries(context.Background(), ch.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 2 || string(es[1].Content) != "second entry" {
+		t.Errorf("unexpected chain entries %v", es)
+	}
+
+	// a commit rejected by factomd fails without spending Entry Credits
+	poor, err := MakeECAddress(bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = c.NewEntryWriter(outbox, poor)
+	w.Interval = 10 * time.Millisecond
+	failed, err := w.WriteEntry(NewEntryFromStrings(ch.ChainID, "unpaid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(w, failed, WriteFailed)
+	if pending, err := outbox.Pending(); err != nil || len(pending) != 0 {
+		t.Errorf("expected empty outbox, recieved %v %v", pending, err)
+	}
+}
+
+// runEntryWriter runs the EntryWriter until done returns true for a status.
+func runEntryWriter(t *testing.T, w *EntryWriter, done func(*WriteStatus) bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates := make(chan *WriteStatus)
+	stopped := make(chan error, 1)
+	go func() { stopped <- w.Run(ctx, updates) }()
+	for {
+		select {
+		case st := <-updates:
+			if done(st) {
+				cancel()
+				<-stopped
+				return
+			}
+		case err := <-stopped:
+			t.Fatalf("expected status, recieved %v", err)
+		}
+	}
+}
+
+// faultServer forwards requests to a factomtest Server, except those that
+// fault answers with the error or result JSON it returns.
+func faultServer(s *factomtest.Server, fault func(req *JSON2Request) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		req := new(JSON2Request)
+		json.Unmarshal(body, req)
+		w.Header().Set("Content-Type", "application/json")
+		if resp := fault(req); resp != "" {
+			id, _ := json.Marshal(req.ID)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,%s}`, id, resp)
+			return
+		}
+		fwd, err := http.Post(s.URL(), "application/json", bytes.NewReader(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer fwd.Body.Close()
+		p, _ := ioutil.ReadAll(fwd.Body)
+		w.Write(p)
+	}))
+}
+
+func TestEntryWriterRetry(t *testing.T) {
+	s := factomtest.NewServer()
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	outbox, err := OpenOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ec, err := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetECBalance(ec.PubString(), 100)
+	ch := NewChainFromStrings("outbox", "retry")
+	if _, err := s.Client().CommitChain(context.Background(), ch, ec); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Client().RevealChain(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	commitFaults, dropReveals := 1, false
+	ts := faultServer(s, func(req *JSON2Request) string {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case req.Method == "commit-entry" && commitFaults > 0:
+			commitFaults--
+			return `"error":{"code":-32603,"message":"Internal error"}`
+		case req.Method == "reveal-entry" && dropReveals:
+			return `"result":{"message":"Entry Reveal Success"}`
+		}
+		return ""
+	})
+	defer ts.Close()
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL})
+
+	// an internal error leaves the Entry queued to be committed again
+	w := c.NewEntryWriter(outbox, ec)
+	w.Interval = 10 * time.Millisecond
+	entryHash, err := w.WriteEntry(NewEntryFromStrings(ch.ChainID, "internal error"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failed *WriteStatus
+	runEntryWriter(t, w, func(st *WriteStatus) bool {
+		if st.State == WriteQueued && st.Err != nil {
+			failed = st
+		}
+		return st.State == WriteConfirmed || st.State == WriteFailed
+	})
+	if failed == nil || failed.EntryHash != entryHash {
+		t.Errorf("expected queued %s after internal error, recieved %v", entryHash, failed)
+	}
+	if pending, err := outbox.Pending(); err != nil || len(pending) != 0 {
+		t.Errorf("expected empty outbox, recieved %v %v", pending, err)
+	}
+
+	// reveals that factomd accepts but never knows count as failed
+	mu.Lock()
+	dropReveals = true
+	mu.Unlock()
+	w.MaxRevealAttempts = 3
+	lost, err := w.WriteEntry(NewEntryFromStrings(ch.ChainID, "lost reveal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last *WriteStatus
+	runEntryWriter(t, w, func(st *WriteStatus) bool {
+		last = st
+		return st.State == WriteConfirmed || st.State == WriteFailed
+	})
+	if last.EntryHash != lost || last.State != WriteFailed || last.Err != ErrRevealAttempts || last.Attempts != 3 {
+		t.Errorf("expected %s failed after 3 attempts, recieved %v", lost, last)
+	}
+	if pending, err := outbox.Pending(); err != nil || len(pending) != 0 {
+		t.Errorf("expected empty outbox, recieved %v %v", pending, err)
+	}
+}
@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -48,13 +49,19 @@ func (p *Properties) String() string {
 // GetProperties requests various properties of the factomd and factom wallet
 // software and API versions.
 func GetProperties() (*Properties, error) {
+	return DefaultClient.GetProperties(context.Background())
+}
+
+// GetProperties requests various properties of the factomd and factom wallet
+// software and API versions.
+func (c *Client) GetProperties(ctx context.Context) (*Properties, error) {
 	// get properties from the factom API and the wallet API
 	props := new(Properties)
 	// wprops := new(PropertiesResponse)
 	req := NewJSON2Request("properties", APICounter(), nil)
 	wreq := NewJSON2Request("properties", APICounter(), nil)
 
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		props.FactomdVersionErr = err.Error()
 		return props, err
@@ -65,7 +72,7 @@ func GetProperties() (*Properties, error) {
 		return props, jerr
 	}
 
-	wresp, werr := walletRequest(wreq)
+	wresp, werr := c.walletRequest(ctx, wreq)
 	wprops := new(Properties)
 	if werr != nil {
 		props.WalletVersionErr = werr.Error()
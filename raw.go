@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 )
@@ -21,9 +22,15 @@ func (r *RawData) GetDataBytes() ([]byte, error) {
 // GetRaw requests the raw data for any binary block kept in the factomd
 // database.
 func GetRaw(keymr string) ([]byte, error) {
+	return DefaultClient.GetRaw(context.Background(), keymr)
+}
+
+// GetRaw requests the raw data for any binary block kept in the factomd
+// database.
+func (c *Client) GetRaw(ctx context.Context, keymr string) ([]byte, error) {
 	params := hashRequest{Hash: keymr}
 	req := NewJSON2Request("raw-data", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -42,9 +49,15 @@ func GetRaw(keymr string) ([]byte, error) {
 // SendRawMsg sends a raw hex encoded byte string for factomd to send as a
 // binary message on the Factom Netwrork.
 func SendRawMsg(message string) (string, error) {
+	return DefaultClient.SendRawMsg(context.Background(), message)
+}
+
+// SendRawMsg sends a raw hex encoded byte string for factomd to send as a
+// binary message on the Factom Netwrork.
+func (c *Client) SendRawMsg(ctx context.Context, message string) (string, error) {
 	param := messageRequest{Message: message}
 	req := NewJSON2Request("send-raw-message", APICounter(), param)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -5,6 +5,10 @@
 package factom
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 )
@@ -64,13 +68,18 @@ func (r *Receipt) String() string {
 
 // GetReceipt requests a Receipt for a given Factom Entry.
 func GetReceipt(hash string) (*Receipt, error) {
+	return DefaultClient.GetReceipt(context.Background(), hash)
+}
+
+// GetReceipt requests a Receipt for a given Factom Entry.
+func (c *Client) GetReceipt(ctx context.Context, hash string) (*Receipt, error) {
 	type receiptResponse struct {
 		Receipt *Receipt `json:"receipt"`
 	}
 
 	params := hashRequest{Hash: hash}
 	req := NewJSON2Request("receipt", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -85,3 +94,126 @@ func GetReceipt(hash string) (*Receipt, error) {
 
 	return rec.Receipt, nil
 }
+
+// Verify checks the Merkle proof of the Receipt without contacting factomd.
+// Every node of the MerkleBranch must hash its Left and Right children to its
+// Top, starting from the Entry Hash, passing through the EntryBlockKeyMR, and
+// ending at the DirectoryBlockKeyMR. If the Receipt includes the raw Entry it
+// must hash to the Entry Hash.
+func (r *Receipt) Verify() error {
+	current, err := hex.DecodeString(r.Entry.EntryHash)
+	if err != nil {
+		return fmt.Errorf("invalid receipt entry hash: %v", err)
+	}
+	if len(current) != 32 {
+		return fmt.Errorf("invalid receipt entry hash length %d", len(current))
+	}
+
+	if r.Entry.Raw != "" {
+		raw, err := hex.DecodeString(r.Entry.Raw)
+		if err != nil {
+			return fmt.Errorf("invalid receipt raw entry: %v", err)
+		}
+		if !bytes.Equal(sha52(raw), current) {
+			return fmt.Errorf("receipt raw entry does not match entry hash %s", r.Entry.EntryHash)
+		}
+	}
+
+	if len(r.MerkleBranch) == 0 {
+		return fmt.Errorf("receipt has no merkle branch")
+	}
+
+	ebKeyMR, err := hex.DecodeString(r.EntryBlockKeyMR)
+	if err != nil {
+		return fmt.Errorf("invalid receipt entry block keymr: %v", err)
+	}
+	foundEBlock := false
+
+	for i, node := range r.MerkleBranch {
+		left, err := hex.DecodeString(node.Left)
+		if err != nil || len(left) != 32 {
+			return fmt.Errorf("invalid left hash in merkle branch node %d", i)
+		}
+		right, err := hex.DecodeString(node.Right)
+		if err != nil || len(right) != 32 {
+			return fmt.Errorf("invalid right hash in merkle branch node %d", i)
+		}
+		top, err := hex.DecodeString(node.Top)
+		if err != nil || len(top) != 32 {
+			return fmt.Errorf("invalid top hash in merkle branch node %d", i)
+		}
+
+		if !bytes.Equal(left, current) && !bytes.Equal(right, current) {
+			return fmt.Errorf("merkle branch node %d does not include %x", i, current)
+		}
+		if h := sha256.Sum256(append(left, right...)); !bytes.Equal(h[:], top) {
+			return fmt.Errorf("merkle branch node %d hashes to %x not %s", i, h, node.Top)
+		}
+
+		current = top
+		if bytes.Equal(current, ebKeyMR) {
+			foundEBlock = true
+		}
+	}
+
+	if !foundEBlock {
+		return fmt.Errorf("merkle branch does not include entry block %s", r.EntryBlockKeyMR)
+	}
+	if hex.EncodeToString(current) != r.DirectoryBlockKeyMR {
+		return fmt.Errorf("merkle branch root %x does not match directory block %s", current, r.DirectoryBlockKeyMR)
+	}
+
+	return nil
+}
+
+// VerifyDBlock verifies the Receipt and checks that it proves the Entry is
+// included in a trusted Directory Block.
+func (r *Receipt) VerifyDBlock(db *DBlock) error {
+	if err := r.Verify(); err != nil {
+		return err
+	}
+	if r.DirectoryBlockKeyMR != db.KeyMR {
+		return fmt.Errorf("receipt directory block %s does not match trusted directory block %s", r.DirectoryBlockKeyMR, db.KeyMR)
+	}
+	return nil
+}
+
+// AnchorRecord is the record of a Directory Block KeyMR anchored into another
+// blockchain, as written to the Factom anchor Chain.
+type AnchorRecord struct {
+	AnchorRecordVer int            `json:"AnchorRecordVer"`
+	DBHeight        int64          `json:"DBHeight"`
+	KeyMR           string         `json:"KeyMR"`
+	RecordHeight    int64          `json:"RecordHeight"`
+	Bitcoin         *BitcoinAnchor `json:"Bitcoin,omitempty"`
+}
+
+// BitcoinAnchor is the Bitcoin transaction of an AnchorRecord.
+type BitcoinAnchor struct {
+	Address     string `json:"Address"`
+	TXID        string `json:"TXID"`
+	BlockHeight int64  `json:"BlockHeight"`
+	BlockHash   string `json:"BlockHash"`
+	Offset      int64  `json:"Offset"`
+}
+
+// VerifyAnchor verifies the Receipt and checks that it proves the Entry is
+// included in the Directory Block of a trusted AnchorRecord. If the Receipt
+// names a Bitcoin transaction and block they must match the AnchorRecord.
+func (r *Receipt) VerifyAnchor(a *AnchorRecord) error {
+	if err := r.Verify(); err != nil {
+		return err
+	}
+	if r.DirectoryBlockKeyMR != a.KeyMR {
+		return fmt.Errorf("receipt directory block %s does not match anchored directory block %s", r.DirectoryBlockKeyMR, a.KeyMR)
+	}
+	if a.Bitcoin != nil {
+		if r.BitcoinTransactionHash != "" && r.BitcoinTransactionHash != a.Bitcoin.TXID {
+			return fmt.Errorf("receipt bitcoin transaction %s does not match anchor %s", r.BitcoinTransactionHash, a.Bitcoin.TXID)
+		}
+		if r.BitcoinBlockHash != "" && r.BitcoinBlockHash != a.Bitcoin.BlockHash {
+			return fmt.Errorf("receipt bitcoin block %s does not match anchor %s", r.BitcoinBlockHash, a.Bitcoin.BlockHash)
+		}
+	}
+	return nil
+}
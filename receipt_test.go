@@ -74,4 +74,37 @@ func TestGetReciept(t *testing.T) {
 		t.Error(err)
 	}
 	t.Log(r)
+
+	if err := r.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	db := new(DBlock)
+	db.KeyMR = r.DirectoryBlockKeyMR
+	if err := r.VerifyDBlock(db); err != nil {
+		t.Error(err)
+	}
+	db.KeyMR = ZeroHash
+	if err := r.VerifyDBlock(db); err == nil {
+		t.Error("expected receipt not to match the directory block")
+	}
+
+	anchor := new(AnchorRecord)
+	anchor.KeyMR = r.DirectoryBlockKeyMR
+	anchor.Bitcoin = &BitcoinAnchor{
+		TXID:      r.BitcoinTransactionHash,
+		BlockHash: r.BitcoinBlockHash,
+	}
+	if err := r.VerifyAnchor(anchor); err != nil {
+		t.Error(err)
+	}
+	anchor.Bitcoin.TXID = ZeroHash
+	if err := r.VerifyAnchor(anchor); err == nil {
+		t.Error("expected receipt not to match the anchor transaction")
+	}
+
+	r.MerkleBranch[3].Left = ZeroHash
+	if err := r.Verify(); err == nil {
+		t.Error("expected tampered receipt to fail verification")
+	}
 }
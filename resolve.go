@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"fmt"
 
 	netki "github.com/FactomProject/netki-go-partner-client"
@@ -25,13 +26,19 @@ func ResolveDnsName(addr string) (string, string, error) {
 // GetDnsBalance returns the balances of the Factoid and Entry Credit addresses
 // associated with a netki DNS name.
 func GetDnsBalance(addr string) (int64, int64, error) {
+	return DefaultClient.GetDnsBalance(context.Background(), addr)
+}
+
+// GetDnsBalance returns the balances of the Factoid and Entry Credit addresses
+// associated with a netki DNS name.
+func (c *Client) GetDnsBalance(ctx context.Context, addr string) (int64, int64, error) {
 	fct, ec, err := ResolveDnsName(addr)
 	if err != nil {
 		return -1, -1, err
 	}
 
-	f, err1 := GetFactoidBalance(fct)
-	e, err2 := GetECBalance(ec)
+	f, err1 := c.GetFactoidBalance(ctx, fct)
+	e, err2 := c.GetECBalance(ctx, ec)
 	if err1 != nil || err2 != nil {
 		return f, e, fmt.Errorf("%s\n%s\n", err1, err2)
 	}
@@ -0,0 +1,168 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"time"
+)
+
+// HTTPError is returned when the API server answers with an HTTP error status
+// and no JSON RPC response.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("API server returned HTTP status %s", e.Status)
+}
+
+// RetryPolicy decides if and when a failed factomd API request is retried.
+type RetryPolicy interface {
+	// Retry is called after the given attempt (starting at 1) has failed with
+	// err. It returns the time to wait before the next attempt, or false if
+	// the request should not be retried.
+	Retry(attempt int, err error) (time.Duration, bool)
+}
+
+// Backoff is a RetryPolicy that retries transient errors with exponentially
+// increasing, randomly jittered, wait times.
+type Backoff struct {
+	// MaxAttempts is the maximum number of attempts including the first.
+	MaxAttempts int
+	// InitialInterval is the wait time after the first failed attempt.
+	InitialInterval time.Duration
+	// MaxInterval caps the wait time between attempts.
+	MaxInterval time.Duration
+	// Multiplier is the factor the wait time grows by after every attempt.
+	Multiplier float64
+	// Jitter is the fraction, between 0 and 1, by which each wait time is
+	// randomly increased or decreased.
+	Jitter float64
+	// Retryable classifies errors as retryable. A nil Retryable uses
+	// IsRetryableError.
+	Retryable func(error) bool
+}
+
+// NewBackoff creates a new Backoff RetryPolicy with the default settings.
+func NewBackoff() *Backoff {
+	b := new(Backoff)
+	b.MaxAttempts = 5
+	b.InitialInterval = 100 * time.Millisecond
+	b.MaxInterval = 5 * time.Second
+	b.Multiplier = 2
+	b.Jitter = 0.5
+	return b
+}
+
+// Retry implements RetryPolicy.
+func (b *Backoff) Retry(attempt int, err error) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts {
+		return 0, false
+	}
+	retryable := b.Retryable
+	if retryable == nil {
+		retryable = IsRetryableError
+	}
+	if !retryable(err) {
+		return 0, false
+	}
+	return b.interval(attempt), true
+}
+
+// interval returns the jittered wait time after the given attempt.
+func (b *Backoff) interval(attempt int) time.Duration {
+	wait := float64(b.InitialInterval)
+	for i := 1; i < attempt; i++ {
+		wait *= b.Multiplier
+		if b.MaxInterval > 0 && wait > float64(b.MaxInterval) {
+			wait = float64(b.MaxInterval)
+			break
+		}
+	}
+	if b.Jitter > 0 {
+		wait += wait * b.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(wait)
+}
+
+// IsRetryableError returns true for errors that are likely to be transient:
+// network errors, HTTP 5xx responses, and factomd internal errors such as
+// those returned while the node is not yet synced. Context cancellation is
+// never retryable.
+func IsRetryableError(err error) bool {
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return false
+	}
+
+	switch e := err.(type) {
+	case *HTTPError:
+		return e.StatusCode >= 500
+	case *JSONError:
+		return e.Code == ErrInternal.Code
+	case net.Error:
+		return true
+	}
+	return false
+}
+
+// writeMethods are the factomd API methods that change the state of the
+// network and are not retried unless FactomdRetryWrites is set.
+var writeMethods = map[string]bool{
+	"commit-chain":     true,
+	"reveal-chain":     true,
+	"commit-entry":     true,
+	"reveal-entry":     true,
+	"factoid-submit":   true,
+	"send-raw-message": true,
+}
+
+// hasWriteMethod returns true if any of the requests is a write method.
+func hasWriteMethod(reqs []*JSON2Request) bool {
+	for _, req := range reqs {
+		if writeMethods[req.Method] {
+			return true
+		}
+	}
+	return false
+}
+
+// retry calls f until it succeeds or the retry policy gives up. Requests
+// containing a write method are only retried when FactomdRetryWrites is set.
+func (c *Client) retry(ctx context.Context, reqs []*JSON2Request, f func() error) error {
+	cfg := c.config()
+	if cfg.FactomdRetryPolicy == nil {
+		return f()
+	}
+	if !cfg.FactomdRetryWrites && hasWriteMethod(reqs) {
+		return f()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		wait, ok := cfg.FactomdRetryPolicy.Retry(attempt, err)
+		if !ok {
+			return err
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/FactomProject/factom"
+
+	"testing"
+)
+
+// newFlakyServer returns a server which fails the first n requests with the
+// given failure handler and answers with a successful EC rate afterwards.
+func newFlakyServer(n int32, posts *int32, fail http.HandlerFunc) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(posts, 1) <= n {
+			fail(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"jsonrpc":"2.0","id":0,"result":{"rate":95369,"txid":"abcd"}}`)
+	}))
+}
+
+func testBackoff() *Backoff {
+	b := NewBackoff()
+	b.InitialInterval = time.Millisecond
+	b.MaxInterval = 5 * time.Millisecond
+	return b
+}
+
+func TestRetryHTTPError(t *testing.T) {
+	var posts int32
+	ts := newFlakyServer(2, &posts, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	defer ts.Close()
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:]})
+	if _, err := c.GetECRate(context.Background()); err == nil {
+		t.Error("expected an error without a retry policy")
+	} else if herr, ok := err.(*HTTPError); !ok || herr.StatusCode != 503 {
+		t.Errorf("expected an HTTPError, recieved %v", err)
+	}
+
+	atomic.StoreInt32(&posts, 0)
+	c.Config.FactomdRetryPolicy = testBackoff()
+	rate, err := c.GetECRate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != 95369 {
+		t.Errorf("expected:%d\nrecieved:%d", 95369, rate)
+	}
+	if n := atomic.LoadInt32(&posts); n != 3 {
+		t.Errorf("expected 3 attempts, recieved %d", n)
+	}
+}
+
+func TestRetryJSONError(t *testing.T) {
+	var posts int32
+	ts := newFlakyServer(1, &posts, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"jsonrpc":"2.0","id":0,"error":{"code":-32603,"message":"Internal error"}}`)
+	})
+	defer ts.Close()
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:], FactomdRetryPolicy: testBackoff()})
+	if _, err := c.GetECRate(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if n := atomic.LoadInt32(&posts); n != 2 {
+		t.Errorf("expected 2 attempts, recieved %d", n)
+	}
+
+	// errors that are not transient are returned after the first attempt
+	ts2 := newFlakyServer(1, &posts, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"jsonrpc":"2.0","id":0,"error":{"code":-32602,"message":"Invalid params"}}`)
+	})
+	defer ts2.Close()
+	c.Config.FactomdServer = ts2.URL[7:]
+	atomic.StoreInt32(&posts, 0)
+	if _, err := c.GetECRate(context.Background()); err == nil {
+		t.Error("expected an invalid params error")
+	}
+	if n := atomic.LoadInt32(&posts); n != 1 {
+		t.Errorf("expected 1 attempt, recieved %d", n)
+	}
+}
+
+func TestRetryWrites(t *testing.T) {
+	var posts int32
+	ts := newFlakyServer(1, &posts, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusBadGateway)
+	})
+	defer ts.Close()
+
+	ent := NewEntryFromStrings("954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4", "test!", "test")
+	ec, err := GetECAddress("Es2Rf7iM6PdsqfYCo3D1tnAR65SkLENyWJG1deUzpRMQmbh9F3eG")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(&RPCConfig{FactomdServer: ts.URL[7:], FactomdRetryPolicy: testBackoff()})
+	if _, err := c.CommitEntry(context.Background(), ent, ec); err == nil {
+		t.Error("expected commit to fail without retrying writes")
+	}
+	if n := atomic.LoadInt32(&posts); n != 1 {
+		t.Errorf("expected 1 attempt, recieved %d", n)
+	}
+
+	atomic.StoreInt32(&posts, 0)
+	c.Config.FactomdRetryWrites = true
+	if _, err := c.CommitEntry(context.Background(), ent, ec); err != nil {
+		t.Error(err)
+	}
+	if n := atomic.LoadInt32(&posts); n != 2 {
+		t.Errorf("expected 2 attempts, recieved %d", n)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := NewBackoff()
+	b.Jitter = 0
+	transient := &HTTPError{StatusCode: 500}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+	}
+	for i, e := range expected {
+		wait, ok := b.Retry(i+1, transient)
+		if !ok || wait != e {
+			t.Errorf("attempt %d: expected %v, recieved %v %v", i+1, e, wait, ok)
+		}
+	}
+	if _, ok := b.Retry(b.MaxAttempts, transient); ok {
+		t.Error("expected no retry after MaxAttempts")
+	}
+	if _, ok := b.Retry(1, errors.New("permanent")); ok {
+		t.Error("expected no retry of a permanent error")
+	}
+	if _, ok := b.Retry(1, context.Canceled); ok {
+		t.Error("expected no retry of a canceled context")
+	}
+
+	b.MaxAttempts = 100
+	if wait, _ := b.Retry(50, transient); wait != b.MaxInterval {
+		t.Errorf("expected wait capped at %v, recieved %v", b.MaxInterval, wait)
+	}
+
+	b.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		wait, _ := b.Retry(1, transient)
+		if wait < 50*time.Millisecond || wait > 150*time.Millisecond {
+			t.Errorf("jittered wait %v out of range", wait)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 )
 
@@ -13,6 +14,13 @@ type Signature struct {
 // The signer can be either an FA address, EC address, or Identity.
 // Be aware that the data is transmitted to the wallet.
 func SignData(signer string, data []byte) (*Signature, error) {
+	return DefaultClient.SignData(context.Background(), signer, data)
+}
+
+// SignData lets you sign arbitrary data by the specified signer.
+// The signer can be either an FA address, EC address, or Identity.
+// Be aware that the data is transmitted to the wallet.
+func (c *Client) SignData(ctx context.Context, signer string, data []byte) (*Signature, error) {
 	params := &struct {
 		Signer string `json:"signer"`
 		Data   []byte `json:"data"`
@@ -22,7 +30,7 @@ func SignData(signer string, data []byte) (*Signature, error) {
 	}
 
 	req := NewJSON2Request("sign-data", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 )
 
@@ -12,8 +13,15 @@ import (
 // (over the lifetime of the node) of Transactions Per Second rate know to
 // factomd.
 func GetTPS() (instant, total float64, err error) {
+	return DefaultClient.GetTPS(context.Background())
+}
+
+// GetTPS returns the instant rate (over the previous 3 seconds) and total rate
+// (over the lifetime of the node) of Transactions Per Second rate know to
+// factomd.
+func (c *Client) GetTPS(ctx context.Context) (instant, total float64, err error) {
 	req := NewJSON2Request("tps-rate", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
@@ -157,10 +158,15 @@ func (tx *Transaction) UnmarshalJSON(data []byte) error {
 
 // NewTransaction creates a new temporary Transaction in the wallet.
 func NewTransaction(name string) (*Transaction, error) {
+	return DefaultClient.NewTransaction(context.Background(), name)
+}
+
+// NewTransaction creates a new temporary Transaction in the wallet.
+func (c *Client) NewTransaction(ctx context.Context, name string) (*Transaction, error) {
 	params := transactionRequest{Name: name}
 	req := NewJSON2Request("new-transaction", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -177,10 +183,15 @@ func NewTransaction(name string) (*Transaction, error) {
 
 // DeleteTransaction remove a temporary transacton from the wallet.
 func DeleteTransaction(name string) error {
+	return DefaultClient.DeleteTransaction(context.Background(), name)
+}
+
+// DeleteTransaction remove a temporary transacton from the wallet.
+func (c *Client) DeleteTransaction(ctx context.Context, name string) error {
 	params := transactionRequest{Name: name}
 	req := NewJSON2Request("delete-transaction", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -193,8 +204,13 @@ func DeleteTransaction(name string) error {
 
 // ListTransactionsAll lists all the transactions from the wallet database.
 func ListTransactionsAll() ([]*Transaction, error) {
+	return DefaultClient.ListTransactionsAll(context.Background())
+}
+
+// ListTransactionsAll lists all the transactions from the wallet database.
+func (c *Client) ListTransactionsAll(ctx context.Context) ([]*Transaction, error) {
 	req := NewJSON2Request("transactions", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -214,6 +230,11 @@ func ListTransactionsAll() ([]*Transaction, error) {
 
 // ListTransactionsAddress lists all transaction to and from a given address.
 func ListTransactionsAddress(addr string) ([]*Transaction, error) {
+	return DefaultClient.ListTransactionsAddress(context.Background(), addr)
+}
+
+// ListTransactionsAddress lists all transaction to and from a given address.
+func (c *Client) ListTransactionsAddress(ctx context.Context, addr string) ([]*Transaction, error) {
 	params := &struct {
 		Address string `json:"address"`
 	}{
@@ -221,7 +242,7 @@ func ListTransactionsAddress(addr string) ([]*Transaction, error) {
 	}
 
 	req := NewJSON2Request("transactions", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -242,6 +263,12 @@ func ListTransactionsAddress(addr string) ([]*Transaction, error) {
 // ListTransactionsID lists a transaction from the wallet database with a given
 // Transaction ID.
 func ListTransactionsID(id string) ([]*Transaction, error) {
+	return DefaultClient.ListTransactionsID(context.Background(), id)
+}
+
+// ListTransactionsID lists a transaction from the wallet database with a given
+// Transaction ID.
+func (c *Client) ListTransactionsID(ctx context.Context, id string) ([]*Transaction, error) {
 	params := &struct {
 		TxID string `json:"txid"`
 	}{
@@ -249,7 +276,7 @@ func ListTransactionsID(id string) ([]*Transaction, error) {
 	}
 
 	req := NewJSON2Request("transactions", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -270,6 +297,12 @@ func ListTransactionsID(id string) ([]*Transaction, error) {
 // ListTransactionsRange lists all transacions from the wallet database made
 // within a given range of Directory Block heights.
 func ListTransactionsRange(start, end int) ([]*Transaction, error) {
+	return DefaultClient.ListTransactionsRange(context.Background(), start, end)
+}
+
+// ListTransactionsRange lists all transacions from the wallet database made
+// within a given range of Directory Block heights.
+func (c *Client) ListTransactionsRange(ctx context.Context, start, end int) ([]*Transaction, error) {
 	params := new(struct {
 		Range struct {
 			Start int `json:"start"`
@@ -280,7 +313,7 @@ func ListTransactionsRange(start, end int) ([]*Transaction, error) {
 	params.Range.End = end
 
 	req := NewJSON2Request("transactions", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -302,8 +335,15 @@ func ListTransactionsRange(start, end int) ([]*Transaction, error) {
 // wallet. Temporary transaction are held by the wallet while they are being
 // constructed and prepaired to be submitted to the network.
 func ListTransactionsTmp() ([]*Transaction, error) {
+	return DefaultClient.ListTransactionsTmp(context.Background())
+}
+
+// ListTransactionsTmp lists all of the temporary transaction held in the
+// wallet. Temporary transaction are held by the wallet while they are being
+// constructed and prepaired to be submitted to the network.
+func (c *Client) ListTransactionsTmp(ctx context.Context) ([]*Transaction, error) {
 	req := NewJSON2Request("tmp-transactions", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -327,6 +367,18 @@ func AddTransactionInput(
 	name,
 	address string,
 	amount uint64,
+) (*Transaction, error) {
+	return DefaultClient.AddTransactionInput(context.Background(), name, address, amount)
+}
+
+// AddTransactionInput adds a factoid input to a temporary transaction in the
+// wallet. The imput should come from a Factoid address heald in the wallet
+// database.
+func (c *Client) AddTransactionInput(
+	ctx context.Context,
+	name,
+	address string,
+	amount uint64,
 ) (*Transaction, error) {
 	if AddressStringType(address) != FactoidPub {
 		return nil, fmt.Errorf("%s is not a Factoid address", address)
@@ -340,7 +392,7 @@ func AddTransactionInput(
 
 	req := NewJSON2Request("add-input", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -361,6 +413,17 @@ func AddTransactionOutput(
 	name,
 	address string,
 	amount uint64,
+) (*Transaction, error) {
+	return DefaultClient.AddTransactionOutput(context.Background(), name, address, amount)
+}
+
+// AddTransactionOutput adds a factoid output to a temporary transaction in
+// the wallet.
+func (c *Client) AddTransactionOutput(
+	ctx context.Context,
+	name,
+	address string,
+	amount uint64,
 ) (*Transaction, error) {
 	if AddressStringType(address) != FactoidPub {
 		return nil, fmt.Errorf("%s is not a Factoid address", address)
@@ -374,7 +437,7 @@ func AddTransactionOutput(
 
 	req := NewJSON2Request("add-output", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -392,6 +455,12 @@ func AddTransactionOutput(
 // AddTransactionECOutput adds an Entry Credit output to a temporary transaction
 // in the wallet.
 func AddTransactionECOutput(name, address string, amount uint64) (*Transaction, error) {
+	return DefaultClient.AddTransactionECOutput(context.Background(), name, address, amount)
+}
+
+// AddTransactionECOutput adds an Entry Credit output to a temporary transaction
+// in the wallet.
+func (c *Client) AddTransactionECOutput(ctx context.Context, name, address string, amount uint64) (*Transaction, error) {
 	if AddressStringType(address) != ECPub {
 		return nil, fmt.Errorf("%s is not an Entry Credit address", address)
 	}
@@ -404,7 +473,7 @@ func AddTransactionECOutput(name, address string, amount uint64) (*Transaction,
 
 	req := NewJSON2Request("add-ec-output", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -422,6 +491,12 @@ func AddTransactionECOutput(name, address string, amount uint64) (*Transaction,
 // AddTransactionFee adds the appropriate factoid fee payment to a transaction
 // input of a temporary transaction in the wallet.
 func AddTransactionFee(name, address string) (*Transaction, error) {
+	return DefaultClient.AddTransactionFee(context.Background(), name, address)
+}
+
+// AddTransactionFee adds the appropriate factoid fee payment to a transaction
+// input of a temporary transaction in the wallet.
+func (c *Client) AddTransactionFee(ctx context.Context, name, address string) (*Transaction, error) {
 	if AddressStringType(address) != FactoidPub {
 		return nil, fmt.Errorf("%s is not a Factoid address", address)
 	}
@@ -433,7 +508,7 @@ func AddTransactionFee(name, address string) (*Transaction, error) {
 
 	req := NewJSON2Request("add-fee", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -451,6 +526,12 @@ func AddTransactionFee(name, address string) (*Transaction, error) {
 // SubTransactionFee subtracts the appropriate factoid fee payment from a
 // transaction output of a temporary transaction in the wallet.
 func SubTransactionFee(name, address string) (*Transaction, error) {
+	return DefaultClient.SubTransactionFee(context.Background(), name, address)
+}
+
+// SubTransactionFee subtracts the appropriate factoid fee payment from a
+// transaction output of a temporary transaction in the wallet.
+func (c *Client) SubTransactionFee(ctx context.Context, name, address string) (*Transaction, error) {
 	params := transactionValueRequest{
 		Name:    name,
 		Address: address,
@@ -458,7 +539,7 @@ func SubTransactionFee(name, address string) (*Transaction, error) {
 
 	req := NewJSON2Request("sub-fee", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -476,6 +557,12 @@ func SubTransactionFee(name, address string) (*Transaction, error) {
 // SignTransaction adds the reqired signatures from the appropriate factoid
 // addresses to a temporary transaction in the wallet.
 func SignTransaction(name string, force bool) (*Transaction, error) {
+	return DefaultClient.SignTransaction(context.Background(), name, force)
+}
+
+// SignTransaction adds the reqired signatures from the appropriate factoid
+// addresses to a temporary transaction in the wallet.
+func (c *Client) SignTransaction(ctx context.Context, name string, force bool) (*Transaction, error) {
 	params := transactionRequest{
 		Name:  name,
 		Force: force,
@@ -483,7 +570,7 @@ func SignTransaction(name string, force bool) (*Transaction, error) {
 
 	req := NewJSON2Request("sign-transaction", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -506,10 +593,21 @@ func SignTransaction(name string, force bool) (*Transaction, error) {
 // that can be securely transfered to an online node to enable transactions from
 // compleatly offline addresses.
 func ComposeTransaction(name string) ([]byte, error) {
+	return DefaultClient.ComposeTransaction(context.Background(), name)
+}
+
+// ComposeTransaction creates a json object from a temporary transaction in the
+// wallet that may be sent to the factomd API to submit the transaction to the
+// network.
+//
+// ComposeTransaction may be used by an offline wallet to create an API call
+// that can be securely transfered to an online node to enable transactions from
+// compleatly offline addresses.
+func (c *Client) ComposeTransaction(ctx context.Context, name string) ([]byte, error) {
 	params := transactionRequest{Name: name}
 	req := NewJSON2Request("compose-transaction", APICounter(), params)
 
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -523,9 +621,15 @@ func ComposeTransaction(name string) ([]byte, error) {
 // SendTransaction composes a prepaired temoprary transaction from the wallet
 // and sends it to the factomd API to be included on the factom network.
 func SendTransaction(name string) (*Transaction, error) {
+	return DefaultClient.SendTransaction(context.Background(), name)
+}
+
+// SendTransaction composes a prepaired temoprary transaction from the wallet
+// and sends it to the factomd API to be included on the factom network.
+func (c *Client) SendTransaction(ctx context.Context, name string) (*Transaction, error) {
 	params := transactionRequest{Name: name}
 
-	tx, err := GetTmpTransaction(name)
+	tx, err := c.GetTmpTransaction(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -534,7 +638,7 @@ func SendTransaction(name string) (*Transaction, error) {
 	}
 
 	wreq := NewJSON2Request("compose-transaction", APICounter(), params)
-	wresp, err := walletRequest(wreq)
+	wresp, err := c.walletRequest(ctx, wreq)
 	if err != nil {
 		return nil, err
 	}
@@ -544,14 +648,14 @@ func SendTransaction(name string) (*Transaction, error) {
 
 	freq := new(JSON2Request)
 	json.Unmarshal(wresp.JSONResult(), freq)
-	fresp, err := factomdRequest(freq)
+	fresp, err := c.factomdRequest(ctx, freq)
 	if err != nil {
 		return nil, err
 	}
 	if fresp.Error != nil {
 		return nil, fresp.Error
 	}
-	if err := DeleteTransaction(name); err != nil {
+	if err := c.DeleteTransaction(ctx, name); err != nil {
 		return nil, err
 	}
 
@@ -560,37 +664,42 @@ func SendTransaction(name string) (*Transaction, error) {
 
 // SendFactoid creates and sends a transaction to the Factom Network.
 func SendFactoid(from, to string, amount uint64, force bool) (*Transaction, error) {
+	return DefaultClient.SendFactoid(context.Background(), from, to, amount, force)
+}
+
+// SendFactoid creates and sends a transaction to the Factom Network.
+func (c *Client) SendFactoid(ctx context.Context, from, to string, amount uint64, force bool) (*Transaction, error) {
 	n := make([]byte, 16)
 	if _, err := rand.Read(n); err != nil {
 		return nil, err
 	}
 	name := hex.EncodeToString(n)
-	if _, err := NewTransaction(name); err != nil {
+	if _, err := c.NewTransaction(ctx, name); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionInput(name, from, amount); err != nil {
+	if _, err := c.AddTransactionInput(ctx, name, from, amount); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionOutput(name, to, amount); err != nil {
+	if _, err := c.AddTransactionOutput(ctx, name, to, amount); err != nil {
 		return nil, err
 	}
-	balance, err := GetFactoidBalance(from)
+	balance, err := c.GetFactoidBalance(ctx, from)
 	if err != nil {
 		return nil, err
 	}
 	if balance > int64(amount) {
-		if _, err := AddTransactionFee(name, from); err != nil {
+		if _, err := c.AddTransactionFee(ctx, name, from); err != nil {
 			return nil, err
 		}
 	} else {
-		if _, err := SubTransactionFee(name, to); err != nil {
+		if _, err := c.SubTransactionFee(ctx, name, to); err != nil {
 			return nil, err
 		}
 	}
-	if _, err := SignTransaction(name, force); err != nil {
+	if _, err := c.SignTransaction(ctx, name, force); err != nil {
 		return nil, err
 	}
-	r, err := SendTransaction(name)
+	r, err := c.SendTransaction(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -601,27 +710,33 @@ func SendFactoid(from, to string, amount uint64, force bool) (*Transaction, erro
 // BuyEC creates and sends a transaction to the Factom Network that purchases
 // Entry Credits.
 func BuyEC(from, to string, amount uint64, force bool) (*Transaction, error) {
+	return DefaultClient.BuyEC(context.Background(), from, to, amount, force)
+}
+
+// BuyEC creates and sends a transaction to the Factom Network that purchases
+// Entry Credits.
+func (c *Client) BuyEC(ctx context.Context, from, to string, amount uint64, force bool) (*Transaction, error) {
 	n := make([]byte, 16)
 	if _, err := rand.Read(n); err != nil {
 		return nil, err
 	}
 	name := hex.EncodeToString(n)
-	if _, err := NewTransaction(name); err != nil {
+	if _, err := c.NewTransaction(ctx, name); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionInput(name, from, amount); err != nil {
+	if _, err := c.AddTransactionInput(ctx, name, from, amount); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionECOutput(name, to, amount); err != nil {
+	if _, err := c.AddTransactionECOutput(ctx, name, to, amount); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionFee(name, from); err != nil {
+	if _, err := c.AddTransactionFee(ctx, name, from); err != nil {
 		return nil, err
 	}
-	if _, err := SignTransaction(name, force); err != nil {
+	if _, err := c.SignTransaction(ctx, name, force); err != nil {
 		return nil, err
 	}
-	r, err := SendTransaction(name)
+	r, err := c.SendTransaction(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -636,7 +751,17 @@ func BuyEC(from, to string, amount uint64, force bool) (*Transaction, error) {
 // so that the exact requested number of Entry Credits are created by the output
 // of the transacton.
 func BuyExactEC(from, to string, amount uint64, force bool) (*Transaction, error) {
-	rate, err := GetECRate()
+	return DefaultClient.BuyExactEC(context.Background(), from, to, amount, force)
+}
+
+// BuyExactEC creates and sends a transaction to the Factom Network that
+// purchases an exact number of Entry Credits.
+//
+// BuyExactEC calculates the and adds the transaction fees and Entry Credit rate
+// so that the exact requested number of Entry Credits are created by the output
+// of the transacton.
+func (c *Client) BuyExactEC(ctx context.Context, from, to string, amount uint64, force bool) (*Transaction, error) {
+	rate, err := c.GetECRate(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -647,22 +772,22 @@ func BuyExactEC(from, to string, amount uint64, force bool) (*Transaction, error
 	}
 	name := hex.EncodeToString(n)
 
-	if _, err := NewTransaction(name); err != nil {
+	if _, err := c.NewTransaction(ctx, name); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionInput(name, from, amount*rate); err != nil {
+	if _, err := c.AddTransactionInput(ctx, name, from, amount*rate); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionECOutput(name, to, amount*rate); err != nil {
+	if _, err := c.AddTransactionECOutput(ctx, name, to, amount*rate); err != nil {
 		return nil, err
 	}
-	if _, err := AddTransactionFee(name, from); err != nil {
+	if _, err := c.AddTransactionFee(ctx, name, from); err != nil {
 		return nil, err
 	}
-	if _, err := SignTransaction(name, force); err != nil {
+	if _, err := c.SignTransaction(ctx, name, force); err != nil {
 		return nil, err
 	}
-	r, err := SendTransaction(name)
+	r, err := c.SendTransaction(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -671,9 +796,16 @@ func BuyExactEC(from, to string, amount uint64, force bool) (*Transaction, error
 }
 
 // FactoidSubmit sends a raw transaction to factomd to be included in the
-// network. (See ComposeTransaction for more details on how to build the binary
-// transaction for the network).
+// network. (See ComposeTransaction, or FactoidTransaction.Compose to build the
+// binary transaction without walletd).
 func FactoidSubmit(tx string) (message, txid string, err error) {
+	return DefaultClient.FactoidSubmit(context.Background(), tx)
+}
+
+// FactoidSubmit sends a raw transaction to factomd to be included in the
+// network. (See ComposeTransaction, or FactoidTransaction.Compose to build the
+// binary transaction without walletd).
+func (c *Client) FactoidSubmit(ctx context.Context, tx string) (message, txid string, err error) {
 	params := &struct {
 		Transaction string
 	}{
@@ -681,12 +813,12 @@ func FactoidSubmit(tx string) (message, txid string, err error) {
 	}
 
 	req := NewJSON2Request("factoid-submit", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return
 	}
 	if resp.Error != nil {
-		return
+		return "", "", resp.Error
 	}
 
 	fsr := new(struct {
@@ -717,9 +849,14 @@ type TransactionResponse struct {
 
 // GetTransaction requests a transaction from the factomd API.
 func GetTransaction(txID string) (*TransactionResponse, error) {
+	return DefaultClient.GetTransaction(context.Background(), txID)
+}
+
+// GetTransaction requests a transaction from the factomd API.
+func (c *Client) GetTransaction(ctx context.Context, txID string) (*TransactionResponse, error) {
 	params := hashRequest{Hash: txID}
 	req := NewJSON2Request("transaction", APICounter(), params)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -735,30 +872,144 @@ func GetTransaction(txID string) (*TransactionResponse, error) {
 	return txResp, nil
 }
 
-// TODO: GetPendingTransactions() should return something more useful than a
-// json string.
-
 // GetPendingTransactions requests a list of transactions that have been
 // submitted to the Factom Network, but have not yet been included in a Factoid
 // Block.
 func GetPendingTransactions() (string, error) {
+	return DefaultClient.GetPendingTransactions(context.Background())
+}
+
+// GetPendingTransactions requests a list of transactions that have been
+// submitted to the Factom Network, but have not yet been included in a Factoid
+// Block.
+func (c *Client) GetPendingTransactions(ctx context.Context) (string, error) {
 	req := NewJSON2Request("pending-transactions", APICounter(), nil)
-	resp, err := factomdRequest(req)
+	resp, err := c.factomdRequest(ctx, req)
 
 	if err != nil {
 		return "", err
 	}
 	if resp.Error != nil {
-		return "", err
+		return "", resp.Error
 	}
 
 	transList := resp.JSONResult()
 	return string(transList), nil
 }
 
+// PendingTransaction is a Factoid Transaction that has been acknowledged by
+// factomd but is not yet in a Factoid Block. The Addresses of the Inputs and
+// Outputs are public Factoid Addresses, and of the ECOutputs public Entry
+// Credit Addresses.
+type PendingTransaction struct {
+	TxID      string
+	DBHeight  int64
+	Status    string
+	Fees      uint64
+	Inputs    []*TransAddress
+	Outputs   []*TransAddress
+	ECOutputs []*TransAddress
+}
+
+// UnmarshalJSON decodes a PendingTransaction in the form returned by factomd.
+func (tx *PendingTransaction) UnmarshalJSON(data []byte) error {
+	j := new(struct {
+		TransactionID string                `json:"transactionid"`
+		DBHeight      int64                 `json:"dbheight"`
+		Status        string                `json:"status"`
+		Fees          uint64                `json:"fees"`
+		Inputs        []factoidTransAddress `json:"inputs"`
+		Outputs       []factoidTransAddress `json:"outputs"`
+		ECOutputs     []factoidTransAddress `json:"ecoutputs"`
+	})
+	if err := json.Unmarshal(data, j); err != nil {
+		return err
+	}
+
+	tx.TxID = j.TransactionID
+	tx.DBHeight = j.DBHeight
+	tx.Status = j.Status
+	tx.Fees = j.Fees
+
+	var err error
+	if tx.Inputs, err = unmarshalTransAddresses(j.Inputs, fcPubPrefix); err != nil {
+		return err
+	}
+	if tx.Outputs, err = unmarshalTransAddresses(j.Outputs, fcPubPrefix); err != nil {
+		return err
+	}
+	if tx.ECOutputs, err = unmarshalTransAddresses(j.ECOutputs, ecPubPrefix); err != nil {
+		return err
+	}
+	return nil
+}
+
+// HasAddress returns true if a public Factoid or Entry Credit Address is one
+// of the Inputs or Outputs of the Transaction.
+func (tx *PendingTransaction) HasAddress(addr string) bool {
+	for _, as := range [][]*TransAddress{tx.Inputs, tx.Outputs, tx.ECOutputs} {
+		for _, a := range as {
+			if a.Address == addr {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ListPendingTransactions returns the Factoid Transactions that are waiting to
+// be written into the next Factoid Block. If addr is not empty only the
+// Transactions to or from that public Factoid or Entry Credit Address are
+// returned.
+func ListPendingTransactions(addr string) ([]*PendingTransaction, error) {
+	return DefaultClient.ListPendingTransactions(context.Background(), addr)
+}
+
+// ListPendingTransactions returns the Factoid Transactions that are waiting to
+// be written into the next Factoid Block. If addr is not empty only the
+// Transactions to or from that public Factoid or Entry Credit Address are
+// returned.
+func (c *Client) ListPendingTransactions(ctx context.Context, addr string) ([]*PendingTransaction, error) {
+	if addr != "" {
+		if t := AddressStringType(addr); t != FactoidPub && t != ECPub {
+			return nil, ErrInvalidAddress
+		}
+	}
+
+	req := NewJSON2Request("pending-transactions", APICounter(), nil)
+	resp, err := c.factomdRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	pending := make([]*PendingTransaction, 0)
+	if err := json.Unmarshal(resp.JSONResult(), &pending); err != nil {
+		return nil, err
+	}
+	if addr == "" {
+		return pending, nil
+	}
+
+	txs := make([]*PendingTransaction, 0)
+	for _, tx := range pending {
+		if tx.HasAddress(addr) {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
 // GetTmpTransaction requests a temporary transaction from the wallet.
 func GetTmpTransaction(name string) (*Transaction, error) {
-	txs, err := ListTransactionsTmp()
+	return DefaultClient.GetTmpTransaction(context.Background(), name)
+}
+
+// GetTmpTransaction requests a temporary transaction from the wallet.
+func (c *Client) GetTmpTransaction(ctx context.Context, name string) (*Transaction, error) {
+	txs, err := c.ListTransactionsTmp(ctx)
 	if err != nil {
 		return nil, err
 	}
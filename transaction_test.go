@@ -6,6 +6,9 @@ package factom_test
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"time"
 
 	. "github.com/FactomProject/factom"
@@ -71,6 +74,69 @@ func TestTransactions(t *testing.T) {
 	}
 }
 
+func TestListPendingTransactions(t *testing.T) {
+	factomdResponse := `{
+		"jsonrpc":"2.0",
+		"id":0,
+		"result":[
+			{
+				"TransactionID":"d998c577a9da5dab3d5634753db3e377e392d72d0204d31bd922df483546da4d",
+				"DBHeight":1000,
+				"Status":"TransactionACK",
+				"Fees":12000,
+				"Inputs":[{"amount":100012000,"address":"031cce24bcc43b596af105167de2c03603c20ada3314a7cfb47befcad4883e6f","useraddress":"FA1zT4aFpEvcnPqPCigB3fvGu4Q4mTXY22iiuV69DqE1pNhdF2MC"}],
+				"Outputs":[{"amount":100000000,"address":"d6ef0d0edbfcba01000afa5de08c92dc809b488499abbf1700a865362003ddb6","useraddress":"FA3bjkbnuXwGbpAQpDa9f3SNwASURwVjA5ZzqzBNeDJKUy3dW4Ay"}],
+				"ECOutputs":[]
+			},
+			{
+				"TransactionID":"b8b12fba54bd1857b0262bba1b71dbeb4e17404570c2ebe50de0dabf061d575c",
+				"DBHeight":1000,
+				"Status":"TransactionACK",
+				"Fees":12000,
+				"Inputs":[{"amount":1012000,"address":"031cce24bcc43b596af105167de2c03603c20ada3314a7cfb47befcad4883e6f","useraddress":"FA1zT4aFpEvcnPqPCigB3fvGu4Q4mTXY22iiuV69DqE1pNhdF2MC"}],
+				"Outputs":[],
+				"ECOutputs":[{"amount":1000000,"address":"3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29","useraddress":"EC2DKSYyRcNWf7RS963VFYgMExoHRYLHVeCfQ9PGPmNzwrcmgm2r"}]
+			}
+		]
+	}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, factomdResponse)
+	}))
+	defer ts.Close()
+
+	SetFactomdServer(ts.URL[7:])
+
+	txs, err := ListPendingTransactions("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 || txs[0].Fees != 12000 || txs[0].DBHeight != 1000 ||
+		txs[0].Inputs[0].Address != "FA1zT4aFpEvcnPqPCigB3fvGu4Q4mTXY22iiuV69DqE1pNhdF2MC" ||
+		txs[1].ECOutputs[0].Address != "EC2DKSYyRcNWf7RS963VFYgMExoHRYLHVeCfQ9PGPmNzwrcmgm2r" {
+		t.Errorf("unexpected pending transactions %v", txs)
+	}
+
+	txs, err = ListPendingTransactions("EC2DKSYyRcNWf7RS963VFYgMExoHRYLHVeCfQ9PGPmNzwrcmgm2r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 || txs[0].TxID != "b8b12fba54bd1857b0262bba1b71dbeb4e17404570c2ebe50de0dabf061d575c" {
+		t.Errorf("unexpected pending transactions %v", txs)
+	}
+	txs, err = ListPendingTransactions("FA3bjkbnuXwGbpAQpDa9f3SNwASURwVjA5ZzqzBNeDJKUy3dW4Ay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 || txs[0].TxID != "d998c577a9da5dab3d5634753db3e377e392d72d0204d31bd922df483546da4d" {
+		t.Errorf("unexpected pending transactions %v", txs)
+	}
+
+	if _, err := ListPendingTransactions("Fs1KWJrpLdfucvmYwN2nWrwepLn8ercpMbzXshd1g8zyhKXLVLWj"); err != ErrInvalidAddress {
+		t.Errorf("expected %v, recieved %v", ErrInvalidAddress, err)
+	}
+}
+
 // helper functions for testing
 
 func mkdummytx() *Transaction {
@@ -0,0 +1,176 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxIdleConns is the default limit of idle keep-alive connections
+	// kept open across all API servers.
+	DefaultMaxIdleConns = 100
+	// DefaultMaxIdleConnsPerHost is the default limit of idle keep-alive
+	// connections kept open to a single API server.
+	DefaultMaxIdleConnsPerHost = 16
+	// DefaultIdleConnTimeout is the default time an idle keep-alive connection
+	// is kept open before it is closed.
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
+// maxTransports is the number of http.Transports kept in the cache. The least
+// recently used transport is dropped, and its idle connections closed, when a
+// new one is needed.
+const maxTransports = 16
+
+// transportKey identifies the settings an http.Transport is built from. API
+// calls made with the same settings share a single transport and its
+// connection pool.
+type transportKey struct {
+	tlsEnable           bool
+	tlsCertFile         string // only set with tlsEnable
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+}
+
+// httpClientCache holds the http.Transports used for the factomd and wallet
+// API calls so that the transport, the TLS configuration, and the keep-alive
+// connections are built once and reused for every request.
+type httpClientCache struct {
+	sync.Mutex
+	transports map[transportKey]*http.Transport
+	used       []transportKey // least recently used first
+}
+
+var httpClients = &httpClientCache{
+	transports: make(map[transportKey]*http.Transport),
+}
+
+// get returns an http.Client with the given timeout using the shared
+// transport for the given settings, building it on first use.
+func (h *httpClientCache) get(key transportKey, timeout time.Duration) (*http.Client, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	tr, ok := h.transports[key]
+	if !ok {
+		var err error
+		if tr, err = newTransport(key); err != nil {
+			return nil, err
+		}
+		h.transports[key] = tr
+	}
+	h.use(key)
+
+	return &http.Client{Transport: tr, Timeout: timeout}, nil
+}
+
+// use marks a transport as the most recently used and drops the least
+// recently used transports beyond maxTransports.
+func (h *httpClientCache) use(key transportKey) {
+	for i, k := range h.used {
+		if k == key {
+			h.used = append(h.used[:i], h.used[i+1:]...)
+			break
+		}
+	}
+	h.used = append(h.used, key)
+
+	for len(h.used) > maxTransports {
+		old := h.used[0]
+		h.used = h.used[1:]
+		h.transports[old].CloseIdleConnections()
+		delete(h.transports, old)
+	}
+}
+
+// newTransport builds an http.Transport for the given settings.
+func newTransport(key transportKey) (*http.Transport, error) {
+	tr := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          key.maxIdleConns,
+		MaxIdleConnsPerHost:   key.maxIdleConnsPerHost,
+		IdleConnTimeout:       key.idleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if key.tlsEnable {
+		caCert, err := ioutil.ReadFile(key.tlsCertFile)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		tr.TLSClientConfig = &tls.Config{RootCAs: caCertPool}
+	}
+	return tr, nil
+}
+
+// closeIdle closes the idle connections of every cached http.Client.
+func (h *httpClientCache) closeIdle() {
+	h.Lock()
+	defer h.Unlock()
+
+	for _, tr := range h.transports {
+		tr.CloseIdleConnections()
+	}
+}
+
+// CloseIdleConnections closes any keep-alive connections to the factomd and
+// wallet API servers which are not currently in use.
+func CloseIdleConnections() {
+	httpClients.closeIdle()
+}
+
+// poolKey fills in the connection pool settings of a transportKey from the
+// RPCConfig, using the package defaults for any unset values. The certificate
+// file is left out when TLS is disabled.
+func (c *RPCConfig) poolKey(key transportKey) transportKey {
+	if !key.tlsEnable {
+		key.tlsCertFile = ""
+	}
+	key.maxIdleConns = c.MaxIdleConns
+	if key.maxIdleConns == 0 {
+		key.maxIdleConns = DefaultMaxIdleConns
+	}
+	key.maxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	if key.maxIdleConnsPerHost == 0 {
+		key.maxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	key.idleConnTimeout = c.IdleConnTimeout
+	if key.idleConnTimeout == 0 {
+		key.idleConnTimeout = DefaultIdleConnTimeout
+	}
+	return key
+}
+
+// factomdHTTPClient returns an http.Client for the factomd API using the
+// shared transport.
+func (c *RPCConfig) factomdHTTPClient() (*http.Client, error) {
+	return httpClients.get(c.poolKey(transportKey{
+		tlsEnable:   c.FactomdTLSEnable,
+		tlsCertFile: c.FactomdTLSCertFile,
+	}), c.FactomdTimeout)
+}
+
+// walletHTTPClient returns an http.Client for the wallet API using the shared
+// transport.
+func (c *RPCConfig) walletHTTPClient() (*http.Client, error) {
+	return httpClients.get(c.poolKey(transportKey{
+		tlsEnable:   c.WalletTLSEnable,
+		tlsCertFile: c.WalletTLSCertFile,
+	}), c.WalletTimeout)
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package factom_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/FactomProject/factom"
+
+	"testing"
+)
+
+func TestConnectionReuse(t *testing.T) {
+	factomdResponse := `{"jsonrpc":"2.0","id":0,"result":{"rate":95369}}`
+
+	var conns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, factomdResponse)
+	}))
+	ts.Config.ConnState = func(c net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	SetFactomdServer(ts.URL[7:])
+	for i := 0; i < 20; i++ {
+		if _, err := GetECRate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("expected 1 connection for sequential requests, got %d", n)
+	}
+
+	CloseIdleConnections()
+	if _, err := GetECRate(); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&conns); n != 2 {
+		t.Errorf("expected a new connection after closing idle connections, got %d", n)
+	}
+}
+
+// the certificate file and timeout do not split the connection pool when TLS
+// is disabled
+func TestConnectionSharing(t *testing.T) {
+	var conns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"jsonrpc":"2.0","id":0,"result":{"rate":95369}}`)
+	}))
+	ts.Config.ConnState = func(c net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	for i := 0; i < 5; i++ {
+		c := NewClient(&RPCConfig{
+			FactomdServer:      ts.URL[7:],
+			FactomdTLSCertFile: fmt.Sprintf("/unused/%d.cert", i),
+			FactomdTimeout:     time.Duration(i+1) * time.Minute,
+		})
+		if _, err := c.GetECRate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("expected 1 connection, got %d", n)
+	}
+}
+
+func TestFactomdTLSMissingCert(t *testing.T) {
+	c := NewClient(&RPCConfig{
+		FactomdServer:      "localhost:8088",
+		FactomdTLSEnable:   true,
+		FactomdTLSCertFile: "/does/not/exist.cert",
+	})
+	if _, err := c.SendFactomdRequest(context.Background(), NewJSON2Request("heights", 0, nil)); err == nil {
+		t.Error("expected an error reading the missing certificate file")
+	}
+}
@@ -41,9 +41,9 @@ func EntryCost(e *Entry) (int8, error) {
 	}
 
 	// caulculate the length exluding the header size 35 for Milestone 1
-	l := len(p) - 35
+	l := len(p) - EntryHeaderSize
 
-	if l > 10240 {
+	if l > EntryMaxDataSize {
 		return 10, fmt.Errorf("Entry cannot be larger than 10KB")
 	}
 
@@ -105,9 +105,13 @@ func FactoidToFactoshi(amt string) uint64 {
 
 // milliTime returns a 6 byte slice representing the unix time in milliseconds
 func milliTime() (r []byte) {
+	return milliTimeBytes(time.Now().UnixNano() / 1e6)
+}
+
+// milliTimeBytes returns the 6 byte big endian encoding of a unix time in
+// milliseconds.
+func milliTimeBytes(m int64) []byte {
 	buf := new(bytes.Buffer)
-	t := time.Now().UnixNano()
-	m := t / 1e6
 	binary.Write(buf, binary.BigEndian, m)
 	return buf.Bytes()[2:]
 }
@@ -125,3 +129,34 @@ func sha52(data []byte) []byte {
 	h2 := sha256.Sum256(append(h1[:], data...))
 	return h2[:]
 }
+
+// computeMerkleRoot returns the Merkle root of a list of hashes as computed by
+// factomd. An odd hash at any level of the tree is paired with itself. The
+// root of an empty list is the zero hash.
+func computeMerkleRoot(hashes [][]byte) []byte {
+	if len(hashes) == 0 {
+		return make([]byte, 32)
+	}
+	level := hashes
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			h := sha256.Sum256(append(append([]byte{}, level[i]...), right...))
+			next = append(next, h[:])
+		}
+		level = next
+	}
+	return level[0]
+}
+
+// computeKeyMR returns the Key Merkle Root of a block from its header and body
+// Merkle root; sha256(sha256(header)+bodyMR)
+func computeKeyMR(header, bodyMR []byte) []byte {
+	h := sha256.Sum256(header)
+	k := sha256.Sum256(append(h[:], bodyMR...))
+	return k[:]
+}
@@ -5,6 +5,7 @@
 package factom
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -12,8 +13,14 @@ import (
 // BackupWallet returns a formatted string with the wallet seed and the secret
 // keys for all of the wallet addresses.
 func BackupWallet() (string, error) {
+	return DefaultClient.BackupWallet(context.Background())
+}
+
+// BackupWallet returns a formatted string with the wallet seed and the secret
+// keys for all of the wallet addresses.
+func (c *Client) BackupWallet(ctx context.Context) (string, error) {
 	req := NewJSON2Request("wallet-backup", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -48,8 +55,14 @@ func BackupWallet() (string, error) {
 // GenerateFactoidAddress creates a new Factoid Address and stores it in the
 // Factom Wallet.
 func GenerateFactoidAddress() (*FactoidAddress, error) {
+	return DefaultClient.GenerateFactoidAddress(context.Background())
+}
+
+// GenerateFactoidAddress creates a new Factoid Address and stores it in the
+// Factom Wallet.
+func (c *Client) GenerateFactoidAddress(ctx context.Context) (*FactoidAddress, error) {
 	req := NewJSON2Request("generate-factoid-address", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -72,8 +85,14 @@ func GenerateFactoidAddress() (*FactoidAddress, error) {
 // GenerateECAddress creates a new Entry Credit Address and stores it in the
 // Factom Wallet.
 func GenerateECAddress() (*ECAddress, error) {
+	return DefaultClient.GenerateECAddress(context.Background())
+}
+
+// GenerateECAddress creates a new Entry Credit Address and stores it in the
+// Factom Wallet.
+func (c *Client) GenerateECAddress(ctx context.Context) (*ECAddress, error) {
 	req := NewJSON2Request("generate-ec-address", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -94,8 +113,12 @@ func GenerateECAddress() (*ECAddress, error) {
 }
 
 func GenerateIdentityKey() (*IdentityKey, error) {
+	return DefaultClient.GenerateIdentityKey(context.Background())
+}
+
+func (c *Client) GenerateIdentityKey(ctx context.Context) (*IdentityKey, error) {
 	req := NewJSON2Request("generate-identity-key", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +144,15 @@ func ImportAddresses(addrs ...string) (
 	[]*FactoidAddress,
 	[]*ECAddress,
 	error) {
+	return DefaultClient.ImportAddresses(context.Background(), addrs...)
+}
+
+// ImportAddresses takes a number of Factoid and Entry Creidit secure keys and
+// stores the Facotid and Entry Credit addresses in the Factom Wallet.
+func (c *Client) ImportAddresses(ctx context.Context, addrs ...string) (
+	[]*FactoidAddress,
+	[]*ECAddress,
+	error) {
 
 	params := new(importRequest)
 	for _, addr := range addrs {
@@ -128,7 +160,7 @@ func ImportAddresses(addrs ...string) (
 		params.Addresses = append(params.Addresses, s)
 	}
 	req := NewJSON2Request("import-addresses", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -171,6 +203,16 @@ func ImportAddresses(addrs ...string) (
 // Factom Genisis block to pay participants in the initial Factom network crowd
 // funding.
 func ImportKoinify(mnemonic string) (*FactoidAddress, error) {
+	return DefaultClient.ImportKoinify(context.Background(), mnemonic)
+}
+
+// ImportKoinify creates a Factoid Address from a secret 12 word koinify
+// mnumonic.
+//
+// This functionality is used only to recover addresses that were funded by the
+// Factom Genisis block to pay participants in the initial Factom network crowd
+// funding.
+func (c *Client) ImportKoinify(ctx context.Context, mnemonic string) (*FactoidAddress, error) {
 	params := &struct {
 		Words string `json:"words"`
 	}{
@@ -178,7 +220,7 @@ func ImportKoinify(mnemonic string) (*FactoidAddress, error) {
 	}
 
 	req := NewJSON2Request("import-koinify", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -201,11 +243,17 @@ func ImportKoinify(mnemonic string) (*FactoidAddress, error) {
 // RemoveAddress removes an address from the Factom Wallet database.
 // (Be careful!)
 func RemoveAddress(address string) error {
+	return DefaultClient.RemoveAddress(context.Background(), address)
+}
+
+// RemoveAddress removes an address from the Factom Wallet database.
+// (Be careful!)
+func (c *Client) RemoveAddress(ctx context.Context, address string) error {
 	params := new(addressRequest)
 	params.Address = address
 
 	req := NewJSON2Request("remove-address", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -218,8 +266,13 @@ func RemoveAddress(address string) error {
 
 // FetchAddresses requests all of the addresses in the Factom Wallet database.
 func FetchAddresses() ([]*FactoidAddress, []*ECAddress, error) {
+	return DefaultClient.FetchAddresses(context.Background())
+}
+
+// FetchAddresses requests all of the addresses in the Factom Wallet database.
+func (c *Client) FetchAddresses(ctx context.Context) ([]*FactoidAddress, []*ECAddress, error) {
 	req := NewJSON2Request("all-addresses", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -259,6 +312,11 @@ func FetchAddresses() ([]*FactoidAddress, []*ECAddress, error) {
 
 // FetchECAddress requests an Entry Credit address from the Factom Wallet.
 func FetchECAddress(ecpub string) (*ECAddress, error) {
+	return DefaultClient.FetchECAddress(context.Background(), ecpub)
+}
+
+// FetchECAddress requests an Entry Credit address from the Factom Wallet.
+func (c *Client) FetchECAddress(ctx context.Context, ecpub string) (*ECAddress, error) {
 	if AddressStringType(ecpub) != ECPub {
 		return nil, fmt.Errorf(
 			"%s is not an Entry Credit Public Address", ecpub)
@@ -267,7 +325,7 @@ func FetchECAddress(ecpub string) (*ECAddress, error) {
 	params.Address = ecpub
 
 	req := NewJSON2Request("address", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -285,6 +343,11 @@ func FetchECAddress(ecpub string) (*ECAddress, error) {
 
 // FetchFactoidAddress requests a Factom address from the Factom Wallet.
 func FetchFactoidAddress(fctpub string) (*FactoidAddress, error) {
+	return DefaultClient.FetchFactoidAddress(context.Background(), fctpub)
+}
+
+// FetchFactoidAddress requests a Factom address from the Factom Wallet.
+func (c *Client) FetchFactoidAddress(ctx context.Context, fctpub string) (*FactoidAddress, error) {
 	if AddressStringType(fctpub) != FactoidPub {
 		return nil, fmt.Errorf("%s is not a Factoid Address", fctpub)
 	}
@@ -292,7 +355,7 @@ func FetchFactoidAddress(fctpub string) (*FactoidAddress, error) {
 	params.Address = fctpub
 
 	req := NewJSON2Request("address", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -309,6 +372,10 @@ func FetchFactoidAddress(fctpub string) (*FactoidAddress, error) {
 }
 
 func ImportIdentityKeys(pubs ...string) ([]*IdentityKey, error) {
+	return DefaultClient.ImportIdentityKeys(context.Background(), pubs...)
+}
+
+func (c *Client) ImportIdentityKeys(ctx context.Context, pubs ...string) ([]*IdentityKey, error) {
 	params := new(struct {
 		IdentityKeys []secretRequest `json:"keys"`
 	})
@@ -318,7 +385,7 @@ func ImportIdentityKeys(pubs ...string) ([]*IdentityKey, error) {
 	}
 
 	req := NewJSON2Request("import-identity-keys", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -343,13 +410,17 @@ func ImportIdentityKeys(pubs ...string) ([]*IdentityKey, error) {
 }
 
 func FetchIdentityKey(pub string) (*IdentityKey, error) {
+	return DefaultClient.FetchIdentityKey(context.Background(), pub)
+}
+
+func (c *Client) FetchIdentityKey(ctx context.Context, pub string) (*IdentityKey, error) {
 	params := new(struct {
 		Public string `json:"public"`
 	})
 	params.Public = pub
 
 	req := NewJSON2Request("identity-key", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -366,8 +437,12 @@ func FetchIdentityKey(pub string) (*IdentityKey, error) {
 }
 
 func FetchIdentityKeys() ([]*IdentityKey, error) {
+	return DefaultClient.FetchIdentityKeys(context.Background())
+}
+
+func (c *Client) FetchIdentityKeys(ctx context.Context) ([]*IdentityKey, error) {
 	req := NewJSON2Request("all-identity-keys", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -398,13 +473,17 @@ func FetchIdentityKeys() ([]*IdentityKey, error) {
 }
 
 func RemoveIdentityKey(pub string) error {
+	return DefaultClient.RemoveIdentityKey(context.Background(), pub)
+}
+
+func (c *Client) RemoveIdentityKey(ctx context.Context, pub string) error {
 	params := new(struct {
 		Public string `json:"public"`
 	})
 	params.Public = pub
 
 	req := NewJSON2Request("remove-identity-key", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -418,8 +497,14 @@ func RemoveIdentityKey(pub string) error {
 // GetWalletHeight requests the current block heights known to the Factom
 // Wallet.
 func GetWalletHeight() (uint32, error) {
+	return DefaultClient.GetWalletHeight(context.Background())
+}
+
+// GetWalletHeight requests the current block heights known to the Factom
+// Wallet.
+func (c *Client) GetWalletHeight(ctx context.Context) (uint32, error) {
 	req := NewJSON2Request("get-height", APICounter(), nil)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return 0, err
 	}
@@ -436,8 +521,12 @@ func GetWalletHeight() (uint32, error) {
 }
 
 func UnlockWallet(passphrase string, seconds int64) (int64, error) {
+	return DefaultClient.UnlockWallet(context.Background(), passphrase, seconds)
+}
+
+func (c *Client) UnlockWallet(ctx context.Context, passphrase string, seconds int64) (int64, error) {
 	req := NewJSON2Request("unlock-wallet", APICounter(), &passphraseRequest{Password: passphrase, Timeout: seconds})
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return 0, err
 	}
@@ -491,13 +580,24 @@ type composeEntryResponse struct {
 // calls needed to create new chains while keeping addresses secure in an
 // offline wallet.
 func WalletComposeChainCommitReveal(chain *Chain, ecPub string, force bool) (*JSON2Request, *JSON2Request, error) {
+	return DefaultClient.WalletComposeChainCommitReveal(context.Background(), chain, ecPub, force)
+}
+
+// WalletComposeChainCommitReveal composes commit and reveal json objects that
+// may be used to make API calls to the factomd API to create a new Factom
+// Chain.
+//
+// WalletComposeChainCommitReveal may be used by an offline wallet to create the
+// calls needed to create new chains while keeping addresses secure in an
+// offline wallet.
+func (c *Client) WalletComposeChainCommitReveal(ctx context.Context, chain *Chain, ecPub string, force bool) (*JSON2Request, *JSON2Request, error) {
 	params := new(composeChainRequest)
 	params.Chain = *chain
 	params.ECPub = ecPub
 	params.Force = force
 
 	req := NewJSON2Request("compose-chain", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -521,13 +621,24 @@ func WalletComposeChainCommitReveal(chain *Chain, ecPub string, force bool) (*JS
 // calls needed to create new entries while keeping addresses secure in an
 // offline wallet.
 func WalletComposeEntryCommitReveal(entry *Entry, ecPub string, force bool) (*JSON2Request, *JSON2Request, error) {
+	return DefaultClient.WalletComposeEntryCommitReveal(context.Background(), entry, ecPub, force)
+}
+
+// WalletComposeEntryCommitReveal composes commit and reveal json objects that
+// may be used to make API calls to the factomd API to create a new Factom
+// Entry.
+//
+// WalletComposeEntryCommitReveal may be used by an offline wallet to create the
+// calls needed to create new entries while keeping addresses secure in an
+// offline wallet.
+func (c *Client) WalletComposeEntryCommitReveal(ctx context.Context, entry *Entry, ecPub string, force bool) (*JSON2Request, *JSON2Request, error) {
 	params := new(composeEntryRequest)
 	params.Entry = *entry
 	params.ECPub = ecPub
 	params.Force = force
 
 	req := NewJSON2Request("compose-entry", APICounter(), params)
-	resp, err := walletRequest(req)
+	resp, err := c.walletRequest(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
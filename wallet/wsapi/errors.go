@@ -40,40 +40,46 @@ code				message						meaning
 
 // RPC Errors
 
+// newError returns a copy of one of the factom package JSON RPC errors so that
+// clients can match it with errors.Is.
+func newError(e *factom.JSONError, data interface{}) *factom.JSONError {
+	return factom.NewJSONError(e.Code, e.Message, data)
+}
+
 func newParseError() *factom.JSONError {
-	return factom.NewJSONError(-32700, "Parse error", nil)
+	return newError(factom.ErrParse, nil)
 }
 
 func newInvalidRequestError() *factom.JSONError {
-	return factom.NewJSONError(-32600, "Invalid Request", nil)
+	return newError(factom.ErrInvalidRequest, nil)
 }
 
 func newMethodNotFoundError() *factom.JSONError {
-	return factom.NewJSONError(-32601, "Method not found", nil)
+	return newError(factom.ErrMethodNotFound, nil)
 }
 
 func newInvalidParamsError() *factom.JSONError {
-	return factom.NewJSONError(-32602, "Invalid params", nil)
+	return newError(factom.ErrInvalidParams, nil)
 }
 
 func newInternalError() *factom.JSONError {
-	return factom.NewJSONError(-32603, "Internal error", nil)
+	return newError(factom.ErrInternal, nil)
 }
 
 func newWalletIsLockedError() *factom.JSONError {
-	return factom.NewJSONError(-32001, "Wallet is locked", nil)
+	return newError(factom.ErrWalletIsLocked, nil)
 }
 
 func newIncorrectPassphraseError() *factom.JSONError {
-	return factom.NewJSONError(-32003, "Incorrect passphrase", nil)
+	return newError(factom.ErrIncorrectPassphrase, nil)
 }
 
 // Custom Errors
 
 func newCustomInternalError(data interface{}) *factom.JSONError {
-	return factom.NewJSONError(-32603, "Internal error", data)
+	return newError(factom.ErrInternal, data)
 }
 
 func newCustomInvalidParamsError(data interface{}) *factom.JSONError {
-	return factom.NewJSONError(-32602, "Invalid params", data)
+	return newError(factom.ErrInvalidParams, data)
 }